@@ -21,4 +21,8 @@ docker run -it --rm -v ${warpgate_data}:/data ghcr.io/warp-tech/warpgate:v0.7.0
         --data-path /data \
         --http-port "8888" 
 
+# the acceptance tests use the "google" sso provider in the Sso credentials,
+# the plan fails if it is not in the config file
+sudo sed -i 's/^sso_providers: \[\]$/sso_providers:\n  - name: google\n    label: Google\n    provider:\n      type: google\n      client_id: warpgate-test\n      client_secret: warpgate-test/' ${warpgate_data}/warpgate.yaml
+
 docker-compose -f "$(pwd)"/_scripts/docker-compose.yml up -d --wait
\ No newline at end of file
@@ -0,0 +1,427 @@
+// Package exporter generates terraform configuration, including import blocks,
+// from the objects of a live warpgate server.
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	roleResourceType           = "warpgate_role"
+	userResourceType           = "warpgate_user"
+	userRolesResourceType      = "warpgate_user_roles"
+	sshTargetResourceType      = "warpgate_ssh_target"
+	httpTargetResourceType     = "warpgate_http_target"
+	postgresTargetResourceType = "warpgate_postgres_target"
+	targetResourceType         = "warpgate_target"
+	targetRolesResourceType    = "warpgate_target_roles"
+)
+
+type exporter struct {
+	client *warpgate.WarpgateClient
+	names  resourceNames
+
+	// terraform identifier of each exported role, by role id
+	roleNames map[string]string
+
+	roles     *hclwrite.File
+	users     *hclwrite.File
+	targets   *hclwrite.File
+	variables *hclwrite.File
+}
+
+// Export reads roles, users, targets and role assignments from warpgate and
+// writes them as terraform resources with import blocks into dir.
+// Secrets are not written to the files but referenced as variables.
+func Export(ctx context.Context, client *warpgate.WarpgateClient, dir string) error {
+	e := &exporter{
+		client:    client,
+		names:     resourceNames{},
+		roleNames: map[string]string{},
+		roles:     hclwrite.NewEmptyFile(),
+		users:     hclwrite.NewEmptyFile(),
+		targets:   hclwrite.NewEmptyFile(),
+		variables: hclwrite.NewEmptyFile(),
+	}
+
+	if err := e.exportRoles(ctx); err != nil {
+		return err
+	}
+
+	if err := e.exportUsers(ctx); err != nil {
+		return err
+	}
+
+	if err := e.exportTargets(ctx); err != nil {
+		return err
+	}
+
+	files := map[string]*hclwrite.File{
+		"roles.tf":     e.roles,
+		"users.tf":     e.users,
+		"targets.tf":   e.targets,
+		"variables.tf": e.variables,
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for name := range files {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return fmt.Errorf("file %s already exists, refusing to overwrite it", filepath.Join(dir, name))
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	for name, file := range files {
+		content := bytes.TrimLeft(hclwrite.Format(file.Bytes()), "\n")
+
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *exporter) exportRoles(ctx context.Context) error {
+	roles, err := e.client.Roles().List(ctx)
+
+	if err != nil {
+		return fmt.Errorf("failed to get role list (Error: %s)", err)
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	body := e.roles.Body()
+
+	for _, role := range roles {
+		name := e.names.unique(roleResourceType, role.Name)
+		e.roleNames[role.Id.String()] = name
+
+		resource := appendResource(body, roleResourceType, name)
+		resource.SetAttributeValue("name", cty.StringVal(role.Name))
+
+		appendImport(body, roleResourceType, name, role.Id.String())
+	}
+
+	return nil
+}
+
+func (e *exporter) exportUsers(ctx context.Context) error {
+	users, err := e.client.Users().List(ctx)
+
+	if err != nil {
+		return fmt.Errorf("failed to get user list (Error: %s)", err)
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	body := e.users.Body()
+
+	for _, user := range users {
+		name := e.names.unique(userResourceType, user.Username)
+
+		credentials := []hclwrite.Tokens{}
+
+		for _, credential := range user.Credentials {
+			tokens, err := e.userCredentialTokens(name, credential)
+
+			if err != nil {
+				return fmt.Errorf("failed to export credentials of user '%s' (Error: %s)", user.Username, err)
+			}
+
+			credentials = append(credentials, tokens)
+		}
+
+		resource := appendResource(body, userResourceType, name)
+		resource.SetAttributeValue("username", cty.StringVal(user.Username))
+		resource.SetAttributeRaw("credentials", multilineTupleTokens(credentials))
+
+		appendImport(body, userResourceType, name, user.Id.String())
+
+		roles, err := e.client.Users().Roles(ctx, user.Id)
+
+		if err != nil {
+			return fmt.Errorf("failed to read roles of user '%s' (Error: %s)", user.Username, err)
+		}
+
+		if len(roles) == 0 {
+			continue
+		}
+
+		assignment := appendResource(body, userRolesResourceType, name)
+		assignment.SetAttributeTraversal("id", traversal(userResourceType, name, "id"))
+		assignment.SetAttributeRaw("role_ids", e.roleIdsTokens(roles))
+
+		appendImport(body, userRolesResourceType, name, user.Id.String())
+	}
+
+	return nil
+}
+
+func (e *exporter) userCredentialTokens(userName string, credential warpgate.UserAuthCredential) (hclwrite.Tokens, error) {
+	kind, err := credential.Discriminator()
+
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := []hclwrite.ObjectAttrTokens{objectAttr("kind", stringTokens(kind))}
+
+	switch kind {
+	case string(warpgate.Password):
+		variable := e.names.unique("variable", fmt.Sprintf("user_%s_password_hash", userName))
+		appendVariable(e.variables.Body(), variable, "string", fmt.Sprintf("Password hash of the warpgate user %s", userName))
+		attrs = append(attrs, objectAttr("hash", referenceTokens("var", variable)))
+
+	case string(warpgate.Totp):
+		variable := e.names.unique("variable", fmt.Sprintf("user_%s_totp_key", userName))
+		appendVariable(e.variables.Body(), variable, "list(number)", fmt.Sprintf("Totp key of the warpgate user %s", userName))
+		attrs = append(attrs, objectAttr("totp_key", referenceTokens("var", variable)))
+
+	case string(warpgate.PublicKey):
+		auth, err := credential.AsUserAuthCredentialUserPublicKeyCredential()
+
+		if err != nil {
+			return nil, err
+		}
+
+		attrs = append(attrs, objectAttr("public_key", stringTokens(auth.Key)))
+
+	case string(warpgate.Sso):
+		auth, err := credential.AsUserAuthCredentialUserSsoCredential()
+
+		if err != nil {
+			return nil, err
+		}
+
+		attrs = append(attrs, objectAttr("email", stringTokens(auth.Email)))
+
+		if auth.Provider != nil {
+			attrs = append(attrs, objectAttr("provider", stringTokens(*auth.Provider)))
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown credential kind '%s'", kind)
+	}
+
+	return hclwrite.TokensForObject(attrs), nil
+}
+
+func (e *exporter) exportTargets(ctx context.Context) error {
+	targets, err := e.client.Targets().List(ctx, "")
+
+	if err != nil {
+		return fmt.Errorf("failed to get target list (Error: %s)", err)
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+
+	body := e.targets.Body()
+
+	for _, target := range targets {
+		kind, err := target.Options.Discriminator()
+
+		if err != nil {
+			return fmt.Errorf("failed to read the kind of target '%s' (Error: %s)", target.Name, err)
+		}
+
+		var resourceType string
+		var options hclwrite.Tokens
+		// the kind specific resources have their options in "options",
+		// warpgate_target in the attribute of the kind
+		optionsAttribute := "options"
+
+		switch kind {
+		case warpgate.TargetKindSsh:
+			resourceType = sshTargetResourceType
+			options, err = e.sshOptionsTokens(target)
+		case warpgate.TargetKindHttp:
+			resourceType = httpTargetResourceType
+			options, err = e.httpOptionsTokens(target)
+		case warpgate.TargetKindMySql:
+			resourceType = targetResourceType
+			optionsAttribute = "mysql"
+			options, err = e.mysqlOptionsTokens(target)
+		case warpgate.TargetKindPostgres:
+			resourceType = postgresTargetResourceType
+			options, err = e.postgresOptionsTokens(target)
+		default:
+			body.AppendNewline()
+			body.AppendUnstructuredTokens(commentTokens(
+				fmt.Sprintf("Target '%s' (id: %s) of kind %s is not supported by the provider.", target.Name, target.Id, kind),
+			))
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to export target '%s' (Error: %s)", target.Name, err)
+		}
+
+		name := e.names.unique(resourceType, target.Name)
+
+		resource := appendResource(body, resourceType, name)
+		resource.SetAttributeValue("name", cty.StringVal(target.Name))
+		resource.SetAttributeRaw(optionsAttribute, options)
+
+		appendImport(body, resourceType, name, target.Id.String())
+
+		roles, err := e.client.Targets().Roles(ctx, target.Id)
+
+		if err != nil {
+			return fmt.Errorf("failed to read roles of target '%s' (Error: %s)", target.Name, err)
+		}
+
+		if len(roles) == 0 {
+			continue
+		}
+
+		assignmentName := e.names.unique(targetRolesResourceType, name)
+
+		assignment := appendResource(body, targetRolesResourceType, assignmentName)
+		assignment.SetAttributeTraversal("id", traversal(resourceType, name, "id"))
+		assignment.SetAttributeRaw("role_ids", e.roleIdsTokens(roles))
+
+		appendImport(body, targetRolesResourceType, assignmentName, target.Id.String())
+	}
+
+	return nil
+}
+
+func (e *exporter) sshOptionsTokens(target warpgate.Target) (hclwrite.Tokens, error) {
+	options, err := target.Options.AsTargetOptionsTargetSSHOptions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	authKind, err := options.Auth.Discriminator()
+
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := []hclwrite.ObjectAttrTokens{
+		objectAttr("host", stringTokens(options.Host)),
+		objectAttr("port", hclwrite.TokensForValue(cty.NumberIntVal(int64(options.Port)))),
+		objectAttr("username", stringTokens(options.Username)),
+		objectAttr("auth_kind", stringTokens(authKind)),
+	}
+
+	if authKind == string(warpgate.Password) {
+		variable := e.names.unique("variable", fmt.Sprintf("ssh_target_%s_password", target.Name))
+		appendVariable(e.variables.Body(), variable, "string", fmt.Sprintf("Password of the warpgate ssh target %s", target.Name))
+		attrs = append(attrs, objectAttr("password", referenceTokens("var", variable)))
+	}
+
+	return hclwrite.TokensForObject(attrs), nil
+}
+
+func (e *exporter) httpOptionsTokens(target warpgate.Target) (hclwrite.Tokens, error) {
+	options, err := target.Options.AsTargetOptionsTargetHTTPOptions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := []hclwrite.ObjectAttrTokens{
+		objectAttr("url", stringTokens(options.Url)),
+	}
+
+	if options.ExternalHost != nil {
+		attrs = append(attrs, objectAttr("external_host", stringTokens(*options.ExternalHost)))
+	}
+
+	if options.Headers != nil && len(*options.Headers) > 0 {
+		headers := map[string]cty.Value{}
+		for k, v := range *options.Headers {
+			headers[k] = cty.StringVal(v)
+		}
+		attrs = append(attrs, objectAttr("headers", hclwrite.TokensForValue(cty.MapVal(headers))))
+	}
+
+	attrs = append(attrs, objectAttr("tls", hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{
+		objectAttr("mode", stringTokens(string(options.Tls.Mode))),
+		objectAttr("verify", hclwrite.TokensForValue(cty.BoolVal(options.Tls.Verify))),
+	})))
+
+	return hclwrite.TokensForObject(attrs), nil
+}
+
+func (e *exporter) mysqlOptionsTokens(target warpgate.Target) (hclwrite.Tokens, error) {
+	options, err := target.Options.AsTargetOptionsTargetMySqlOptions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return e.databaseOptionsTokens(target, "mysql", options.Host, options.Port, options.Username, options.Password, options.Tls), nil
+}
+
+func (e *exporter) postgresOptionsTokens(target warpgate.Target) (hclwrite.Tokens, error) {
+	options, err := target.Options.AsTargetOptionsTargetPostgresOptions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return e.databaseOptionsTokens(target, "postgres", options.Host, options.Port, options.Username, options.Password, options.Tls), nil
+}
+
+// databaseOptionsTokens writes the options shared by the mysql and postgres
+// targets, the password is a variable.
+func (e *exporter) databaseOptionsTokens(target warpgate.Target, protocol string, host string, port uint16, username string, password *string, tls warpgate.Tls) hclwrite.Tokens {
+	attrs := []hclwrite.ObjectAttrTokens{
+		objectAttr("host", stringTokens(host)),
+		objectAttr("port", hclwrite.TokensForValue(cty.NumberIntVal(int64(port)))),
+		objectAttr("username", stringTokens(username)),
+	}
+
+	if password != nil {
+		variable := e.names.unique("variable", fmt.Sprintf("%s_target_%s_password", protocol, target.Name))
+		appendVariable(e.variables.Body(), variable, "string", fmt.Sprintf("Password of the warpgate %s target %s", protocol, target.Name))
+		attrs = append(attrs, objectAttr("password", referenceTokens("var", variable)))
+	}
+
+	attrs = append(attrs, objectAttr("tls", hclwrite.TokensForObject([]hclwrite.ObjectAttrTokens{
+		objectAttr("mode", stringTokens(string(tls.Mode))),
+		objectAttr("verify", hclwrite.TokensForValue(cty.BoolVal(tls.Verify))),
+	})))
+
+	return hclwrite.TokensForObject(attrs)
+}
+
+// roleIdsTokens references the exported roles instead of their raw uuids.
+func (e *exporter) roleIdsTokens(roles []warpgate.Role) hclwrite.Tokens {
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	elems := []hclwrite.Tokens{}
+
+	for _, role := range roles {
+		elems = append(elems, e.roleIdTokens(role.Id))
+	}
+
+	return hclwrite.TokensForTuple(elems)
+}
+
+func (e *exporter) roleIdTokens(id uuid.UUID) hclwrite.Tokens {
+	if name, ok := e.roleNames[id.String()]; ok {
+		return referenceTokens(roleResourceType, name, "id")
+	}
+
+	return stringTokens(id.String())
+}
@@ -0,0 +1,152 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"terraform-provider-warpgate/warpgate"
+	"testing"
+)
+
+const (
+	testRoleOpsId = "11111111-1111-1111-1111-111111111111"
+	testRoleDevId = "22222222-2222-2222-2222-222222222222"
+)
+
+func testExportServer(t *testing.T) *warpgate.WarpgateClient {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch path := strings.TrimPrefix(r.URL.Path, warpgate.WARPGATE_ENDPOINT_ADMIN_API); {
+		case r.URL.Path == warpgate.WARPGATE_ENDPOINT_LOGIN:
+			w.WriteHeader(201)
+		case path == "/roles":
+			fmt.Fprintf(w, `[{"id":"%s","name":"ops"},{"id":"%s","name":"1 dev"}]`, testRoleOpsId, testRoleDevId)
+		case strings.HasSuffix(path, "/roles"):
+			fmt.Fprintf(w, `[{"id":"%s","name":"ops"}]`, testRoleOpsId)
+		case path == "/users":
+			fmt.Fprint(w, `[{"id":"33333333-3333-3333-3333-333333333333","username":"alice","roles":["ops"],"credentials":[`+
+				`{"kind":"Password","hash":"$argon2id$secret"},`+
+				`{"kind":"PublicKey","key":"ssh-ed25519 AAAA"},`+
+				`{"kind":"Sso","email":"alice@example.com","provider":"google"}]}]`)
+		case path == "/targets":
+			fmt.Fprint(w, `[`+
+				`{"id":"44444444-4444-4444-4444-444444444444","name":"prod-db","allow_roles":["ops"],"options":{"kind":"Ssh","host":"10.0.0.1","port":22,"username":"root","auth":{"kind":"Password","password":"hunter2"}}},`+
+				`{"id":"66666666-6666-6666-6666-666666666666","name":"orders","allow_roles":[],"options":{"kind":"MySql","host":"10.0.0.2","port":3306,"username":"app","password":"hunter3","tls":{"mode":"Preferred","verify":true}}},`+
+				`{"id":"77777777-7777-7777-7777-777777777777","name":"billing","allow_roles":[],"options":{"kind":"Postgres","host":"10.0.0.3","port":5432,"username":"app","password":"hunter4","tls":{"mode":"Required","verify":true}}},`+
+				`{"id":"55555555-5555-5555-5555-555555555555","name":"warpgate","allow_roles":[],"options":{"kind":"WebAdmin"}}]`)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	serverUrl, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverUrl.Port())
+
+	client := warpgate.NewWarpgateClient(serverUrl.Hostname(), port, true, warpgate.RequestLimits{})
+
+	if err := client.Login("admin", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestExport(t *testing.T) {
+	client := testExportServer(t)
+	dir := t.TempDir()
+
+	if err := Export(context.Background(), client, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	expectations := map[string][]string{
+		"roles.tf": {
+			`resource "warpgate_role" "ops" {`,
+			`resource "warpgate_role" "_1_dev" {`,
+			`to = warpgate_role.ops`,
+			`id = "` + testRoleOpsId + `"`,
+		},
+		"users.tf": {
+			`resource "warpgate_user" "alice" {`,
+			`hash = var.user_alice_password_hash`,
+			`public_key = "ssh-ed25519 AAAA"`,
+			`role_ids = [warpgate_role.ops.id]`,
+			`to = warpgate_user_roles.alice`,
+		},
+		"targets.tf": {
+			`resource "warpgate_ssh_target" "prod_db" {`,
+			`password  = var.ssh_target_prod_db_password`,
+			`id       = warpgate_ssh_target.prod_db.id`,
+			`resource "warpgate_target" "orders" {`,
+			`mysql = {`,
+			`password = var.mysql_target_orders_password`,
+			`resource "warpgate_postgres_target" "billing" {`,
+			`password = var.postgres_target_billing_password`,
+			`# Target 'warpgate'`,
+		},
+		"variables.tf": {
+			`variable "user_alice_password_hash" {`,
+			`variable "ssh_target_prod_db_password" {`,
+			`variable "mysql_target_orders_password" {`,
+			`variable "postgres_target_billing_password" {`,
+		},
+	}
+
+	for file, expected := range expectations {
+		content := read(file)
+
+		for _, e := range expected {
+			if !strings.Contains(content, e) {
+				t.Errorf("%s: expected to contain %q, got:\n%s", file, e, content)
+			}
+		}
+
+		for _, secret := range []string{"hunter2", "hunter3", "hunter4", "$argon2id$secret"} {
+			if strings.Contains(content, secret) {
+				t.Errorf("%s: secret %q leaked into the exported files", file, secret)
+			}
+		}
+	}
+
+	if err := Export(context.Background(), client, dir); err == nil {
+		t.Error("expected the export to refuse to overwrite existing files")
+	}
+}
+
+func TestResourceNamesUnique(t *testing.T) {
+	names := resourceNames{}
+
+	cases := []struct {
+		resourceType string
+		name         string
+		expected     string
+	}{
+		{"warpgate_role", "Ops Team", "ops_team"},
+		{"warpgate_role", "ops-team", "ops_team_2"},
+		{"warpgate_user", "ops-team", "ops_team"},
+		{"warpgate_role", "1st", "_1st"},
+		{"warpgate_role", "!!!", "_"},
+	}
+
+	for _, c := range cases {
+		if got := names.unique(c.resourceType, c.name); got != c.expected {
+			t.Errorf("unique(%q, %q) = %q, expected %q", c.resourceType, c.name, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package exporter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var invalidIdentifierChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// resourceNames hands out unique terraform identifiers per resource type.
+type resourceNames map[string]map[string]bool
+
+func (n resourceNames) unique(resourceType string, name string) string {
+	identifier := invalidIdentifierChars.ReplaceAllString(strings.ToLower(name), "_")
+	identifier = strings.Trim(identifier, "_")
+
+	if identifier == "" || (identifier[0] >= '0' && identifier[0] <= '9') {
+		identifier = "_" + identifier
+	}
+
+	if n[resourceType] == nil {
+		n[resourceType] = map[string]bool{}
+	}
+
+	candidate := identifier
+	for i := 2; n[resourceType][candidate]; i++ {
+		candidate = fmt.Sprintf("%s_%d", identifier, i)
+	}
+	n[resourceType][candidate] = true
+
+	return candidate
+}
+
+func traversal(root string, attrs ...string) hcl.Traversal {
+	result := hcl.Traversal{hcl.TraverseRoot{Name: root}}
+	for _, a := range attrs {
+		result = append(result, hcl.TraverseAttr{Name: a})
+	}
+	return result
+}
+
+func stringTokens(value string) hclwrite.Tokens {
+	return hclwrite.TokensForValue(cty.StringVal(value))
+}
+
+func referenceTokens(root string, attrs ...string) hclwrite.Tokens {
+	return hclwrite.TokensForTraversal(traversal(root, attrs...))
+}
+
+func objectAttr(name string, value hclwrite.Tokens) hclwrite.ObjectAttrTokens {
+	return hclwrite.ObjectAttrTokens{
+		Name:  hclwrite.TokensForIdentifier(name),
+		Value: value,
+	}
+}
+
+// multilineTupleTokens writes each element of the tuple on its own line,
+// which reads better than hclwrite.TokensForTuple for lists of objects.
+func multilineTupleTokens(elems []hclwrite.Tokens) hclwrite.Tokens {
+	if len(elems) == 0 {
+		return hclwrite.TokensForTuple(elems)
+	}
+
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")},
+		{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+	}
+
+	for _, elem := range elems {
+		tokens = append(tokens, elem...)
+		tokens = append(tokens,
+			&hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")},
+			&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+		)
+	}
+
+	return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+}
+
+func commentTokens(comment string) hclwrite.Tokens {
+	return hclwrite.Tokens{{
+		Type:  hclsyntax.TokenComment,
+		Bytes: []byte("# " + comment + "\n"),
+	}}
+}
+
+func appendResource(body *hclwrite.Body, resourceType string, name string) *hclwrite.Body {
+	body.AppendNewline()
+	return body.AppendNewBlock("resource", []string{resourceType, name}).Body()
+}
+
+func appendImport(body *hclwrite.Body, resourceType string, name string, id string) {
+	body.AppendNewline()
+	block := body.AppendNewBlock("import", nil).Body()
+	block.SetAttributeTraversal("to", traversal(resourceType, name))
+	block.SetAttributeValue("id", cty.StringVal(id))
+}
+
+func appendVariable(body *hclwrite.Body, name string, variableType string, description string) {
+	body.AppendNewline()
+	block := body.AppendNewBlock("variable", []string{name}).Body()
+	block.SetAttributeRaw("type", hclwrite.TokensForIdentifier(variableType))
+	block.SetAttributeValue("description", cty.StringVal(description))
+	block.SetAttributeValue("sensitive", cty.True)
+}
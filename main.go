@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"log"
+	"terraform-provider-warpgate/exporter"
 	"terraform-provider-warpgate/provider"
 
 	// "terraform-provider-warpgate/provider"
@@ -25,10 +26,19 @@ var (
 
 func main() {
 	var debug bool
+	var export bool
+	var exportDir string
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
+	flag.BoolVar(&export, "export", false, "set to true to export the configuration of a live warpgate server as terraform files (uses the WARPGATE_* environment variables to login)")
+	flag.StringVar(&exportDir, "export-dir", ".", "the directory where the exported terraform files are written")
 	flag.Parse()
 
+	if export {
+		runExport(exportDir)
+		return
+	}
+
 	opts := providerserver.ServeOpts{
 		// TODO: Update this string with the published name of your provider.
 		Address: "registry.terraform.io/andreee94/warpgate",
@@ -42,3 +52,19 @@ func main() {
 		log.Fatal(err.Error())
 	}
 }
+
+func runExport(dir string) {
+	client, err := provider.NewClientFromEnv()
+
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	err = exporter.Export(context.Background(), client, dir)
+
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	log.Printf("Exported warpgate configuration to %s", dir)
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ action.Action = &closeAllSessionsAction{}
+var _ action.ActionWithConfigure = &closeAllSessionsAction{}
+
+func (a closeAllSessionsAction) Schema(ctx context.Context, req action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Closes the sessions of all the users on all the targets.",
+		Attributes:          map[string]schema.Attribute{},
+	}
+}
+
+func NewCloseAllSessionsAction() action.Action {
+	return &closeAllSessionsAction{}
+}
+
+type closeAllSessionsAction struct {
+	provider *warpgateProvider
+}
+
+func (a *closeAllSessionsAction) Metadata(ctx context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_close_all_sessions"
+}
+
+func (a *closeAllSessionsAction) Configure(ctx context.Context, req action.ConfigureRequest, resp *action.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*warpgateProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Action Configure Type",
+			fmt.Sprintf("Expected *warpgateProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !provider.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"Expected a configured provider but it wasn't. Please report this issue to the provider developers.",
+		)
+
+		return
+	}
+
+	a.provider = provider
+}
+
+func (a *closeAllSessionsAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	err := a.provider.client.Sessions().CloseAll(ctx)
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to close sessions",
+			fmt.Sprintf("Failed to close all the sessions. (Error: %s)", err),
+		)
+		return
+	}
+
+	resp.SendProgress(action.InvokeProgressEvent{Message: "Closed all the sessions."})
+}
@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"terraform-provider-warpgate/warpgate/warpgatetest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestCloseAllSessionsAction(t *testing.T) {
+	server, p := testActionServer(t)
+
+	first := server.AddSession("alice", warpgatetest.AdminTargetName)
+	second := server.AddSession("bob", warpgatetest.AdminTargetName)
+
+	resp, _ := testInvokeAction(t, p, NewCloseAllSessionsAction(), map[string]tftypes.Value{})
+
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+
+	if server.SessionActive(first) || server.SessionActive(second) {
+		t.Errorf("expected all the sessions to be closed")
+	}
+}
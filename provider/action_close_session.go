@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/provider/validators"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ action.Action = &closeSessionAction{}
+var _ action.ActionWithConfigure = &closeSessionAction{}
+
+func (a closeSessionAction) Schema(ctx context.Context, req action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Closes a session of a user on a target.",
+		Attributes: map[string]schema.Attribute{
+			"session_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Id of the session in warpgate.",
+				Validators:          []validator.String{validators.IsUUID()},
+			},
+		},
+	}
+}
+
+func NewCloseSessionAction() action.Action {
+	return &closeSessionAction{}
+}
+
+type closeSessionAction struct {
+	provider *warpgateProvider
+}
+
+func (a *closeSessionAction) Metadata(ctx context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_close_session"
+}
+
+func (a *closeSessionAction) Configure(ctx context.Context, req action.ConfigureRequest, resp *action.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*warpgateProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Action Configure Type",
+			fmt.Sprintf("Expected *warpgateProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !provider.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"Expected a configured provider but it wasn't. Please report this issue to the provider developers.",
+		)
+
+		return
+	}
+
+	a.provider = provider
+}
+
+func (a *closeSessionAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var config provider_models.CloseSession
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id_as_uuid, err := uuid.Parse(config.SessionId.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse the id as uuid",
+			fmt.Sprintf("Failed to parse the id '%s' as uuid", config.SessionId),
+		)
+		return
+	}
+
+	err = a.provider.client.Sessions().Close(ctx, id_as_uuid)
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to close session",
+			fmt.Sprintf("Failed to close session with id '%s'. (Error: %s)", config.SessionId, err),
+		)
+		return
+	}
+
+	resp.SendProgress(action.InvokeProgressEvent{
+		Message: fmt.Sprintf("Closed session %s.", config.SessionId.ValueString()),
+	})
+}
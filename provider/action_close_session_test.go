@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"terraform-provider-warpgate/warpgate"
+	"terraform-provider-warpgate/warpgate/warpgatetest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// testActionServer returns an in-memory warpgate server and a provider
+// logged in to it.
+func testActionServer(t *testing.T) (*warpgatetest.Server, *warpgateProvider) {
+	t.Helper()
+
+	server := warpgatetest.NewServer()
+	t.Cleanup(server.Close)
+
+	client := warpgate.NewWarpgateClient(server.Host(), server.Port(), true, warpgate.RequestLimits{})
+
+	if err := client.Login(warpgatetest.AdminUsername, warpgatetest.AdminPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	return server, &warpgateProvider{configured: true, client: client}
+}
+
+// testInvokeAction invokes the action with the configuration values, returning
+// the response and the progress messages.
+func testInvokeAction(t *testing.T, p *warpgateProvider, a action.Action, values map[string]tftypes.Value) (action.InvokeResponse, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	configureResp := action.ConfigureResponse{}
+	a.(action.ActionWithConfigure).Configure(ctx, action.ConfigureRequest{ProviderData: p}, &configureResp)
+
+	if configureResp.Diagnostics.HasError() {
+		t.Fatal(configureResp.Diagnostics)
+	}
+
+	schemaResp := action.SchemaResponse{}
+	a.Schema(ctx, action.SchemaRequest{}, &schemaResp)
+
+	messages := []string{}
+	resp := action.InvokeResponse{
+		SendProgress: func(event action.InvokeProgressEvent) {
+			messages = append(messages, event.Message)
+		},
+	}
+
+	a.Invoke(ctx, action.InvokeRequest{
+		Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), values),
+		},
+	}, &resp)
+
+	return resp, messages
+}
+
+func TestCloseSessionAction(t *testing.T) {
+	server, p := testActionServer(t)
+
+	closed := server.AddSession("alice", warpgatetest.AdminTargetName)
+	other := server.AddSession("alice", warpgatetest.AdminTargetName)
+
+	resp, messages := testInvokeAction(t, p, NewCloseSessionAction(), map[string]tftypes.Value{
+		"session_id": tftypes.NewValue(tftypes.String, closed.String()),
+	})
+
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+
+	if server.SessionActive(closed) || !server.SessionActive(other) {
+		t.Errorf("expected only the session %s to be closed", closed)
+	}
+
+	if len(messages) != 1 {
+		t.Errorf("expected a progress message, got %v", messages)
+	}
+
+	resp, _ = testInvokeAction(t, p, NewCloseSessionAction(), map[string]tftypes.Value{
+		"session_id": tftypes.NewValue(tftypes.String, "7cd9e8a3-ed2f-4cd3-9b46-3e2f4b4bb9e6"),
+	})
+
+	if !resp.Diagnostics.HasError() {
+		t.Errorf("expected an error for a missing session")
+	}
+}
@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	provider_models "terraform-provider-warpgate/provider/models"
+
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ action.Action = &closeUserSessionsAction{}
+var _ action.ActionWithConfigure = &closeUserSessionsAction{}
+
+func (a closeUserSessionsAction) Schema(ctx context.Context, req action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Closes all the active sessions of a user, e.g. before removing a credential.",
+		Attributes: map[string]schema.Attribute{
+			"username": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The user whose sessions are closed.",
+			},
+		},
+	}
+}
+
+func NewCloseUserSessionsAction() action.Action {
+	return &closeUserSessionsAction{}
+}
+
+type closeUserSessionsAction struct {
+	provider *warpgateProvider
+}
+
+func (a *closeUserSessionsAction) Metadata(ctx context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_close_user_sessions"
+}
+
+func (a *closeUserSessionsAction) Configure(ctx context.Context, req action.ConfigureRequest, resp *action.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*warpgateProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Action Configure Type",
+			fmt.Sprintf("Expected *warpgateProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !provider.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"Expected a configured provider but it wasn't. Please report this issue to the provider developers.",
+		)
+
+		return
+	}
+
+	a.provider = provider
+}
+
+func (a *closeUserSessionsAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var config provider_models.CloseUserSessions
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	closed, err := closeSessions(ctx, a.provider.client, userSessions(config.Username.ValueString()))
+
+	resp.SendProgress(action.InvokeProgressEvent{
+		Message: fmt.Sprintf("Closed %d active sessions of user '%s'.", closed, config.Username.ValueString()),
+	})
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to close sessions",
+			fmt.Sprintf("Failed to close the sessions of user '%s'. (Error: %s)", config.Username.ValueString(), err),
+		)
+	}
+}
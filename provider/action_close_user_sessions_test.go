@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"reflect"
+	"terraform-provider-warpgate/warpgate/warpgatetest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestCloseUserSessionsAction(t *testing.T) {
+	server, p := testActionServer(t)
+
+	first := server.AddSession("alice", warpgatetest.AdminTargetName)
+	second := server.AddSession("alice", warpgatetest.AdminTargetName)
+	other := server.AddSession("bob", warpgatetest.AdminTargetName)
+
+	resp, messages := testInvokeAction(t, p, NewCloseUserSessionsAction(), map[string]tftypes.Value{
+		"username": tftypes.NewValue(tftypes.String, "alice"),
+	})
+
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+
+	if server.SessionActive(first) || server.SessionActive(second) || !server.SessionActive(other) {
+		t.Errorf("expected only the sessions of alice to be closed")
+	}
+
+	if !reflect.DeepEqual(messages, []string{"Closed 2 active sessions of user 'alice'."}) {
+		t.Errorf("unexpected progress messages %v", messages)
+	}
+}
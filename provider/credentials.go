@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"terraform-provider-warpgate/warpgate"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Same parameters used by warpgate (the defaults of the argon2 crate).
+const (
+	passwordHashMemory  = 4096
+	passwordHashTime    = 3
+	passwordHashThreads = 1
+	passwordHashLength  = 32
+	passwordSaltMinLen  = 8
+)
+
+// HashPassword returns the argon2id hash of the password in the PHC string
+// format stored by warpgate in the `hash` of the Password credentials.
+// The salt is explicit so that the same inputs always give the same hash.
+func HashPassword(password string, salt string) (string, error) {
+	if len(salt) < passwordSaltMinLen {
+		return "", fmt.Errorf("the salt must be at least %d bytes long", passwordSaltMinLen)
+	}
+
+	hash := argon2.IDKey([]byte(password), []byte(salt), passwordHashTime, passwordHashMemory, passwordHashThreads, passwordHashLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		passwordHashMemory,
+		passwordHashTime,
+		passwordHashThreads,
+		base64.RawStdEncoding.EncodeToString([]byte(salt)),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
+}
+
+// TotpKeyToSecret returns the base32 secret of a totp key, as the key is
+// stored in the `totp_key` of the Totp credentials (a list of bytes).
+func TotpKeyToSecret(key []uint16) (string, error) {
+	if len(key) == 0 {
+		return "", fmt.Errorf("the totp key is empty")
+	}
+
+	data := make([]byte, len(key))
+
+	for i, value := range key {
+		if value > 255 {
+			return "", fmt.Errorf("the totp key must be a list of bytes, found %d at index %d", value, i)
+		}
+
+		data[i] = byte(value)
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(data), nil
+}
+
+// TotpUri returns the otpauth:// uri of a totp key, the one encoded in the QR
+// code shown by authenticator apps. Warpgate uses SHA1, 6 digits and 30s.
+func TotpUri(key []uint16, username string, issuer string) (string, error) {
+	secret, err := TotpKeyToSecret(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	label := url.PathEscape(username)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", "6")
+	query.Set("period", "30")
+
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode()), nil
+}
+
+// TicketConnectionString returns how a ticket is used to connect to a target
+// of the given kind through warpgate, as shown by the warpgate admin UI.
+func TicketConnectionString(kind string, host string, port int64, secret string) (string, error) {
+	if secret == "" {
+		return "", fmt.Errorf("the ticket secret is empty")
+	}
+
+	if port < 1 || port > 65535 {
+		return "", fmt.Errorf("the port %d is not a number between 1 and 65535", port)
+	}
+
+	address := host
+	if strings.Contains(host, ":") {
+		address = "[" + host + "]"
+	}
+
+	username := "ticket-" + secret
+
+	switch kind {
+	case warpgate.TargetKindSsh:
+		return fmt.Sprintf("ssh %s@%s -p %d", username, host, port), nil
+	case warpgate.TargetKindHttp:
+		return fmt.Sprintf("https://%s:%d/?warpgate-ticket=%s", address, port, url.QueryEscape(secret)), nil
+	case warpgate.TargetKindMySql:
+		return fmt.Sprintf("mysql -u %s --host %s --port %d --ssl", username, host, port), nil
+	case warpgate.TargetKindPostgres:
+		return fmt.Sprintf("psql \"host=%s port=%d user=%s sslmode=require\"", host, port, username), nil
+	default:
+		return "", fmt.Errorf("tickets are not supported for targets of kind %s", strconv.Quote(kind))
+	}
+}
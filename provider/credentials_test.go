@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("password", "saltsalt")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hash != "$argon2id$v=19$m=4096,t=3,p=1$c2FsdHNhbHQ$kJvQUZvNZLLzIw4lXL0nVb2HtJqyPrFaO3b7fbBoYo4" {
+		t.Errorf("unexpected hash %s", hash)
+	}
+
+	// the hash must verify as any argon2id PHC string, with the parameters it contains
+	parts := strings.Split(hash, "$")
+	salt, _ := base64.RawStdEncoding.DecodeString(parts[4])
+	expected := base64.RawStdEncoding.EncodeToString(argon2.IDKey([]byte("password"), salt, 3, 4096, 1, 32))
+
+	if parts[5] != expected {
+		t.Errorf("the hash %s does not verify", hash)
+	}
+
+	if _, err := HashPassword("password", "short"); err == nil {
+		t.Error("expected an error for a short salt")
+	}
+}
+
+func TestTotpUri(t *testing.T) {
+	uri, err := TotpUri([]uint16{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, "alice@example.com", "Warpgate")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri != "otpauth://totp/Warpgate:alice@example.com?algorithm=SHA1&digits=6&issuer=Warpgate&period=30&secret=AEBAGBAFAYDQQCIK" {
+		t.Errorf("unexpected uri %s", uri)
+	}
+
+	uri, err = TotpUri([]uint16{1, 2, 3, 4, 5}, "alice smith", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri != "otpauth://totp/alice%20smith?algorithm=SHA1&digits=6&period=30&secret=AEBAGBAF" {
+		t.Errorf("unexpected uri without issuer %s", uri)
+	}
+
+	if _, err := TotpUri([]uint16{256}, "alice", ""); err == nil {
+		t.Error("expected an error for a key that is not a list of bytes")
+	}
+
+	if _, err := TotpUri(nil, "alice", ""); err == nil {
+		t.Error("expected an error for an empty key")
+	}
+}
+
+func TestTicketConnectionString(t *testing.T) {
+	cases := []struct {
+		kind     string
+		host     string
+		port     int64
+		expected string
+	}{
+		{"Ssh", "warpgate.example.com", 2222, "ssh ticket-secret@warpgate.example.com -p 2222"},
+		{"Http", "warpgate.example.com", 8888, "https://warpgate.example.com:8888/?warpgate-ticket=secret"},
+		{"Http", "2001:db8::1", 443, "https://[2001:db8::1]:443/?warpgate-ticket=secret"},
+		{"MySql", "warpgate.example.com", 33306, "mysql -u ticket-secret --host warpgate.example.com --port 33306 --ssl"},
+		{"Postgres", "warpgate.example.com", 55432, `psql "host=warpgate.example.com port=55432 user=ticket-secret sslmode=require"`},
+	}
+
+	for _, c := range cases {
+		connectionString, err := TicketConnectionString(c.kind, c.host, c.port, "secret")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if connectionString != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.kind, c.expected, connectionString)
+		}
+	}
+
+	if _, err := TicketConnectionString("WebAdmin", "warpgate.example.com", 8888, "secret"); err == nil {
+		t.Error("expected an error for an unsupported kind")
+	}
+
+	if _, err := TicketConnectionString("Ssh", "warpgate.example.com", 0, "secret"); err == nil {
+		t.Error("expected an error for an invalid port")
+	}
+}
@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"terraform-provider-warpgate/warpgate"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -36,7 +37,9 @@ func (d httpTargetListDataSource) Schema(ctx context.Context, req datasource.Sch
 							Attributes: map[string]schema.Attribute{
 								"external_host": schema.StringAttribute{Computed: true},
 								"url":           schema.StringAttribute{Computed: true},
-								"headers":       schema.MapAttribute{Computed: true, ElementType: types.StringType},
+								// warpgate does not know which headers are sensitive, so all of them are
+								// returned in headers, which is sensitive too.
+								"headers": schema.MapAttribute{Computed: true, Sensitive: true, ElementType: types.StringType},
 								"tls": schema.SingleNestedAttribute{
 									Computed: true,
 									Attributes: map[string]schema.Attribute{
@@ -106,31 +109,28 @@ func (d *httpTargetListDataSource) Read(ctx context.Context, req datasource.Read
 		return
 	}
 
-	response, err := d.provider.client.GetTargetsWithResponse(ctx)
+	targets, err := d.provider.client.Targets().List(ctx, "")
 
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get target list",
-			"Failed to get target list",
+			fmt.Sprintf("Failed to get target list. (Error: %s)", err),
 		)
 		return
 	}
 
-	if response.HTTPResponse.StatusCode != 200 {
-		resp.Diagnostics.AddError(
-			"Failed to get target list, wrong error code.",
-			fmt.Sprintf("Failed to get target list. (Error code: %d)", response.HTTPResponse.StatusCode),
-		)
-		return
-	}
+	tflog.Info(ctx, fmt.Sprintf("Found %d targets.", len(targets)))
 
-	tflog.Info(ctx, fmt.Sprintf("Found %d targets.", len(*response.JSON200)))
-
-	for _, target := range *response.JSON200 {
+	for _, target := range targets {
 
 		tflog.Trace(ctx, fmt.Sprintf("Found %v", target))
 
-		httpoptions, err := ParseHttpOptions(target.Options)
+		if kind, _ := target.Options.Discriminator(); kind != warpgate.TargetKindHttp {
+			tflog.Debug(ctx, fmt.Sprintf("Target %s is a %s target, not http. Continuing.", target.Name, kind))
+			continue
+		}
+
+		httpoptions, err := ParseHttpOptions(target.Options, nil)
 
 		if err != nil || httpoptions == nil {
 			tflog.Info(ctx, fmt.Sprintf("Target %v is not http, skipping.", target))
@@ -141,7 +141,7 @@ func (d *httpTargetListDataSource) Read(ctx context.Context, req datasource.Read
 			AllowRoles: ArrayOfStringToTerraformSet(target.AllowRoles),
 			Id:         types.StringValue(target.Id.String()),
 			Name:       types.StringValue(target.Name),
-			Options: &provider_models.TargetHttpOptions{
+			Options: &provider_models.TargetHttpListOptions{
 				ExternalHost: httpoptions.ExternalHost,
 				Url:          httpoptions.Url,
 				Headers:      httpoptions.Headers,
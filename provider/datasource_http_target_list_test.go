@@ -18,17 +18,17 @@ func TestAccHttpTargetListDataSource(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("warpgate_http_target.one", "name", "one"),
 					testCheckFuncValidUUID("warpgate_http_target.one", "id"),
-					resource.TestCheckResourceAttr("warpgate_http_target.one", "options.url", "10.10.10.10"),
+					resource.TestCheckResourceAttr("warpgate_http_target.one", "options.url", "http://10.10.10.10"),
 					// resource.TestCheckResourceAttr("warpgate_http_target.one", "options.port", "11"),
 
 					resource.TestCheckResourceAttr("warpgate_http_target.two", "name", "two"),
 					testCheckFuncValidUUID("warpgate_http_target.two", "id"),
-					resource.TestCheckResourceAttr("warpgate_http_target.two", "options.url", "20.20.20.20"),
+					resource.TestCheckResourceAttr("warpgate_http_target.two", "options.url", "http://20.20.20.20"),
 					// resource.TestCheckResourceAttr("warpgate_http_target.two", "options.port", "22"),
 
 					resource.TestCheckResourceAttr("warpgate_http_target.three", "name", "three"),
 					testCheckFuncValidUUID("warpgate_http_target.three", "id"),
-					resource.TestCheckResourceAttr("warpgate_http_target.three", "options.url", "30.30.30.30"),
+					resource.TestCheckResourceAttr("warpgate_http_target.three", "options.url", "http://30.30.30.30"),
 					// resource.TestCheckResourceAttr("warpgate_http_target.three", "options.port", "33"),
 				),
 			},
@@ -63,7 +63,7 @@ resource "warpgate_http_target" "one" {
 	name = "one"
 	options = {
 		external_host = "192.168.1.1"
-		url = "10.10.10.10"
+		url = "http://10.10.10.10"
 		tls = {
 			mode = "Preferred" 
 			verify = true
@@ -76,7 +76,7 @@ resource "warpgate_http_target" "one" {
 resource "warpgate_http_target" "two" {
 	name = "two"
 	options = {
-		url = "20.20.20.20"
+		url = "http://20.20.20.20"
 		tls = {
 			mode = "Preferred" 
 			verify = true
@@ -86,7 +86,7 @@ resource "warpgate_http_target" "two" {
 resource "warpgate_http_target" "three" {
 	name = "three"
 	options = {
-		url = "30.30.30.30"
+		url = "http://30.30.30.30"
 		tls = {
 			mode = "Preferred" 
 			verify = true
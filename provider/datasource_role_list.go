@@ -88,27 +88,19 @@ func (d *roleListDataSource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 
-	response, err := d.provider.client.GetRolesWithResponse(ctx)
+	roles, err := d.provider.client.Roles().List(ctx)
 
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get role list",
-			"Failed to get role list",
-		)
-		return
-	}
-
-	if response.HTTPResponse.StatusCode != 200 {
-		resp.Diagnostics.AddError(
-			"Failed to get role list, wrong error code.",
-			fmt.Sprintf("Failed to get role list. (Error code: %d)", response.HTTPResponse.StatusCode),
+			fmt.Sprintf("Failed to get role list. (Error: %s)", err),
 		)
 		return
 	}
 
-	tflog.Info(ctx, fmt.Sprintf("Found %d roles.", len(*response.JSON200)))
+	tflog.Info(ctx, fmt.Sprintf("Found %d roles.", len(roles)))
 
-	for _, role := range *response.JSON200 {
+	for _, role := range roles {
 
 		tflog.Trace(ctx, fmt.Sprintf("Found %v", role))
 
@@ -102,36 +102,33 @@ func (d *sshTargetListDataSource) Read(ctx context.Context, req datasource.ReadR
 		return
 	}
 
-	response, err := d.provider.client.GetTargetsWithResponse(ctx)
+	targets, err := d.provider.client.Targets().List(ctx, "")
 
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get target list",
-			"Failed to get target list",
+			fmt.Sprintf("Failed to get target list. (Error: %s)", err),
 		)
 		return
 	}
 
-	if response.HTTPResponse.StatusCode != 200 {
-		resp.Diagnostics.AddError(
-			"Failed to get target list, wrong error code.",
-			fmt.Sprintf("Failed to get target list. (Error code: %d)", response.HTTPResponse.StatusCode),
-		)
-		return
-	}
+	tflog.Info(ctx, fmt.Sprintf("Found %d targets.", len(targets)))
 
-	tflog.Info(ctx, fmt.Sprintf("Found %d targets.", len(*response.JSON200)))
-
-	for _, target := range *response.JSON200 {
+	for _, target := range targets {
 
 		tflog.Trace(ctx, fmt.Sprintf("Found %v", target))
 
+		if kind, _ := target.Options.Discriminator(); kind != warpgate.TargetKindSsh {
+			tflog.Debug(ctx, fmt.Sprintf("Target %s is a %s target, not ssh. Continuing.", target.Name, kind))
+			continue
+		}
+
 		sshoptions, err := ParseSshOptions(target.Options)
 
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Failed to read ssh target. Wrong options",
-				fmt.Sprintf("Failed to read ssh target %v. Wrong options type. (Error: %v ", response.JSON200, err),
+				fmt.Sprintf("Failed to read ssh target %v. Wrong options type. (Error: %v ", targets, err),
 			)
 			return
 		}
@@ -3,14 +3,17 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/crypto/ssh"
 
 	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/provider/sshkeys"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces
@@ -19,17 +22,30 @@ var _ datasource.DataSource = &sshkeyListDataSource{}
 func (d sshkeyListDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
-			"id":   schema.StringAttribute{Computed: true},
-			"kind": schema.StringAttribute{Computed: false, Required: true},
+			"id": schema.StringAttribute{Computed: true},
+			"kind": schema.StringAttribute{
+				Optional:    true,
+				Description: "Return only the keys of this kind (e.g. `ssh-ed25519`). All the keys are returned if not set.",
+			},
+			"comment": schema.StringAttribute{
+				Optional:    true,
+				Description: "The comment appended to `authorized_key` and `authorized_keys`. No comment is added if not set.",
+			},
 			"sshkeys": schema.ListNestedAttribute{
 				Computed: true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
-						"kind":              schema.StringAttribute{Computed: true},
-						"public_key_base64": schema.StringAttribute{Computed: true},
+						"kind":               schema.StringAttribute{Computed: true},
+						"public_key_base64":  schema.StringAttribute{Computed: true},
+						"authorized_key":     schema.StringAttribute{Computed: true, Description: "The key as an OpenSSH authorized_keys line."},
+						"fingerprint_sha256": schema.StringAttribute{Computed: true, Description: "The SHA256 fingerprint of the key, as printed by `ssh-keygen -l`."},
 					},
 				},
 			},
+			"authorized_keys": schema.StringAttribute{
+				Computed:    true,
+				Description: "All the distinct keys in the authorized_keys format, one per line, ready to be added to the authorized_keys of the targets.",
+			},
 		},
 	}
 }
@@ -77,9 +93,11 @@ func (d *sshkeyListDataSource) Configure(ctx context.Context, req datasource.Con
 }
 func (d *sshkeyListDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var resourceState struct {
-		Id      types.String             `tfsdk:"id"`
-		Kind    string                   `tfsdk:"kind"`
-		SshKeys []provider_models.SshKey `tfsdk:"sshkeys"`
+		Id             types.String             `tfsdk:"id"`
+		Kind           types.String             `tfsdk:"kind"`
+		Comment        types.String             `tfsdk:"comment"`
+		SshKeys        []provider_models.SshKey `tfsdk:"sshkeys"`
+		AuthorizedKeys types.String             `tfsdk:"authorized_keys"`
 	}
 
 	diags := req.Config.Get(ctx, &resourceState)
@@ -89,40 +107,58 @@ func (d *sshkeyListDataSource) Read(ctx context.Context, req datasource.ReadRequ
 		return
 	}
 
-	response, err := d.provider.client.GetSshOwnKeysWithResponse(ctx)
+	ownKeys, err := d.provider.client.Ssh().OwnKeys(ctx)
 
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to get sshkey list",
-			"Failed to get sshkey list",
+			fmt.Sprintf("Failed to get sshkey list. (Error: %s)", err),
 		)
 		return
 	}
 
-	if response.HTTPResponse.StatusCode != 200 {
-		resp.Diagnostics.AddError(
-			"Failed to get sshkey list, wrong error code.",
-			fmt.Sprintf("Failed to get sshkey list. (Error code: %d)", response.HTTPResponse.StatusCode),
-		)
-		return
-	}
+	tflog.Info(ctx, fmt.Sprintf("Found %d sshkeys.", len(ownKeys)))
 
-	tflog.Info(ctx, fmt.Sprintf("Found %d sshkeys.", len(*response.JSON200)))
+	// the same key is listed once for every signature algorithm (e.g.
+	// rsa-sha2-256 and rsa-sha2-512), but it must be authorized only once.
+	var authorizedKeys strings.Builder
+	seen := map[string]bool{}
 
-	for _, sshkey := range *response.JSON200 {
+	for _, sshkey := range ownKeys {
 
 		tflog.Trace(ctx, fmt.Sprintf("Found %v", sshkey))
 
-		if sshkey.Kind != resourceState.Kind {
+		if !resourceState.Kind.IsNull() && sshkey.Kind != resourceState.Kind.ValueString() {
 			continue
 		}
 
+		publicKey, err := sshkeys.ParseBase64(sshkey.PublicKeyBase64)
+
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to parse sshkey",
+				fmt.Sprintf("Failed to parse the sshkey of kind %s. (Error: %s)", sshkey.Kind, err),
+			)
+			return
+		}
+
+		authorizedKey := sshkeys.AuthorizedKey(publicKey, resourceState.Comment.ValueString())
+
 		resourceState.SshKeys = append(resourceState.SshKeys, provider_models.SshKey{
-			Kind:            types.StringValue(sshkey.Kind),
-			PublicKeyBase64: types.StringValue(sshkey.PublicKeyBase64),
+			Kind:              types.StringValue(sshkey.Kind),
+			PublicKeyBase64:   types.StringValue(sshkey.PublicKeyBase64),
+			AuthorizedKey:     types.StringValue(authorizedKey),
+			FingerprintSha256: types.StringValue(ssh.FingerprintSHA256(publicKey)),
 		})
+
+		if !seen[sshkey.PublicKeyBase64] {
+			seen[sshkey.PublicKeyBase64] = true
+			authorizedKeys.WriteString(authorizedKey + "\n")
+		}
 	}
 
+	resourceState.AuthorizedKeys = types.StringValue(authorizedKeys.String())
+
 	randomUUID, _ := uuid.NewRandom()
 	resourceState.Id = types.StringValue(randomUUID.String())
 
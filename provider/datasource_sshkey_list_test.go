@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -23,6 +24,18 @@ func TestAccSshKeyListDataSource(t *testing.T) {
 					resource.TestCheckResourceAttr("data.warpgate_sshkey_list.rsa_sha2_256", "sshkeys.#", "1"),
 					resource.TestCheckResourceAttr("data.warpgate_sshkey_list.rsa_sha2_256", "kind", "rsa-sha2-256"),
 					resource.TestCheckResourceAttr("data.warpgate_sshkey_list.rsa_sha2_256", "sshkeys.0.kind", "rsa-sha2-256"),
+					resource.TestMatchResourceAttr("data.warpgate_sshkey_list.rsa_sha2_256", "sshkeys.0.authorized_key", regexp.MustCompile(`^ssh-rsa \S+$`)),
+				),
+			},
+			// Test all the keys with a comment
+			{
+				Config: testAccSshKeyListAllDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.warpgate_sshkey_list.all", "sshkeys.#"),
+					resource.TestCheckNoResourceAttr("data.warpgate_sshkey_list.all", "kind"),
+					resource.TestMatchResourceAttr("data.warpgate_sshkey_list.all", "sshkeys.0.authorized_key", regexp.MustCompile(`^\S+ \S+ warpgate@example.com$`)),
+					resource.TestMatchResourceAttr("data.warpgate_sshkey_list.all", "sshkeys.0.fingerprint_sha256", regexp.MustCompile(`^SHA256:`)),
+					resource.TestMatchResourceAttr("data.warpgate_sshkey_list.all", "authorized_keys", regexp.MustCompile(`^ssh-ed25519 \S+ warpgate@example.com\n`)),
 				),
 			},
 		},
@@ -42,3 +55,13 @@ data "warpgate_sshkey_list" "rsa_sha2_256" {
 }
 `
 }
+
+func testAccSshKeyListAllDataSourceConfig() string {
+	return `
+provider "warpgate" {}
+
+data "warpgate_sshkey_list" "all" {
+	comment = "warpgate@example.com"
+}
+`
+}
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	provider_models "terraform-provider-warpgate/provider/models"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ datasource.DataSource = &ssoProvidersDataSource{}
+
+func (d ssoProvidersDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The sso providers configured in the warpgate config file.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{Computed: true},
+			"providers": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name":  schema.StringAttribute{Computed: true, Description: "The name of the provider, used as the provider of the `Sso` credentials."},
+						"label": schema.StringAttribute{Computed: true, Description: "The label shown on the login page."},
+						"kind":  schema.StringAttribute{Computed: true, Description: "The kind of the provider (e.g. `Google`, `Azure` or `Custom`)."},
+					},
+				},
+			},
+			"names": schema.SetAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The names of the providers.",
+			},
+		},
+	}
+}
+
+func NewSsoProvidersDataSource() datasource.DataSource {
+	return &ssoProvidersDataSource{}
+}
+
+type ssoProvidersDataSource struct {
+	provider *warpgateProvider
+}
+
+func (d *ssoProvidersDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_sso_providers"
+}
+
+func (d *ssoProvidersDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*warpgateProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *warpgateProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !provider.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"Expected a configured provider but it wasn't. Please report this issue to the provider developers.",
+		)
+
+		return
+	}
+
+	d.provider = provider
+
+}
+
+func (d *ssoProvidersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var resourceState provider_models.SsoProviders
+
+	diags := req.Config.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	providers, err := d.provider.client.Sso().Providers(ctx)
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to get sso provider list",
+			fmt.Sprintf("Failed to get sso provider list. (Error: %s)", err),
+		)
+		return
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("Found %d sso providers.", len(providers)))
+
+	names := []string{}
+	resourceState.Providers = []provider_models.SsoProvider{}
+
+	for _, provider := range providers {
+		resourceState.Providers = append(resourceState.Providers, provider_models.SsoProvider{
+			Name:  types.StringValue(provider.Name),
+			Label: types.StringValue(provider.Label),
+			Kind:  types.StringValue(provider.Kind),
+		})
+
+		names = append(names, provider.Name)
+	}
+
+	resourceState.Names = ArrayOfStringToTerraformSet(names)
+
+	randomUUID, _ := uuid.NewRandom()
+	resourceState.Id = types.StringValue(randomUUID.String())
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
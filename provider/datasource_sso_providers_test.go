@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccSsoProvidersDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Test the datasource
+			{
+				Config: testAccSsoProvidersDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.warpgate_sso_providers.test", "providers.#", "1"),
+					resource.TestCheckResourceAttr("data.warpgate_sso_providers.test", "providers.0.name", "google"),
+					resource.TestCheckResourceAttr("data.warpgate_sso_providers.test", "providers.0.kind", "Google"),
+					resource.TestCheckTypeSetElemAttr("data.warpgate_sso_providers.test", "names.*", "google"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSsoProvidersDataSourceConfig() string {
+	return `
+provider "warpgate" {}
+
+data "warpgate_sso_providers" "test" {}
+`
+}
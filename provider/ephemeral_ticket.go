@@ -0,0 +1,249 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/provider/validators"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ ephemeral.EphemeralResource = &ticketEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &ticketEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithClose = &ticketEphemeralResource{}
+
+// ticketIdPrivateKey is the key of the private data holding the ticket id
+// between Open and Close.
+const ticketIdPrivateKey = "ticket_id"
+
+// Default ports of the warpgate listeners, used by the connection string.
+var defaultTicketPorts = map[string]int64{
+	warpgate.TargetKindSsh:      2222,
+	warpgate.TargetKindMySql:    33306,
+	warpgate.TargetKindPostgres: 55432,
+}
+
+func (r ticketEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "A ticket that exists only for the duration of a terraform run: it is created when opened " +
+			"and deleted when closed, so the secret is never stored in the state.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Id of the ticket in warpgate",
+			},
+			"username": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The user that authenticates with the ticket.",
+			},
+			"target_name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The name of the target the ticket gives access to.",
+			},
+			"host": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The host of warpgate used in `connection_string`, defaults to the host of the provider.",
+				Validators:          []validator.String{validators.IsHostOrIp()},
+			},
+			"port": schema.Int64Attribute{
+				Optional: true,
+				MarkdownDescription: "The port of the warpgate listener used in `connection_string`, defaults to " +
+					"2222 for ssh targets, 33306 for mysql targets, 55432 for postgres targets and the port of the provider for http targets.",
+				Validators: []validator.Int64{int64validator.Between(1, 65535)},
+			},
+			"secret": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The secret of the ticket.",
+			},
+			"connection_string": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The ssh, mysql or psql command line, or the url for http targets, to connect with the ticket.",
+			},
+		},
+	}
+}
+
+func NewTicketEphemeralResource() ephemeral.EphemeralResource {
+	return &ticketEphemeralResource{}
+}
+
+type ticketEphemeralResource struct {
+	provider *warpgateProvider
+}
+
+func (r *ticketEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ticket"
+}
+
+func (r *ticketEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*warpgateProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *warpgateProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !provider.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"Expected a configured provider but it wasn't. Please report this issue to the provider developers.",
+		)
+
+		return
+	}
+
+	r.provider = provider
+}
+
+func (r *ticketEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var ticket provider_models.Ticket
+
+	diags := req.Config.Get(ctx, &ticket)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.provider.client.Tickets().Create(ctx, warpgate.CreateTicketRequest{
+		Username:   ticket.Username.ValueString(),
+		TargetName: ticket.TargetName.ValueString(),
+	})
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create ticket",
+			fmt.Sprintf("Failed to create ticket for %s on target %s. (Error: %s)", ticket.Username, ticket.TargetName, err),
+		)
+		return
+	}
+
+	ticketId, err := json.Marshal(created.Ticket.Id.String())
+
+	if err == nil {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, ticketIdPrivateKey, ticketId)...)
+	}
+
+	ticket.Id = types.StringValue(created.Ticket.Id.String())
+	ticket.Secret = types.StringValue(created.Secret)
+	ticket.ConnectionString = types.StringNull()
+
+	connectionString, err := r.connectionString(ctx, ticket)
+
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Failed to build the ticket connection string",
+			fmt.Sprintf("The connection_string of the ticket is null. (Error: %s)", err),
+		)
+	} else {
+		ticket.ConnectionString = types.StringValue(connectionString)
+	}
+
+	diags = resp.Result.Set(ctx, &ticket)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *ticketEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	data, diags := req.Private.GetKey(ctx, ticketIdPrivateKey)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() || data == nil {
+		return
+	}
+
+	var ticketId string
+
+	if err := json.Unmarshal(data, &ticketId); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse the ticket id",
+			fmt.Sprintf("Failed to parse the ticket id %s. (Error: %s)", data, err),
+		)
+		return
+	}
+
+	id_as_uuid, err := uuid.Parse(ticketId)
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse the id as uuid",
+			fmt.Sprintf("Failed to parse the id '%s' as uuid", ticketId),
+		)
+		return
+	}
+
+	err = r.provider.client.Tickets().Delete(ctx, id_as_uuid)
+
+	if errors.Is(err, warpgate.ErrNotFound) {
+		tflog.Info(ctx, fmt.Sprintf("Ticket %s already deleted.", ticketId))
+		return
+	}
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete ticket",
+			fmt.Sprintf("Failed to delete ticket with id '%s'. (Error: %s)", ticketId, err),
+		)
+		return
+	}
+}
+
+// connectionString looks up the kind of the target to build the connection
+// string of the ticket.
+func (r *ticketEphemeralResource) connectionString(ctx context.Context, ticket provider_models.Ticket) (string, error) {
+	targets, err := r.provider.client.Targets().List(ctx, "")
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, target := range targets {
+		if target.Name != ticket.TargetName.ValueString() {
+			continue
+		}
+
+		kind, err := target.Options.Discriminator()
+
+		if err != nil {
+			return "", err
+		}
+
+		host := r.provider.client.Address
+		if !ticket.Host.IsNull() {
+			host = ticket.Host.ValueString()
+		}
+
+		port, ok := defaultTicketPorts[kind]
+		if !ok {
+			port = int64(r.provider.client.Port)
+		}
+		if !ticket.Port.IsNull() {
+			port = ticket.Port.ValueInt64()
+		}
+
+		return TicketConnectionString(kind, host, port, ticket.Secret.ValueString())
+	}
+
+	return "", fmt.Errorf("target %s not found", ticket.TargetName.ValueString())
+}
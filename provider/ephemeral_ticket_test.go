@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccTicketEphemeralResource(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The ticket is used during the run and deleted when closed
+			{
+				Config: testAccTicketEphemeralResourceConfig("ticket-test"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("warpgate_ssh_target.test", "id"),
+					testCheckNoTicketsFor("ticket-test"),
+				),
+			},
+		},
+	})
+}
+
+// testCheckNoTicketsFor checks that no ticket of the user is left in warpgate.
+func testCheckNoTicketsFor(username string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client, err := NewClientFromEnv()
+
+		if err != nil {
+			return err
+		}
+
+		response, err := client.GetTicketsWithResponse(context.Background())
+
+		if err != nil {
+			return err
+		}
+
+		if response.StatusCode() != 200 {
+			return fmt.Errorf("failed to list the tickets (Error code: %d)", response.StatusCode())
+		}
+
+		for _, ticket := range *response.JSON200 {
+			if ticket.Username == username {
+				return fmt.Errorf("ticket %s of %s was not deleted", ticket.Id, username)
+			}
+		}
+
+		return nil
+	}
+}
+
+func testAccTicketEphemeralResourceConfig(username string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_user" "test" {
+	username = "%s"
+	credentials = []
+}
+
+resource "warpgate_ssh_target" "test" {
+	name = "ticket-target"
+	options = {
+		host = "10.10.10.10"
+		port = 22
+		username = "root"
+		auth_kind = "PublicKey"
+	}
+}
+
+ephemeral "warpgate_ticket" "test" {
+	username    = warpgate_user.test.username
+	target_name = warpgate_ssh_target.test.name
+}
+`, username)
+}
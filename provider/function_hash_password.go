@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ function.Function = &hashPasswordFunction{}
+
+func NewHashPasswordFunction() function.Function {
+	return &hashPasswordFunction{}
+}
+
+type hashPasswordFunction struct{}
+
+func (f *hashPasswordFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "hash_password"
+}
+
+func (f *hashPasswordFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Hash a password for a warpgate Password credential",
+		MarkdownDescription: "Returns the argon2id hash of the password, in the format expected by the `hash` of the " +
+			"`Password` credentials of `warpgate_user`, computed like the `hash` of `warpgate_user_password`. " +
+			"The salt is required since functions must always return the same result for the same arguments, " +
+			"use a random value kept in the state (e.g. `random_password`).",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "password",
+				Description: "The plaintext password.",
+			},
+			function.StringParameter{
+				Name:        "salt",
+				Description: "The salt of the hash, at least 8 bytes long.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *hashPasswordFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var password, salt string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &password, &salt))
+
+	if resp.Error != nil {
+		return
+	}
+
+	hash, err := HashPassword(password, salt)
+
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(1, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, hash))
+}
@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccHashPasswordFunction(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "hash" {
+	value = provider::warpgate::hash_password("password", "saltsalt")
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("hash", "$argon2id$v=19$m=4096,t=3,p=1$c2FsdHNhbHQ$kJvQUZvNZLLzIw4lXL0nVb2HtJqyPrFaO3b7fbBoYo4"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"terraform-provider-warpgate/provider/sshkeys"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ function.Function = &sshKeyFingerprintFunction{}
+
+func NewSshKeyFingerprintFunction() function.Function {
+	return &sshKeyFingerprintFunction{}
+}
+
+type sshKeyFingerprintFunction struct{}
+
+func (f *sshKeyFingerprintFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "ssh_key_fingerprint"
+}
+
+func (f *sshKeyFingerprintFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Compute the SHA256 fingerprint of a ssh public key",
+		MarkdownDescription: "Returns the SHA256 fingerprint of a public key in the authorized_keys format, as printed by " +
+			"`ssh-keygen -l` and as the `fingerprint_sha256` of the `PublicKey` credentials of `warpgate_user`.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "public_key",
+				Description: "The public key, e.g. `ssh-ed25519 AAAA... user@host`.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *sshKeyFingerprintFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var publicKey string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &publicKey))
+
+	if resp.Error != nil {
+		return
+	}
+
+	fingerprint, _, err := sshkeys.Fingerprint(publicKey)
+
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, fingerprint))
+}
@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccSshKeyFingerprintFunction(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+output "fingerprint" {
+	value = provider::warpgate::ssh_key_fingerprint(%q)
+}
+`, testUserPublicKeyA),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("fingerprint", "SHA256:ug9edX55FAfpkL8yPrXtiYNjuwRUJ4XfNG2wfCA53SY"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ function.Function = &ticketConnectionStringFunction{}
+
+func NewTicketConnectionStringFunction() function.Function {
+	return &ticketConnectionStringFunction{}
+}
+
+type ticketConnectionStringFunction struct{}
+
+func (f *ticketConnectionStringFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "ticket_connection_string"
+}
+
+func (f *ticketConnectionStringFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Build the command or url to connect to a target with a warpgate ticket",
+		MarkdownDescription: "Returns how to connect through warpgate with a ticket: the ssh, mysql or psql command line " +
+			"for `Ssh`, `MySql` and `Postgres` targets, the url for `Http` targets.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "kind",
+				Description: "The kind of the target: `Ssh`, `Http`, `MySql` or `Postgres`.",
+			},
+			function.StringParameter{
+				Name:        "host",
+				Description: "The host of warpgate.",
+			},
+			function.Int64Parameter{
+				Name:        "port",
+				Description: "The port of the warpgate listener of the target kind.",
+			},
+			function.StringParameter{
+				Name:        "secret",
+				Description: "The secret of the ticket.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ticketConnectionStringFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var kind, host, secret string
+	var port int64
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &kind, &host, &port, &secret))
+
+	if resp.Error != nil {
+		return
+	}
+
+	connectionString, err := TicketConnectionString(kind, host, port, secret)
+
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, connectionString))
+}
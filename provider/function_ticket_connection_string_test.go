@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTicketConnectionStringFunction(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "ssh" {
+	value = provider::warpgate::ticket_connection_string("Ssh", "warpgate.example.com", 2222, "secret")
+}
+
+output "http" {
+	value = provider::warpgate::ticket_connection_string("Http", "warpgate.example.com", 8888, "secret")
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("ssh", "ssh ticket-secret@warpgate.example.com -p 2222"),
+					resource.TestCheckOutput("http", "https://warpgate.example.com:8888/?warpgate-ticket=secret"),
+				),
+			},
+		},
+	})
+}
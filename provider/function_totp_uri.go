@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ function.Function = &totpUriFunction{}
+
+func NewTotpUriFunction() function.Function {
+	return &totpUriFunction{}
+}
+
+type totpUriFunction struct{}
+
+func (f *totpUriFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "totp_uri"
+}
+
+func (f *totpUriFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Build the otpauth:// uri of a warpgate Totp credential",
+		MarkdownDescription: "Returns the `otpauth://totp/...` uri of a totp key, the one encoded in the QR codes " +
+			"read by authenticator apps. The key is a list of bytes, as the `totp_key` of the `Totp` credentials of `warpgate_user`.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:        "key",
+				ElementType: types.Int64Type,
+				Description: "The totp key as list of bytes.",
+			},
+			function.StringParameter{
+				Name:        "username",
+				Description: "The username shown by the authenticator app.",
+			},
+			function.StringParameter{
+				Name:        "issuer",
+				Description: "The issuer shown by the authenticator app (e.g. `Warpgate`), can be empty.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *totpUriFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var key types.List
+	var username, issuer string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &key, &username, &issuer))
+
+	if resp.Error != nil {
+		return
+	}
+
+	for i, value := range key.Elements() {
+		if value.IsNull() || value.IsUnknown() {
+			resp.Error = function.NewArgumentFuncError(0, "The totp key cannot contain null values.")
+			return
+		}
+
+		if byteValue := value.(types.Int64).ValueInt64(); byteValue < 0 || byteValue > 255 {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("The totp key must be a list of bytes, found %d at index %d.", byteValue, i))
+			return
+		}
+	}
+
+	uri, err := TotpUri(TerraformListToArrayOfUint16(key), username, issuer)
+
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, uri))
+}
@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccTotpUriFunction(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "uri" {
+	value = provider::warpgate::totp_uri([1, 2, 3, 4, 5, 6, 7, 8, 9, 10], "alice@example.com", "Warpgate")
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("uri", "otpauth://totp/Warpgate:alice@example.com?algorithm=SHA1&digits=6&issuer=Warpgate&period=30&secret=AEBAGBAFAYDQQCIK"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// Import ids can either be the raw uuid of the object or a `<key>:<value>`
+// pair that is resolved through the list endpoints, e.g. `name:prod-db`.
+const (
+	importKeyId       = "id"
+	importKeyName     = "name"
+	importKeyUsername = "username"
+)
+
+func ParseImportId(id string) (key string, value string) {
+	parts := strings.SplitN(id, ":", 2)
+
+	if len(parts) == 1 {
+		return importKeyId, id
+	}
+
+	return parts[0], parts[1]
+}
+
+func ResolveRoleImportId(ctx context.Context, client *warpgate.WarpgateClient, importId string) (string, error) {
+	key, value := ParseImportId(importId)
+
+	switch key {
+	case importKeyId:
+		return value, nil
+	case importKeyName:
+	default:
+		return "", fmt.Errorf("unsupported import id '%s'. Expected '<uuid>' or 'name:<name>'", importId)
+	}
+
+	roles, err := client.Roles().List(ctx)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get role list (Error: %s)", err)
+	}
+
+	matches := []string{}
+
+	for _, role := range roles {
+		if role.Name == value {
+			matches = append(matches, role.Id.String())
+		}
+	}
+
+	return singleImportMatch("role", key, value, matches)
+}
+
+func ResolveUserImportId(ctx context.Context, client *warpgate.WarpgateClient, importId string) (string, error) {
+	key, value := ParseImportId(importId)
+
+	switch key {
+	case importKeyId:
+		return value, nil
+	case importKeyUsername:
+	default:
+		return "", fmt.Errorf("unsupported import id '%s'. Expected '<uuid>' or 'username:<username>'", importId)
+	}
+
+	users, err := client.Users().List(ctx)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get user list (Error: %s)", err)
+	}
+
+	matches := []string{}
+
+	for _, user := range users {
+		if user.Username == value {
+			matches = append(matches, user.Id.String())
+		}
+	}
+
+	return singleImportMatch("user", key, value, matches)
+}
+
+// ResolveTargetImportId resolves the import id of a target. If kind is not
+// empty the target, found by id or by name, must be of that kind (e.g. "Ssh",
+// "Http"): the targets of the other kinds with the same name are ignored.
+func ResolveTargetImportId(ctx context.Context, client *warpgate.WarpgateClient, importId string, kind string) (string, error) {
+	key, value := ParseImportId(importId)
+
+	switch key {
+	case importKeyId:
+		if kind == "" {
+			return value, nil
+		}
+
+		return resolveTargetImportUuid(ctx, client, value, kind)
+	case importKeyName:
+	default:
+		return "", fmt.Errorf("unsupported import id '%s'. Expected '<uuid>' or 'name:<name>'", importId)
+	}
+
+	targets, err := client.Targets().List(ctx, "")
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get target list (Error: %s)", err)
+	}
+
+	matches := []string{}
+	otherKinds := []string{}
+
+	for _, target := range targets {
+		if target.Name != value {
+			continue
+		}
+
+		targetKind, err := target.Options.Discriminator()
+
+		if err != nil {
+			return "", fmt.Errorf("failed to read the kind of target '%s' (Error: %s)", target.Id, err)
+		}
+
+		if kind != "" && targetKind != kind {
+			otherKinds = append(otherKinds, fmt.Sprintf("%s (id: %s)", targetKind, target.Id))
+			continue
+		}
+
+		matches = append(matches, target.Id.String())
+	}
+
+	if len(matches) == 0 && len(otherKinds) > 0 {
+		return "", fmt.Errorf("no %s target found with %s '%s', only targets of other kinds: %s", kind, key, value, strings.Join(otherKinds, ", "))
+	}
+
+	return singleImportMatch("target", key, value, matches)
+}
+
+// resolveTargetImportUuid checks that the target with the id is of the kind.
+func resolveTargetImportUuid(ctx context.Context, client *warpgate.WarpgateClient, value string, kind string) (string, error) {
+	id, err := uuid.Parse(value)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the id '%s' as uuid (Error: %s)", value, err)
+	}
+
+	target, err := client.Targets().Get(ctx, id)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read target '%s' (Error: %s)", value, err)
+	}
+
+	if err := checkTargetKind(target, kind); err != nil {
+		return "", err
+	}
+
+	return target.Id.String(), nil
+}
+
+func singleImportMatch(objectType string, key string, value string, matches []string) (string, error) {
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no %s found with %s '%s'", objectType, key, value)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("ambiguous import: %d %ss found with %s '%s' (ids: %s). Import by id instead", len(matches), objectType, key, value, strings.Join(matches, ", "))
+	}
+}
+
+func importStateResolvedId(ctx context.Context, id string, err error, objectType string, resp *resource.ImportStateResponse) {
+	if err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("Failed to import %s", objectType),
+			fmt.Sprintf("Failed to import %s. (Error: %s)", objectType, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+}
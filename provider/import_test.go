@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"terraform-provider-warpgate/warpgate"
+	"testing"
+)
+
+func TestResolveTargetImportIdKind(t *testing.T) {
+	server, p := testActionServer(t)
+	ctx := context.Background()
+
+	sshOptions := warpgate.TargetOptions{}
+	auth := warpgate.SSHTargetAuth{}
+	_ = auth.FromSSHTargetAuthSshTargetPublicKeyAuth(warpgate.SSHTargetAuthSshTargetPublicKeyAuth{})
+	_ = sshOptions.FromTargetOptionsTargetSSHOptions(warpgate.TargetOptionsTargetSSHOptions{Host: "10.0.0.1", Port: 22, Username: "root", Auth: auth})
+
+	httpOptions := warpgate.TargetOptions{}
+	_ = httpOptions.FromTargetOptionsTargetHTTPOptions(warpgate.TargetOptionsTargetHTTPOptions{Url: "https://10.0.0.2", Tls: warpgate.Tls{Mode: warpgate.Preferred}})
+
+	sshTarget := server.AddTarget("shared", sshOptions)
+	httpTarget := server.AddTarget("shared", httpOptions)
+
+	cases := []struct {
+		importId string
+		kind     string
+		expected string
+		err      string
+	}{
+		{"name:shared", warpgate.TargetKindSsh, sshTarget.String(), ""},
+		{"name:shared", warpgate.TargetKindHttp, httpTarget.String(), ""},
+		{"name:shared", "", "", "ambiguous import"},
+		{"name:shared", warpgate.TargetKindPostgres, "", "only targets of other kinds"},
+		{sshTarget.String(), warpgate.TargetKindSsh, sshTarget.String(), ""},
+		{httpTarget.String(), warpgate.TargetKindSsh, "", "is a Http target, not a Ssh target"},
+		{httpTarget.String(), "", httpTarget.String(), ""},
+		{"not-a-uuid", warpgate.TargetKindSsh, "", "failed to parse"},
+	}
+
+	for _, c := range cases {
+		id, err := ResolveTargetImportId(ctx, p.client, c.importId, c.kind)
+
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s (%s): expected an error containing %q, got %v", c.importId, c.kind, c.err, err)
+			}
+			continue
+		}
+
+		if err != nil || id != c.expected {
+			t.Errorf("%s (%s): expected %s, got %s (Error: %v)", c.importId, c.kind, c.expected, id, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// inlineRolesPrivateKey is the key of the private data holding the names of
+// the roles last assigned by the roles attribute of a user or target, to
+// detect the ones changed by other resources.
+const inlineRolesPrivateKey = "inline_roles"
+
+// privateStateSetter is the private data of the create and update responses.
+type privateStateSetter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// applyInlineRoles assigns the planned roles of a user or target, configured
+// by name. A null or unknown plan, i.e. roles not configured, leaves the roles
+// as they are. The returned set holds the roles assigned afterwards, to be
+// saved into the state even if some of the changes failed.
+func applyInlineRoles(ctx context.Context, client *warpgate.WarpgateClient, reconciler RoleReconciler, current []string, planned types.Set, private privateStateSetter) (types.Set, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if planned.IsNull() || planned.IsUnknown() {
+		diags.Append(private.SetKey(ctx, inlineRolesPrivateKey, nil)...)
+		return ArrayOfStringToTerraformSet(current), diags
+	}
+
+	desired := []string{}
+	diags.Append(planned.ElementsAs(ctx, &desired, false)...)
+
+	if diags.HasError() {
+		return ArrayOfStringToTerraformSet(current), diags
+	}
+
+	roles, err := client.Roles().List(ctx)
+
+	if err != nil {
+		diags.AddError(
+			"Failed to read roles",
+			fmt.Sprintf("Failed to read the roles to assign to %s. (Error: %s)", reconciler.ObjectName, err),
+		)
+		return ArrayOfStringToTerraformSet(current), diags
+	}
+
+	achieved, reconcileDiags := reconciler.ReconcileNames(ctx, roles, current, desired)
+	diags.Append(reconcileDiags...)
+
+	applied, err := json.Marshal(achieved)
+
+	if err != nil {
+		diags.AddError("Failed to save the assigned roles", err.Error())
+		return ArrayOfStringToTerraformSet(achieved), diags
+	}
+
+	diags.Append(private.SetKey(ctx, inlineRolesPrivateKey, applied)...)
+
+	return ArrayOfStringToTerraformSet(achieved), diags
+}
+
+// warnInlineRolesChanged warns when the roles configured in attribute changed
+// outside of the resource since its last apply, usually because the same user
+// or target is also managed by rolesResource: each one would remove the roles
+// assigned by the other at every apply.
+// It does not detect rolesResource itself, only its effect: the refreshed
+// state is compared with the roles of the last apply, so nothing is reported
+// at the first plan that adds rolesResource, before it assigned any role, nor
+// with -refresh=false.
+func warnInlineRolesChanged(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, attribute string, rolesResource string) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var configured types.Set
+	var state types.Set
+
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attribute), &configured)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root(attribute), &state)...)
+
+	if resp.Diagnostics.HasError() || configured.IsNull() || state.IsNull() || state.IsUnknown() {
+		return
+	}
+
+	data, diags := req.Private.GetKey(ctx, inlineRolesPrivateKey)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() || data == nil {
+		return
+	}
+
+	var applied []string
+
+	if err := json.Unmarshal(data, &applied); err != nil {
+		return
+	}
+
+	current := []string{}
+	resp.Diagnostics.Append(state.ElementsAs(ctx, &current, false)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, added, removed := ArrayIntersection(current, applied)
+
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	resp.Diagnostics.AddAttributeWarning(
+		path.Root(attribute),
+		"Roles changed outside of the resource",
+		fmt.Sprintf("The %s changed since the last apply (added: [%s], removed: [%s]), probably because they are also managed by %s. "+
+			"Manage them either with `%s` or with %s, otherwise each one reverts the changes of the other at every apply.",
+			attribute, strings.Join(added, ", "), strings.Join(removed, ", "), rolesResource, attribute, rolesResource),
+	)
+}
@@ -1,9 +1,21 @@
 package models
 
-import "github.com/hashicorp/terraform-plugin-framework/types"
+import (
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
 
 type Role struct {
 	// ID have to be nullable
 	Id   types.String `tfsdk:"id"`
 	Name types.String `tfsdk:"name"`
 }
+
+type RoleResource struct {
+	Id          types.String   `tfsdk:"id"`
+	Name        types.String   `tfsdk:"name"`
+	ForceDetach types.Bool     `tfsdk:"force_detach"`
+	UserIds     types.Set      `tfsdk:"user_ids"`
+	TargetIds   types.Set      `tfsdk:"target_ids"`
+	Timeouts    timeouts.Value `tfsdk:"timeouts"`
+}
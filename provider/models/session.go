@@ -0,0 +1,11 @@
+package models
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+type CloseSession struct {
+	SessionId types.String `tfsdk:"session_id"`
+}
+
+type CloseUserSessions struct {
+	Username types.String `tfsdk:"username"`
+}
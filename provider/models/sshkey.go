@@ -3,6 +3,8 @@ package models
 import "github.com/hashicorp/terraform-plugin-framework/types"
 
 type SshKey struct {
-	Kind            types.String `tfsdk:"kind"`
-	PublicKeyBase64 types.String `tfsdk:"public_key_base64"`
+	Kind              types.String `tfsdk:"kind"`
+	PublicKeyBase64   types.String `tfsdk:"public_key_base64"`
+	AuthorizedKey     types.String `tfsdk:"authorized_key"`
+	FingerprintSha256 types.String `tfsdk:"fingerprint_sha256"`
 }
@@ -0,0 +1,16 @@
+package models
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+type SsoProvider struct {
+	Name  types.String `tfsdk:"name"`
+	Label types.String `tfsdk:"label"`
+	Kind  types.String `tfsdk:"kind"`
+}
+
+// SsoProviders is the warpgate_sso_providers data source.
+type SsoProviders struct {
+	Id        types.String  `tfsdk:"id"`
+	Providers []SsoProvider `tfsdk:"providers"`
+	Names     types.Set     `tfsdk:"names"`
+}
@@ -1,6 +1,9 @@
 package models
 
-import "github.com/hashicorp/terraform-plugin-framework/types"
+import (
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
 
 // type TargetOptions interface{}
 
@@ -22,22 +25,62 @@ type TargetSSHOptions struct {
 	AuthKind types.String `tfsdk:"auth_kind"`
 }
 
+// TargetSshResource is TargetSsh with the write-only attributes, which only
+// the resource has.
+type TargetSshResource struct {
+	AllowRoles types.Set                 `tfsdk:"allow_roles"`
+	Id         types.String              `tfsdk:"id"`
+	Name       types.String              `tfsdk:"name"`
+	Options    *TargetSSHResourceOptions `tfsdk:"options"`
+
+	CloseSessionsOnChange types.Bool `tfsdk:"close_sessions_on_change"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+type TargetSSHResourceOptions struct {
+	TargetSSHOptions
+	PasswordWo        types.String `tfsdk:"password_wo"`
+	PasswordWoVersion types.Int64  `tfsdk:"password_wo_version"`
+}
+
 /////////////////////////////////////////
 /////////////////////////////////////////
 
 type TargetHttp struct {
+	AllowRoles types.Set              `tfsdk:"allow_roles"`
+	Id         types.String           `tfsdk:"id"`
+	Name       types.String           `tfsdk:"name"`
+	Options    *TargetHttpListOptions `tfsdk:"options"`
+}
+
+type TargetHttpResource struct {
 	AllowRoles types.Set          `tfsdk:"allow_roles"`
 	Id         types.String       `tfsdk:"id"`
 	Name       types.String       `tfsdk:"name"`
 	Options    *TargetHttpOptions `tfsdk:"options"`
+
+	CloseSessionsOnChange types.Bool `tfsdk:"close_sessions_on_change"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
 }
 
 type TargetHttpOptions struct {
+	ExternalHost     types.String `tfsdk:"external_host"`
+	Url              types.String `tfsdk:"url"`
+	Tls              *TargetTls   `tfsdk:"tls"`
+	Headers          types.Map    `tfsdk:"headers"`
+	SensitiveHeaders types.Map    `tfsdk:"sensitive_headers"`
+	// Headers      *map[string]string `tfsdk:"headers"`
+}
+
+// TargetHttpListOptions are the options of the http target list data source,
+// which has no sensitive_headers: warpgate returns all the headers together.
+type TargetHttpListOptions struct {
 	ExternalHost types.String `tfsdk:"external_host"`
 	Url          types.String `tfsdk:"url"`
 	Tls          *TargetTls   `tfsdk:"tls"`
 	Headers      types.Map    `tfsdk:"headers"`
-	// Headers      *map[string]string `tfsdk:"headers"`
 }
 
 // type TargetHttpOptions_Headers struct {
@@ -51,3 +94,80 @@ type TargetTls struct {
 
 /////////////////////////////////////////
 /////////////////////////////////////////
+
+/////////////////////////////////////////
+/////////////////////////////////////////
+
+type TargetMySqlOptions struct {
+	Host     types.String `tfsdk:"host"`
+	Port     types.Int64  `tfsdk:"port"` // uint16
+	Username types.String `tfsdk:"username"`
+	Password types.String `tfsdk:"password"`
+	Tls      *TargetTls   `tfsdk:"tls"`
+}
+
+type TargetMySqlResourceOptions struct {
+	TargetMySqlOptions
+	PasswordWo        types.String `tfsdk:"password_wo"`
+	PasswordWoVersion types.Int64  `tfsdk:"password_wo_version"`
+}
+
+type TargetPostgres struct {
+	AllowRoles types.Set              `tfsdk:"allow_roles"`
+	Id         types.String           `tfsdk:"id"`
+	Name       types.String           `tfsdk:"name"`
+	Options    *TargetPostgresOptions `tfsdk:"options"`
+}
+
+type TargetPostgresResource struct {
+	AllowRoles types.Set              `tfsdk:"allow_roles"`
+	Id         types.String           `tfsdk:"id"`
+	Name       types.String           `tfsdk:"name"`
+	Options    *TargetPostgresOptions `tfsdk:"options"`
+
+	CloseSessionsOnChange types.Bool `tfsdk:"close_sessions_on_change"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+type TargetPostgresOptions struct {
+	Host     types.String `tfsdk:"host"`
+	Port     types.Int64  `tfsdk:"port"` // uint16
+	Username types.String `tfsdk:"username"`
+	Password types.String `tfsdk:"password"`
+	Tls      *TargetTls   `tfsdk:"tls"`
+}
+
+type TargetPostgresResourceOptions struct {
+	TargetPostgresOptions
+	PasswordWo        types.String `tfsdk:"password_wo"`
+	PasswordWoVersion types.Int64  `tfsdk:"password_wo_version"`
+}
+
+/////////////////////////////////////////
+/////////////////////////////////////////
+
+// TargetWebAdminOptions has no attributes, the web admin target is built
+// into warpgate.
+type TargetWebAdminOptions struct{}
+
+/////////////////////////////////////////
+/////////////////////////////////////////
+
+// TargetResource is a target of any kind, only the options of its kind are
+// not nil.
+type TargetResource struct {
+	AllowRoles types.Set                      `tfsdk:"allow_roles"`
+	Id         types.String                   `tfsdk:"id"`
+	Name       types.String                   `tfsdk:"name"`
+	Kind       types.String                   `tfsdk:"kind"`
+	Ssh        *TargetSSHResourceOptions      `tfsdk:"ssh"`
+	Http       *TargetHttpOptions             `tfsdk:"http"`
+	MySql      *TargetMySqlResourceOptions    `tfsdk:"mysql"`
+	Postgres   *TargetPostgresResourceOptions `tfsdk:"postgres"`
+	WebAdmin   *TargetWebAdminOptions         `tfsdk:"web_admin"`
+
+	CloseSessionsOnChange types.Bool `tfsdk:"close_sessions_on_change"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
@@ -1,8 +1,12 @@
 package models
 
-import "github.com/hashicorp/terraform-plugin-framework/types"
+import (
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
 
 type TargetRoles struct {
-	Id      types.String `tfsdk:"id"`
-	RoleIds types.Set    `tfsdk:"role_ids"`
+	Id       types.String   `tfsdk:"id"`
+	RoleIds  types.Set      `tfsdk:"role_ids"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
 }
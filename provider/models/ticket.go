@@ -0,0 +1,13 @@
+package models
+
+import "github.com/hashicorp/terraform-plugin-framework/types"
+
+type Ticket struct {
+	Id               types.String `tfsdk:"id"`
+	Username         types.String `tfsdk:"username"`
+	TargetName       types.String `tfsdk:"target_name"`
+	Host             types.String `tfsdk:"host"`
+	Port             types.Int64  `tfsdk:"port"`
+	Secret           types.String `tfsdk:"secret"`
+	ConnectionString types.String `tfsdk:"connection_string"`
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"context"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"terraform-provider-warpgate/provider/sshkeys"
 
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
@@ -25,27 +27,127 @@ type User struct {
 	Username    types.String `tfsdk:"username"`
 	Credentials types.Set    `tfsdk:"credentials"` // []UserAuthCredential
 	Roles       types.Set    `tfsdk:"roles"`
+
+	// typed alternative to credentials
+	Password   *UserPasswordCredential `tfsdk:"password"`
+	Totp       *UserTotpCredential     `tfsdk:"totp"`
+	Sso        types.Set               `tfsdk:"sso"` // []UserSsoCredential
+	PublicKeys types.Set               `tfsdk:"public_keys"`
+
+	CloseSessionsOnChange types.Bool `tfsdk:"close_sessions_on_change"`
+
+	PasswordHashWo        types.String `tfsdk:"password_hash_wo"`
+	PasswordHashWoVersion types.Int64  `tfsdk:"password_hash_wo_version"`
+	TotpKeyWo             types.List   `tfsdk:"totp_key_wo"` //[]uint8
+	TotpKeyWoVersion      types.Int64  `tfsdk:"totp_key_wo_version"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
 }
 
 type UserAuthCredential struct {
 	// Id        types.String `tfsdk:"id"`
-	Kind      types.String `tfsdk:"kind"`
-	Hash      types.String `tfsdk:"hash"`
-	Email     types.String `tfsdk:"email"`
-	Provider  types.String `tfsdk:"provider"`
-	TotpKey   types.List   `tfsdk:"totp_key"`   //[]uint8
-	PublicKey types.String `tfsdk:"public_key"` //string
+	Kind              types.String      `tfsdk:"kind"`
+	Hash              types.String      `tfsdk:"hash"`
+	Email             types.String      `tfsdk:"email"`
+	Provider          types.String      `tfsdk:"provider"`
+	TotpKey           types.List        `tfsdk:"totp_key"` //[]uint8
+	PublicKey         sshkeys.PublicKey `tfsdk:"public_key"`
+	FingerprintSha256 types.String      `tfsdk:"fingerprint_sha256"`
+	KeyType           types.String      `tfsdk:"key_type"`
 }
 
-func (u User) CredentialsAsArray(ctx context.Context) ([]UserAuthCredential, error) {
-	if u.Credentials.IsNull() {
-		return nil, nil
+type UserPasswordCredential struct {
+	Hash types.String `tfsdk:"hash"`
+}
+
+type UserTotpCredential struct {
+	Key types.List `tfsdk:"key"` //[]uint8
+}
+
+type UserSsoCredential struct {
+	Email    types.String `tfsdk:"email"`
+	Provider types.String `tfsdk:"provider"`
+}
+
+// NewUserAuthCredential returns a credential of the kind with all the other
+// attributes null.
+func NewUserAuthCredential(kind string) UserAuthCredential {
+	return UserAuthCredential{
+		Kind:              types.StringValue(kind),
+		Hash:              types.StringNull(),
+		Email:             types.StringNull(),
+		Provider:          types.StringNull(),
+		TotpKey:           types.ListNull(types.Int64Type),
+		PublicKey:         sshkeys.NewPublicKeyNull(),
+		FingerprintSha256: types.StringNull(),
+		KeyType:           types.StringNull(),
 	}
+}
 
+// CredentialsAsArray returns the credentials set either with credentials or
+// with the typed attributes (password, totp, sso and public_keys).
+func (u User) CredentialsAsArray(ctx context.Context) ([]UserAuthCredential, error) {
 	var vars []UserAuthCredential
-	err := u.Credentials.ElementsAs(ctx, &vars, true)
-	if err != nil {
-		return nil, fmt.Errorf("error reading credentials: %s", err)
+
+	if !u.Credentials.IsNull() {
+		err := u.Credentials.ElementsAs(ctx, &vars, true)
+		if err != nil {
+			return nil, fmt.Errorf("error reading credentials: %s", err)
+		}
 	}
+
+	if u.Password != nil {
+		credential := NewUserAuthCredential("Password")
+		credential.Hash = u.Password.Hash
+		vars = append(vars, credential)
+	}
+
+	if u.Totp != nil {
+		credential := NewUserAuthCredential("Totp")
+		credential.TotpKey = u.Totp.Key
+		vars = append(vars, credential)
+	}
+
+	if !u.Sso.IsNull() {
+		var sso []UserSsoCredential
+		err := u.Sso.ElementsAs(ctx, &sso, true)
+		if err != nil {
+			return nil, fmt.Errorf("error reading sso credentials: %s", err)
+		}
+
+		for _, s := range sso {
+			credential := NewUserAuthCredential("Sso")
+			credential.Email = s.Email
+			credential.Provider = s.Provider
+			vars = append(vars, credential)
+		}
+	}
+
+	if !u.PublicKeys.IsNull() {
+		var publicKeys []sshkeys.PublicKey
+		err := u.PublicKeys.ElementsAs(ctx, &publicKeys, true)
+		if err != nil {
+			return nil, fmt.Errorf("error reading public keys: %s", err)
+		}
+
+		for _, publicKey := range publicKeys {
+			credential := NewUserAuthCredential("PublicKey")
+			credential.PublicKey = publicKey
+			vars = append(vars, credential)
+		}
+	}
+
 	return vars, nil
 }
+
+// ManagesCredentials reports if the credentials are set by the resource,
+// otherwise they are left to the per-credential resources.
+func (u User) ManagesCredentials() bool {
+	return !u.Credentials.IsNull() || u.UsesTypedCredentials() || !u.PasswordHashWoVersion.IsNull() || !u.TotpKeyWoVersion.IsNull()
+}
+
+// UsesTypedCredentials reports if the credentials are set with the typed
+// attributes instead of credentials.
+func (u User) UsesTypedCredentials() bool {
+	return u.Credentials.IsNull() && (u.Password != nil || u.Totp != nil || !u.Sso.IsNull() || !u.PublicKeys.IsNull())
+}
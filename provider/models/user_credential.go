@@ -0,0 +1,40 @@
+package models
+
+import (
+	"terraform-provider-warpgate/provider/sshkeys"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type UserPasswordResource struct {
+	Id       types.String   `tfsdk:"id"`
+	UserId   types.String   `tfsdk:"user_id"`
+	Password types.String   `tfsdk:"password"`
+	Hash     types.String   `tfsdk:"hash"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+type UserTotpResource struct {
+	Id       types.String   `tfsdk:"id"`
+	UserId   types.String   `tfsdk:"user_id"`
+	Key      types.List     `tfsdk:"key"` //[]uint8
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+type UserSsoCredentialResource struct {
+	Id       types.String   `tfsdk:"id"`
+	UserId   types.String   `tfsdk:"user_id"`
+	Email    types.String   `tfsdk:"email"`
+	Provider types.String   `tfsdk:"sso_provider"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+type UserPublicKeyResource struct {
+	Id                types.String      `tfsdk:"id"`
+	UserId            types.String      `tfsdk:"user_id"`
+	PublicKey         sshkeys.PublicKey `tfsdk:"public_key"`
+	FingerprintSha256 types.String      `tfsdk:"fingerprint_sha256"`
+	KeyType           types.String      `tfsdk:"key_type"`
+	Timeouts          timeouts.Value    `tfsdk:"timeouts"`
+}
@@ -1,9 +1,13 @@
 package models
 
-import "github.com/hashicorp/terraform-plugin-framework/types"
+import (
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
 
 type UserRoles struct {
 	// ID have to be nullable
-	Id      types.String `tfsdk:"id"`
-	RoleIds types.Set    `tfsdk:"role_ids"`
+	Id       types.String   `tfsdk:"id"`
+	RoleIds  types.Set      `tfsdk:"role_ids"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
 }
@@ -7,9 +7,15 @@ import (
 	"strconv"
 	"terraform-provider-warpgate/provider/validators"
 	"terraform-provider-warpgate/warpgate"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -26,9 +32,20 @@ func New(version string) func() provider.Provider {
 }
 
 var _ provider.Provider = &warpgateProvider{}
+var _ provider.ProviderWithFunctions = &warpgateProvider{}
+var _ provider.ProviderWithEphemeralResources = &warpgateProvider{}
+var _ provider.ProviderWithActions = &warpgateProvider{}
 
 // var _ provider.ProviderWithMetaSchema = &warpgateProvider{}
 
+const (
+	// defaultRequestTimeout bounds the requests when request_timeout is not set.
+	defaultRequestTimeout = time.Minute
+	// defaultResourceTimeout bounds the operations of the resources without
+	// a timeouts block.
+	defaultResourceTimeout = 10 * time.Minute
+)
+
 type warpgateProvider struct {
 	configured bool
 	version    string
@@ -48,7 +65,7 @@ func (d warpgateProvider) Schema(ctx context.Context, req provider.SchemaRequest
 				Description: "The hostname of the warpgate server",
 				Optional:    true,
 				Validators: []validator.String{
-					validators.IsDomain(),
+					validators.IsHostOrIp(),
 				},
 			},
 			"port": schema.Int64Attribute{
@@ -71,17 +88,41 @@ func (d warpgateProvider) Schema(ctx context.Context, req provider.SchemaRequest
 				Description: "If to skip the verification of the tls certificate (For self signed certificates)",
 				Optional:    true,
 			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				Description: "The maximum number of requests sent to the warpgate server at the same time, shared by all resources and data sources (Unlimited by default)",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"requests_per_second": schema.Float64Attribute{
+				Description: "The maximum number of requests per second sent to the warpgate server, shared by all resources and data sources (Unlimited by default)",
+				Optional:    true,
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0.1),
+				},
+			},
+			"request_timeout": schema.StringAttribute{
+				Description: "The maximum duration of a single request to the warpgate server, e.g. \"30s\" (1m by default)",
+				Optional:    true,
+				Validators: []validator.String{
+					validators.IsPositiveDuration(),
+				},
+			},
 		},
 	}
 }
 
 // Provider schema struct
 type providerData struct {
-	Host               types.String `tfsdk:"host"`
-	Port               types.Int64  `tfsdk:"port"`
-	Username           types.String `tfsdk:"username"`
-	Password           types.String `tfsdk:"password"`
-	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+	Host                  types.String  `tfsdk:"host"`
+	Port                  types.Int64   `tfsdk:"port"`
+	Username              types.String  `tfsdk:"username"`
+	Password              types.String  `tfsdk:"password"`
+	InsecureSkipVerify    types.Bool    `tfsdk:"insecure_skip_verify"`
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+	RequestsPerSecond     types.Float64 `tfsdk:"requests_per_second"`
+	RequestTimeout        types.String  `tfsdk:"request_timeout"`
 }
 
 func (p *warpgateProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
@@ -93,16 +134,35 @@ func (p *warpgateProvider) Configure(ctx context.Context, req provider.Configure
 		return
 	}
 
+	if !checkForUnknowsInConfig(&config, resp) {
+		return
+	}
+
+	p.client, diags = newClientFromConfig(config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	p.configured = true
+
+	resp.DataSourceData = p
+	resp.ResourceData = p
+	resp.EphemeralResourceData = p
+	resp.ActionData = p
+}
+
+// newClientFromConfig resolves the provider configuration, falling back to the
+// WARPGATE_* environment variables for unset attributes, and logs into the
+// warpgate server.
+func newClientFromConfig(config providerData) (client *warpgate.WarpgateClient, diags diag.Diagnostics) {
 	var err error
 	var host string
 	var port int
 	var username string
 	var password string
 	var insecureSkipVerify bool
-
-	if !checkForUnknowsInConfig(&config, resp) {
-		return
-	}
+	var limits warpgate.RequestLimits
 
 	if config.Host.IsNull() {
 		host = os.Getenv("WARPGATE_HOST")
@@ -123,11 +183,11 @@ func (p *warpgateProvider) Configure(ctx context.Context, req provider.Configure
 		} else {
 			port, err = strconv.Atoi(portString)
 			if err != nil {
-				resp.Diagnostics.AddError(
+				diags.AddError(
 					"Invalid port",
 					"The port must be an integer",
 				)
-				return
+				return nil, diags
 			}
 		}
 	} else {
@@ -146,11 +206,11 @@ func (p *warpgateProvider) Configure(ctx context.Context, req provider.Configure
 		if len(envValue) > 0 {
 			insecureSkipVerify, err = strconv.ParseBool(envValue)
 			if err != nil {
-				resp.Diagnostics.AddError(
+				diags.AddError(
 					"Invalid insecureSkipVerify",
 					"The insecureSkipVerify must be a valid bool (Valid true values: '1', 't', 'T', 'true', 'TRUE', 'True'. Valid false values: '0', 'f', 'F', 'false', 'FALSE', 'False')",
 				)
-				return
+				return nil, diags
 			}
 		} else {
 			insecureSkipVerify = false
@@ -160,31 +220,99 @@ func (p *warpgateProvider) Configure(ctx context.Context, req provider.Configure
 		insecureSkipVerify = config.InsecureSkipVerify.ValueBool()
 	}
 
+	if config.MaxConcurrentRequests.IsNull() {
+		if envValue := os.Getenv("WARPGATE_MAX_CONCURRENT_REQUESTS"); envValue != "" {
+			limits.MaxConcurrentRequests, err = strconv.Atoi(envValue)
+			if err != nil || limits.MaxConcurrentRequests < 1 {
+				diags.AddError(
+					"Invalid max_concurrent_requests",
+					"The max_concurrent_requests must be an integer greater than 0",
+				)
+				return nil, diags
+			}
+		}
+	} else {
+		limits.MaxConcurrentRequests = int(config.MaxConcurrentRequests.ValueInt64())
+	}
+
+	if config.RequestsPerSecond.IsNull() {
+		if envValue := os.Getenv("WARPGATE_REQUESTS_PER_SECOND"); envValue != "" {
+			limits.RequestsPerSecond, err = strconv.ParseFloat(envValue, 64)
+			if err != nil || limits.RequestsPerSecond < 0.1 {
+				diags.AddError(
+					"Invalid requests_per_second",
+					"The requests_per_second must be a number greater than or equal to 0.1",
+				)
+				return nil, diags
+			}
+		}
+	} else {
+		limits.RequestsPerSecond = config.RequestsPerSecond.ValueFloat64()
+	}
+
+	requestTimeout := os.Getenv("WARPGATE_REQUEST_TIMEOUT")
+
+	if !config.RequestTimeout.IsNull() {
+		requestTimeout = config.RequestTimeout.ValueString()
+	}
+
+	if requestTimeout == "" {
+		limits.Timeout = defaultRequestTimeout
+	} else {
+		limits.Timeout, err = time.ParseDuration(requestTimeout)
+		if err != nil || limits.Timeout <= 0 {
+			diags.AddError(
+				"Invalid request_timeout",
+				"The request_timeout must be a positive duration such as \"30s\" or \"2m\"",
+			)
+			return nil, diags
+		}
+	}
+
 	if username == "" {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Unable to find username",
 			"Username cannot be an empty string",
 		)
-		return
+		return nil, diags
 	}
 
-	p.client = warpgate.NewWarpgateClient(host, port, insecureSkipVerify)
+	client = warpgate.NewWarpgateClient(host, port, insecureSkipVerify, limits)
 
-	err = p.client.Login(username, password)
+	err = client.Login(username, password)
 
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			// "Unable to login",
-			fmt.Sprintf("Unable to login, %s:%s@%s:%d", username, password, host, port),
+			fmt.Sprintf("Unable to login, %s@%s:%d", username, host, port),
 			err.Error(),
 		)
-		return
+		return nil, diags
 	}
 
-	p.configured = true
+	return client, diags
+}
 
-	resp.DataSourceData = p
-	resp.ResourceData = p
+// NewClientFromEnv logs into the warpgate server using the same environment
+// variables as the provider (WARPGATE_HOST, WARPGATE_PORT, ...).
+func NewClientFromEnv() (*warpgate.WarpgateClient, error) {
+	client, diags := newClientFromConfig(providerData{
+		Host:                  types.StringNull(),
+		Port:                  types.Int64Null(),
+		Username:              types.StringNull(),
+		Password:              types.StringNull(),
+		InsecureSkipVerify:    types.BoolNull(),
+		MaxConcurrentRequests: types.Int64Null(),
+		RequestsPerSecond:     types.Float64Null(),
+		RequestTimeout:        types.StringNull(),
+	})
+
+	if diags.HasError() {
+		d := diags.Errors()[0]
+		return nil, fmt.Errorf("%s: %s", d.Summary(), d.Detail())
+	}
+
+	return client, nil
 }
 
 func checkForUnknowsInConfig(config *providerData, resp *provider.ConfigureResponse) bool {
@@ -211,6 +339,30 @@ func checkForUnknowsInConfig(config *providerData, resp *provider.ConfigureRespo
 		)
 		return false
 	}
+
+	if config.MaxConcurrentRequests.IsUnknown() {
+		resp.Diagnostics.AddWarning(
+			"Unable to create client",
+			"Cannot use unknown value as max_concurrent_requests",
+		)
+		return false
+	}
+
+	if config.RequestsPerSecond.IsUnknown() {
+		resp.Diagnostics.AddWarning(
+			"Unable to create client",
+			"Cannot use unknown value as requests_per_second",
+		)
+		return false
+	}
+
+	if config.RequestTimeout.IsUnknown() {
+		resp.Diagnostics.AddWarning(
+			"Unable to create client",
+			"Cannot use unknown value as request_timeout",
+		)
+		return false
+	}
 	return true
 }
 
@@ -218,10 +370,16 @@ func (p *warpgateProvider) Resources(ctx context.Context) []func() resource.Reso
 	return []func() resource.Resource{
 		NewHttpTargetResource,
 		NewSshTargetResource,
+		NewPostgresTargetResource,
+		NewTargetResource,
 		NewRoleResource,
 		NewTargetRolesResource,
 		NewUserResource,
 		NewUserRolesResource,
+		NewUserPasswordResource,
+		NewUserTotpResource,
+		NewUserSsoCredentialResource,
+		NewUserPublicKeyResource,
 	}
 }
 
@@ -231,5 +389,29 @@ func (p *warpgateProvider) DataSources(ctx context.Context) []func() datasource.
 		NewSshkeyListDataSource,
 		NewSshTargetListDataSource,
 		NewHttpTargetListDataSource,
+		NewSsoProvidersDataSource,
+	}
+}
+
+func (p *warpgateProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewTicketEphemeralResource,
+	}
+}
+
+func (p *warpgateProvider) Actions(ctx context.Context) []func() action.Action {
+	return []func() action.Action{
+		NewCloseAllSessionsAction,
+		NewCloseSessionAction,
+		NewCloseUserSessionsAction,
+	}
+}
+
+func (p *warpgateProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewHashPasswordFunction,
+		NewSshKeyFingerprintFunction,
+		NewTicketConnectionStringFunction,
+		NewTotpUriFunction,
 	}
 }
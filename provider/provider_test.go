@@ -2,6 +2,7 @@ package provider
 
 import (
 	"os"
+	"terraform-provider-warpgate/warpgate/warpgatetest"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
@@ -16,6 +17,26 @@ var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServe
 	"warpgate": providerserver.NewProtocol6WithError(New("0.3.0")()),
 }
 
+// TestMain runs the acceptance tests against an in-memory warpgate server,
+// unless WARPGATE_TEST_SERVER=docker is set to use the real server started
+// by _scripts/testacc_setup.sh.
+func TestMain(m *testing.M) {
+	if os.Getenv("TF_ACC") == "" || os.Getenv("WARPGATE_TEST_SERVER") == "docker" {
+		os.Exit(m.Run())
+	}
+
+	server := warpgatetest.NewServer()
+
+	for name, value := range server.Env() {
+		os.Setenv(name, value)
+	}
+
+	code := m.Run()
+
+	server.Close()
+	os.Exit(code)
+}
+
 func testAccPreCheck(t *testing.T) {
 	checkEnvNotNull(t, "WARPGATE_HOST")
 	checkEnvNotNull(t, "WARPGATE_PORT")
@@ -2,18 +2,23 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	provider_models "terraform-provider-warpgate/provider/models"
 	"terraform-provider-warpgate/provider/validators"
 	"terraform-provider-warpgate/warpgate"
 
 	"github.com/google/uuid"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -23,9 +28,16 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &httpTargetResource{}
 var _ resource.ResourceWithImportState = &httpTargetResource{}
+var _ resource.ResourceWithUpgradeState = &httpTargetResource{}
+var _ resource.ResourceWithModifyPlan = &httpTargetResource{}
+
+// httpTargetStateMigrations upgrades the state of the older schema versions of the
+// http target resource, see NewStateUpgraders.
+var httpTargetStateMigrations = []StateMigration{}
 
 func (r httpTargetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: SchemaVersion(httpTargetStateMigrations),
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:            true,
@@ -33,54 +45,96 @@ func (r httpTargetResource) Schema(ctx context.Context, req resource.SchemaReque
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				}},
-			"allow_roles": schema.SetAttribute{Computed: true, ElementType: types.StringType},
+			"allow_roles": schema.SetAttribute{
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "The names of the roles allowed to access the target. When set, the roles are assigned and removed by this resource, " +
+					"otherwise they are only read and can be assigned with [target_roles](target_roles.md). Do not use both on the same target: the plan only warns about it once the roles changed outside of the resource since its last apply.",
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"close_sessions_on_change": closeSessionsOnChangeAttribute("on the target when the target is deleted or its url changes"),
 			"name": schema.StringAttribute{
 				Computed:            false,
 				Required:            true,
 				MarkdownDescription: "The username of the user.",
 			},
 			"options": schema.SingleNestedAttribute{
+				Computed:   false,
+				Required:   true,
+				Attributes: httpTargetOptionsAttributes(),
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// httpTargetOptionsAttributes are the options of warpgate_http_target and the
+// http options of warpgate_target.
+func httpTargetOptionsAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"external_host": schema.StringAttribute{
+			Computed:   false,
+			Optional:   true,
+			Validators: []validator.String{validators.IsHostOrHostPort()},
+		},
+		"url": schema.StringAttribute{
+			Computed:   false,
+			Required:   true,
+			Validators: []validator.String{validators.IsUrl("http", "https")},
+		},
+		"headers": schema.MapAttribute{
+			Computed:    false,
+			Optional:    true,
+			ElementType: types.StringType,
+		},
+		"sensitive_headers": schema.MapAttribute{
+			Computed:    false,
+			Optional:    true,
+			Sensitive:   true,
+			ElementType: types.StringType,
+			Description: "Headers hidden from the plan output, e.g. `Authorization`. " +
+				"They are sent to warpgate together with `headers`, so a header cannot be set in both. " +
+				"On import all the headers are read as `headers`.",
+			Validators: []validator.Map{
+				validators.HeaderNamesConflictWith(path.MatchRelative().AtParent().AtName("headers")),
+			},
+		},
+		"tls": targetTlsAttribute(),
+	}
+}
+
+// targetTlsAttribute is the tls configuration of the http, mysql and postgres
+// targets.
+func targetTlsAttribute() schema.Attribute {
+	return schema.SingleNestedAttribute{
+		Computed: false,
+		Required: true,
+		Attributes: map[string]schema.Attribute{
+			"mode": schema.StringAttribute{
 				Computed: false,
 				Required: true,
-				Attributes: map[string]schema.Attribute{
-					"external_host": schema.StringAttribute{
-						Computed:   false,
-						Optional:   true,
-						Validators: []validator.String{validators.IsDomain()},
-					},
-					"url": schema.StringAttribute{
-						Computed:   false,
-						Required:   true,
-						Validators: []validator.String{validators.IsDomain()},
-					},
-					"headers": schema.MapAttribute{
-						Computed:    false,
-						Optional:    true,
-						ElementType: types.StringType,
-					},
-					"tls": schema.SingleNestedAttribute{
-						Computed: false,
-						Required: true,
-						Attributes: map[string]schema.Attribute{
-							"mode": schema.StringAttribute{
-								Computed: false,
-								Required: true,
-								Validators: []validator.String{
-									stringvalidator.OneOf(
-										string(warpgate.Disabled),
-										string(warpgate.Preferred),
-										string(warpgate.Required),
-									),
-								},
-							},
-							"verify": schema.BoolAttribute{
-								Computed: false,
-								Required: true,
-							},
-						},
-					},
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						string(warpgate.Disabled),
+						string(warpgate.Preferred),
+						string(warpgate.Required),
+					),
 				},
 			},
+			"verify": schema.BoolAttribute{
+				Computed: false,
+				Required: true,
+			},
 		},
 	}
 }
@@ -127,7 +181,7 @@ type httpTargetResource struct {
 }
 
 func (r *httpTargetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var resourceState provider_models.TargetHttp
+	var resourceState provider_models.TargetHttpResource
 
 	diags := req.Config.Get(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -136,8 +190,17 @@ func (r *httpTargetResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	var headers map[string]string
-	diags = resourceState.Options.Headers.ElementsAs(ctx, &headers, false)
+	createTimeout, diags := resourceState.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	headers, diags := MergeHttpHeaders(ctx, resourceState.Options)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -156,7 +219,7 @@ func (r *httpTargetResource) Create(ctx context.Context, req resource.CreateRequ
 			},
 		})
 
-	response, err := r.provider.client.CreateTargetWithResponse(ctx, warpgate.CreateTargetJSONRequestBody{
+	target, err := r.provider.client.Targets().Create(ctx, warpgate.CreateTargetJSONRequestBody{
 		Name:    resourceState.Name.ValueString(),
 		Options: *targetOptions,
 	})
@@ -164,21 +227,16 @@ func (r *httpTargetResource) Create(ctx context.Context, req resource.CreateRequ
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to create http target",
-			"Failed to create http target",
+			fmt.Sprintf("Failed to create http target. (Error: %s)", err),
 		)
 		return
 	}
 
-	if response.StatusCode() != 201 {
-		resp.Diagnostics.AddError(
-			"Failed to create http target, wrong error code.",
-			fmt.Sprintf("Failed to create http target. (Error code: %d)", response.StatusCode()),
-		)
-		return
-	}
+	resourceState.Id = types.StringValue(target.Id.String())
+
+	resourceState.AllowRoles, diags = applyInlineRoles(ctx, r.provider.client, targetRoleReconciler(r.provider.client, target.Id), target.AllowRoles, resourceState.AllowRoles, resp.Private)
+	resp.Diagnostics.Append(diags...)
 
-	resourceState.Id = types.StringValue(response.JSON201.Id.String())
-	resourceState.AllowRoles = ArrayOfStringToTerraformSet(response.JSON201.AllowRoles)
 	// resourceState.Options = &provider_models.TargetHttpOptions{
 	// 	ExternalHost: resourceState.Options.ExternalHost,
 	// 	Url:          resourceState.Options.Url,
@@ -193,7 +251,7 @@ func (r *httpTargetResource) Create(ctx context.Context, req resource.CreateRequ
 }
 
 func (r *httpTargetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var resourceState provider_models.TargetHttp
+	var resourceState provider_models.TargetHttpResource
 
 	diags := req.State.Get(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -202,6 +260,16 @@ func (r *httpTargetResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
+	readTimeout, diags := resourceState.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
 	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
@@ -212,50 +280,51 @@ func (r *httpTargetResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	response, err := r.provider.client.GetTargetWithResponse(ctx, id_as_uuid)
+	target, err := r.provider.client.Targets().Get(ctx, id_as_uuid)
 
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to read http target",
+	if errors.Is(err, warpgate.ErrNotFound) {
+		resp.Diagnostics.AddWarning(
+			"Failed to read http target, resource not found. Removing from the state.",
 			fmt.Sprintf("Failed to read http target with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
+		resp.State.RemoveResource(ctx)
 		return
 	}
 
-	if response.StatusCode() == 404 {
-		resp.Diagnostics.AddWarning(
-			"Failed to read http target, resource not found. Removing from the state.",
-			fmt.Sprintf("Failed to read http target. (Error code: %d)", response.StatusCode()),
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read http target",
+			fmt.Sprintf("Failed to read http target with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
-		resp.State.RemoveResource(ctx)
 		return
 	}
 
-	if response.StatusCode() != 200 {
+	if err := checkTargetKind(target, warpgate.TargetKindHttp); err != nil {
 		resp.Diagnostics.AddError(
-			"Failed to read http target, wrong error code.",
-			fmt.Sprintf("Failed to read http target. (Error code: %d)", response.StatusCode()),
+			"Failed to read http target. Wrong kind",
+			fmt.Sprintf("Failed to read http target with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
 		return
 	}
 
-	httpoptions, err := ParseHttpOptions(response.JSON200.Options)
+	httpoptions, err := ParseHttpOptions(target.Options, resourceState.Options)
 
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to read http target. Wrong options",
-			fmt.Sprintf("Failed to read http target %v. Wrong options type. (Error: %v ", response.JSON200, err),
+			fmt.Sprintf("Failed to read http target %v. Wrong options type. (Error: %v ", target, err),
 		)
 		return
 	}
 
-	resourceState.AllowRoles = ArrayOfStringToTerraformSet(response.JSON200.AllowRoles)
-	resourceState.Name = types.StringValue(response.JSON200.Name)
+	resourceState.AllowRoles = ArrayOfStringToTerraformSet(target.AllowRoles)
+	resourceState.Name = types.StringValue(target.Name)
 	resourceState.Options = &provider_models.TargetHttpOptions{
-		ExternalHost: httpoptions.ExternalHost,
-		Headers:      httpoptions.Headers,
-		Tls:          httpoptions.Tls,
-		Url:          httpoptions.Url,
+		ExternalHost:     httpoptions.ExternalHost,
+		Headers:          httpoptions.Headers,
+		SensitiveHeaders: httpoptions.SensitiveHeaders,
+		Tls:              httpoptions.Tls,
+		Url:              httpoptions.Url,
 	}
 
 	diags = resp.State.Set(ctx, &resourceState)
@@ -263,15 +332,29 @@ func (r *httpTargetResource) Read(ctx context.Context, req resource.ReadRequest,
 }
 
 func (r *httpTargetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var resourcePlan provider_models.TargetHttp
+	var resourceState provider_models.TargetHttpResource
+	var resourcePlan provider_models.TargetHttpResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	diags = req.Plan.Get(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
 
-	diags := req.Plan.Get(ctx, &resourcePlan)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := resourcePlan.Timeouts.Update(ctx, defaultResourceTimeout)
 	resp.Diagnostics.Append(diags...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	id_as_uuid, err := uuid.Parse(resourcePlan.Id.ValueString())
 
 	if err != nil {
@@ -282,8 +365,7 @@ func (r *httpTargetResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
-	var headers map[string]string
-	diags = resourcePlan.Options.Headers.ElementsAs(ctx, &headers, false)
+	headers, diags := MergeHttpHeaders(ctx, resourcePlan.Options)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -302,7 +384,7 @@ func (r *httpTargetResource) Update(ctx context.Context, req resource.UpdateRequ
 			},
 		})
 
-	response, err := r.provider.client.UpdateTargetWithResponse(ctx, id_as_uuid, warpgate.UpdateTargetJSONRequestBody{
+	target, err := r.provider.client.Targets().Update(ctx, id_as_uuid, warpgate.UpdateTargetJSONRequestBody{
 		Name:    resourcePlan.Name.ValueString(),
 		Options: *targetOptions,
 	})
@@ -315,26 +397,24 @@ func (r *httpTargetResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
-	if response.StatusCode() != 200 {
-		resp.Diagnostics.AddError(
-			"Failed to update http target, wrong error code.",
-			fmt.Sprintf("Failed to update http target. (Error code: %d)", response.StatusCode()),
-		)
-		return
-	}
-
 	// probably unnecessary check
-	if response.JSON200.Id != id_as_uuid || response.JSON200.Name != resourcePlan.Name.ValueString() {
+	if target.Id != id_as_uuid || target.Name != resourcePlan.Name.ValueString() {
 		resp.Diagnostics.AddWarning(
 			"Created resource is different from requested.",
 			fmt.Sprintf("Created resource is different from requested. Requested: (%s, %s), Created: (%s, %s)",
-				response.JSON200.Id, response.JSON200.Name,
+				target.Id, target.Name,
 				resourcePlan.Id, resourcePlan.Name,
 			),
 		)
 		return
 	}
-	resourcePlan.AllowRoles = ArrayOfStringToTerraformSet(response.JSON200.AllowRoles)
+
+	resourcePlan.AllowRoles, diags = applyInlineRoles(ctx, r.provider.client, targetRoleReconciler(r.provider.client, id_as_uuid), target.AllowRoles, resourcePlan.AllowRoles, resp.Private)
+	resp.Diagnostics.Append(diags...)
+
+	if resourcePlan.CloseSessionsOnChange.ValueBool() && !resourceState.Options.Url.Equal(resourcePlan.Options.Url) {
+		resp.Diagnostics.Append(closeSessionsOnChange(ctx, r.provider.client, fmt.Sprintf("http target '%s'", resourceState.Name.ValueString()), targetSessions(id_as_uuid))...)
+	}
 
 	tflog.Debug(ctx, fmt.Sprintf("Updating http_target state: %v", resourcePlan))
 
@@ -343,7 +423,7 @@ func (r *httpTargetResource) Update(ctx context.Context, req resource.UpdateRequ
 }
 
 func (r *httpTargetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var resourceState provider_models.TargetHttp
+	var resourceState provider_models.TargetHttpResource
 
 	diags := req.State.Get(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -352,6 +432,16 @@ func (r *httpTargetResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
+	deleteTimeout, diags := resourceState.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
@@ -362,7 +452,7 @@ func (r *httpTargetResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
-	response, err := r.provider.client.DeleteTargetWithResponse(ctx, id_as_uuid)
+	err = r.provider.client.Targets().Delete(ctx, id_as_uuid)
 
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -372,20 +462,49 @@ func (r *httpTargetResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
-	if response.StatusCode() != 204 {
-		resp.Diagnostics.AddError(
-			"Failed to delete http target, wrong error code.",
-			fmt.Sprintf("Failed to delete http target. (Error code: %d)", response.StatusCode()),
-		)
-		return
+	if resourceState.CloseSessionsOnChange.ValueBool() {
+		resp.Diagnostics.Append(closeSessionsOnChange(ctx, r.provider.client, fmt.Sprintf("http target '%s'", resourceState.Name.ValueString()), targetSessions(id_as_uuid))...)
 	}
 }
 
+// ModifyPlan warns when the roles configured on the target changed outside of
+// the resource since the last apply, see warnInlineRolesChanged.
+func (r *httpTargetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	warnInlineRolesChanged(ctx, req, resp, "allow_roles", "warpgate_target_roles")
+}
+
 func (r *httpTargetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	id, err := ResolveTargetImportId(ctx, r.provider.client, req.ID, warpgate.TargetKindHttp)
+	importStateResolvedId(ctx, id, err, "http target", resp)
+}
+
+func (r *httpTargetResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return NewStateUpgraders(httpTargetStateMigrations)
+}
+
+// MergeHttpHeaders returns the headers and the sensitive headers as the
+// single map expected by warpgate.
+func MergeHttpHeaders(ctx context.Context, options *provider_models.TargetHttpOptions) (map[string]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	headers := map[string]string{}
+
+	for _, value := range []types.Map{options.Headers, options.SensitiveHeaders} {
+		var values map[string]string
+
+		diags.Append(value.ElementsAs(ctx, &values, false)...)
+
+		for name, header := range values {
+			headers[name] = header
+		}
+	}
+
+	return headers, diags
 }
 
-func ParseHttpOptions(options warpgate.TargetOptions) (result *provider_models.TargetHttpOptions, err error) {
+// ParseHttpOptions reads the http options of a target. Warpgate stores all
+// the headers in a single map, the headers that are in the sensitive_headers
+// of prior (if not nil) are moved back to SensitiveHeaders.
+func ParseHttpOptions(options warpgate.TargetOptions, prior *provider_models.TargetHttpOptions) (result *provider_models.TargetHttpOptions, err error) {
 	result = &provider_models.TargetHttpOptions{}
 
 	httpoptions, err := options.AsTargetOptionsTargetHTTPOptions()
@@ -406,11 +525,50 @@ func ParseHttpOptions(options warpgate.TargetOptions) (result *provider_models.T
 		Verify: types.BoolValue(httpoptions.Tls.Verify),
 	}
 
-	if httpoptions.Headers == nil {
-		result.Headers = types.MapNull(types.StringType)
+	priorHeaders := types.MapNull(types.StringType)
+	priorSensitiveHeaders := types.MapNull(types.StringType)
+
+	if prior != nil {
+		priorHeaders = prior.Headers
+		priorSensitiveHeaders = prior.SensitiveHeaders
+	}
+
+	sensitiveNames := map[string]bool{}
+	for name := range priorSensitiveHeaders.Elements() {
+		sensitiveNames[http.CanonicalHeaderKey(name)] = true
+	}
+
+	headers := map[string]string{}
+	sensitiveHeaders := map[string]string{}
+
+	if httpoptions.Headers != nil {
+		for name, value := range *httpoptions.Headers {
+			if sensitiveNames[http.CanonicalHeaderKey(name)] {
+				sensitiveHeaders[name] = value
+			} else {
+				headers[name] = value
+			}
+		}
 	}
 
-	result.Headers, _ = types.MapValueFrom(context.TODO(), types.StringType, httpoptions.Headers)
+	result.Headers = httpHeadersValue(headers, priorHeaders)
+	result.SensitiveHeaders = httpHeadersValue(sensitiveHeaders, priorSensitiveHeaders)
 
 	return result, err
 }
+
+// httpHeadersValue returns null for no headers, unless the prior value is an
+// empty map (i.e. `headers = {}` in the configuration).
+func httpHeadersValue(headers map[string]string, prior types.Map) types.Map {
+	if len(headers) == 0 {
+		if !prior.IsNull() && !prior.IsUnknown() && len(prior.Elements()) == 0 {
+			return prior
+		}
+
+		return types.MapNull(types.StringType)
+	}
+
+	value, _ := types.MapValueFrom(context.TODO(), types.StringType, headers)
+
+	return value
+}
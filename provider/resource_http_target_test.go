@@ -2,8 +2,13 @@ package provider
 
 import (
 	"fmt"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/warpgate"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
@@ -15,10 +20,10 @@ func TestAccHttpTargetResource(t *testing.T) {
 		Steps: []resource.TestStep{
 			// Create and Read testing
 			{
-				Config: testAccHttpTargetResourceConfig("one", "10.10.10.10"),
+				Config: testAccHttpTargetResourceConfig("one", "http://10.10.10.10"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("warpgate_http_target.test", "name", "one"),
-					resource.TestCheckResourceAttr("warpgate_http_target.test", "options.url", "10.10.10.10"),
+					resource.TestCheckResourceAttr("warpgate_http_target.test", "options.url", "http://10.10.10.10"),
 					resource.TestCheckResourceAttrSet("warpgate_http_target.test", "id"),
 					resource.TestCheckResourceAttrSet("warpgate_http_target.test", "name"),
 					resource.TestCheckResourceAttrSet("warpgate_http_target.test", "options.url"),
@@ -35,12 +40,19 @@ func TestAccHttpTargetResource(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			// ImportState by name testing
+			{
+				ResourceName:      "warpgate_http_target.test",
+				ImportState:       true,
+				ImportStateId:     "name:one",
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
-				Config: testAccHttpTargetResourceConfig("two", "20.20.20.20"),
+				Config: testAccHttpTargetResourceConfig("two", "https://20.20.20.20:8443"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("warpgate_http_target.test", "name", "two"),
-					resource.TestCheckResourceAttr("warpgate_http_target.test", "options.url", "20.20.20.20"),
+					resource.TestCheckResourceAttr("warpgate_http_target.test", "options.url", "https://20.20.20.20:8443"),
 					resource.TestCheckResourceAttrSet("warpgate_http_target.test", "id"),
 					resource.TestCheckResourceAttrSet("warpgate_http_target.test", "name"),
 					resource.TestCheckResourceAttrSet("warpgate_http_target.test", "options.url"),
@@ -51,6 +63,16 @@ func TestAccHttpTargetResource(t *testing.T) {
 					testCheckFuncValidUUID("warpgate_http_target.test", "id"),
 				),
 			},
+			// Sensitive headers testing
+			{
+				Config: testAccHttpTargetResourceHeadersConfig("two", "https://20.20.20.20:8443"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_http_target.test", "options.headers.%", "1"),
+					resource.TestCheckResourceAttr("warpgate_http_target.test", "options.headers.X-Forwarded-Proto", "https"),
+					resource.TestCheckResourceAttr("warpgate_http_target.test", "options.sensitive_headers.%", "1"),
+					resource.TestCheckResourceAttr("warpgate_http_target.test", "options.sensitive_headers.Authorization", "Bearer token"),
+				),
+			},
 			// Delete testing automatically occurs in TestCase
 		},
 	})
@@ -72,3 +94,69 @@ resource "warpgate_http_target" "test" {
 }
 `, name, url)
 }
+
+func testAccHttpTargetResourceHeadersConfig(name string, url string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_http_target" "test" {
+	name = "%s"
+	options = {
+		url = "%s"
+		headers = {
+			X-Forwarded-Proto = "https"
+		}
+		sensitive_headers = {
+			Authorization = "Bearer token"
+		}
+		tls = {
+			mode = "Preferred"
+			verify = true
+		}
+	}
+}
+`, name, url)
+}
+
+func TestParseHttpOptionsSensitiveHeaders(t *testing.T) {
+	var options warpgate.TargetOptions
+	err := options.FromTargetOptionsTargetHTTPOptions(warpgate.TargetOptionsTargetHTTPOptions{
+		Url:     "https://10.10.10.10",
+		Headers: &map[string]string{"X-Forwarded-Proto": "https", "Authorization": "Bearer token"},
+		Tls:     warpgate.Tls{Mode: warpgate.Preferred, Verify: true},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prior := &provider_models.TargetHttpOptions{
+		Headers:          types.MapValueMust(types.StringType, map[string]attr.Value{}),
+		SensitiveHeaders: types.MapValueMust(types.StringType, map[string]attr.Value{"authorization": types.StringValue("old")}),
+	}
+
+	result, err := ParseHttpOptions(options, prior)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Headers.Elements()) != 1 || result.Headers.Elements()["X-Forwarded-Proto"] == nil {
+		t.Errorf("unexpected headers %v", result.Headers)
+	}
+
+	if len(result.SensitiveHeaders.Elements()) != 1 || !result.SensitiveHeaders.Elements()["Authorization"].Equal(types.StringValue("Bearer token")) {
+		t.Errorf("unexpected sensitive headers %v", result.SensitiveHeaders)
+	}
+
+	// without a prior state (import and data source) all the headers are plain headers
+	result, err = ParseHttpOptions(options, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Headers.Elements()) != 2 || !result.SensitiveHeaders.IsNull() {
+		t.Errorf("unexpected headers %v and sensitive headers %v", result.Headers, result.SensitiveHeaders)
+	}
+}
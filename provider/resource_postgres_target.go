@@ -0,0 +1,415 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/provider/validators"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &postgresTargetResource{}
+var _ resource.ResourceWithImportState = &postgresTargetResource{}
+var _ resource.ResourceWithUpgradeState = &postgresTargetResource{}
+var _ resource.ResourceWithModifyPlan = &postgresTargetResource{}
+
+// postgresTargetStateMigrations upgrades the state of the older schema
+// versions of the postgres target resource, see NewStateUpgraders.
+var postgresTargetStateMigrations = []StateMigration{}
+
+func (r postgresTargetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version:             SchemaVersion(postgresTargetStateMigrations),
+		MarkdownDescription: "A PostgreSQL target.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Id of the postgres target in warpgate",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				}},
+			"allow_roles": schema.SetAttribute{
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "The names of the roles allowed to access the target. When set, the roles are assigned and removed by this resource, " +
+					"otherwise they are only read and can be assigned with [target_roles](target_roles.md). Do not use both on the same target: the plan only warns about it once the roles changed outside of the resource since its last apply.",
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"close_sessions_on_change": closeSessionsOnChangeAttribute("on the target when the target is deleted or its host or port changes"),
+			"name": schema.StringAttribute{
+				Computed: false,
+				Required: true,
+			},
+			"options": schema.SingleNestedAttribute{
+				Computed:   false,
+				Required:   true,
+				Attributes: postgresTargetOptionsAttributes(),
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// postgresTargetOptionsAttributes are the options of warpgate_postgres_target.
+func postgresTargetOptionsAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"host": schema.StringAttribute{
+			Required:   true,
+			Validators: []validator.String{validators.IsHostOrIp()},
+		},
+		"port": schema.Int64Attribute{
+			Required:   true,
+			Validators: []validator.Int64{int64validator.Between(1, 65535)},
+		},
+		"username": schema.StringAttribute{
+			Required: true,
+		},
+		"password": schema.StringAttribute{
+			Optional:  true,
+			Sensitive: true,
+		},
+		"tls": targetTlsAttribute(),
+	}
+}
+
+func NewPostgresTargetResource() resource.Resource {
+	return &postgresTargetResource{}
+}
+
+func (r *postgresTargetResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_postgres_target"
+}
+
+func (r *postgresTargetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*warpgateProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *warpgateProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !provider.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"Expected a configured provider but it wasn't. Please report this issue to the provider developers.",
+		)
+
+		return
+	}
+
+	r.provider = provider
+}
+
+type postgresTargetResource struct {
+	provider *warpgateProvider
+}
+
+func (r *postgresTargetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var resourceState provider_models.TargetPostgresResource
+
+	diags := req.Config.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := resourceState.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	target, err := r.provider.client.Targets().Create(ctx, warpgate.CreateTargetJSONRequestBody{
+		Name:    resourceState.Name.ValueString(),
+		Options: GeneratePostgresOptions(resourceState.Options),
+	})
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create postgres target",
+			fmt.Sprintf("Failed to create postgres target. (Error: %s)", err),
+		)
+		return
+	}
+
+	resourceState.Id = types.StringValue(target.Id.String())
+
+	resourceState.AllowRoles, diags = applyInlineRoles(ctx, r.provider.client, targetRoleReconciler(r.provider.client, target.Id), target.AllowRoles, resourceState.AllowRoles, resp.Private)
+	resp.Diagnostics.Append(diags...)
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *postgresTargetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var resourceState provider_models.TargetPostgresResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := resourceState.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse the id as uuid",
+			fmt.Sprintf("Failed to parse the id %s as uuid", resourceState.Id.String()),
+		)
+		return
+	}
+
+	target, err := r.provider.client.Targets().Get(ctx, id_as_uuid)
+
+	if errors.Is(err, warpgate.ErrNotFound) {
+		resp.Diagnostics.AddWarning(
+			"Failed to read postgres target, resource not found. Removing from the state.",
+			fmt.Sprintf("Failed to read postgres target with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read postgres target",
+			fmt.Sprintf("Failed to read postgres target with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		return
+	}
+
+	if err := checkTargetKind(target, warpgate.TargetKindPostgres); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read postgres target. Wrong kind",
+			fmt.Sprintf("Failed to read postgres target with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		return
+	}
+
+	postgresoptions, err := ParsePostgresOptions(target.Options)
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read postgres target. Wrong options",
+			fmt.Sprintf("Failed to read postgres target %v. Wrong options type. (Error: %v ", target, err),
+		)
+		return
+	}
+
+	resourceState.AllowRoles = ArrayOfStringToTerraformSet(target.AllowRoles)
+	resourceState.Name = types.StringValue(target.Name)
+	resourceState.Options = postgresoptions
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *postgresTargetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var resourceState provider_models.TargetPostgresResource
+	var resourcePlan provider_models.TargetPostgresResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	diags = req.Plan.Get(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := resourcePlan.Timeouts.Update(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	id_as_uuid, err := uuid.Parse(resourcePlan.Id.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse the id as uuid",
+			fmt.Sprintf("Failed to parse the id '%s' as uuid", resourcePlan.Id),
+		)
+		return
+	}
+
+	target, err := r.provider.client.Targets().Update(ctx, id_as_uuid, warpgate.UpdateTargetJSONRequestBody{
+		Name:    resourcePlan.Name.ValueString(),
+		Options: GeneratePostgresOptions(resourcePlan.Options),
+	})
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update postgres target",
+			fmt.Sprintf("Failed to update postgres target with id '%s'. (Error: %s)", resourcePlan.Id, err),
+		)
+		return
+	}
+
+	resourcePlan.AllowRoles, diags = applyInlineRoles(ctx, r.provider.client, targetRoleReconciler(r.provider.client, id_as_uuid), target.AllowRoles, resourcePlan.AllowRoles, resp.Private)
+	resp.Diagnostics.Append(diags...)
+
+	if resourcePlan.CloseSessionsOnChange.ValueBool() && postgresTargetAddress(resourceState.Options) != postgresTargetAddress(resourcePlan.Options) {
+		resp.Diagnostics.Append(closeSessionsOnChange(ctx, r.provider.client, fmt.Sprintf("postgres target '%s'", resourceState.Name.ValueString()), targetSessions(id_as_uuid))...)
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Updating postgres_target state: %v", resourcePlan))
+
+	diags = resp.State.Set(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *postgresTargetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var resourceState provider_models.TargetPostgresResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := resourceState.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse the id as uuid",
+			fmt.Sprintf("Failed to parse the id '%s' as uuid", resourceState.Id),
+		)
+		return
+	}
+
+	err = r.provider.client.Targets().Delete(ctx, id_as_uuid)
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete postgres target",
+			fmt.Sprintf("Failed to delete postgres target with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		return
+	}
+
+	if resourceState.CloseSessionsOnChange.ValueBool() {
+		resp.Diagnostics.Append(closeSessionsOnChange(ctx, r.provider.client, fmt.Sprintf("postgres target '%s'", resourceState.Name.ValueString()), targetSessions(id_as_uuid))...)
+	}
+}
+
+// ModifyPlan warns when the roles configured on the target changed outside of
+// the resource since the last apply, see warnInlineRolesChanged.
+func (r *postgresTargetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	warnInlineRolesChanged(ctx, req, resp, "allow_roles", "warpgate_target_roles")
+}
+
+func (r *postgresTargetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	id, err := ResolveTargetImportId(ctx, r.provider.client, req.ID, warpgate.TargetKindPostgres)
+	importStateResolvedId(ctx, id, err, "postgres target", resp)
+}
+
+func (r *postgresTargetResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return NewStateUpgraders(postgresTargetStateMigrations)
+}
+
+func GeneratePostgresOptions(options *provider_models.TargetPostgresOptions) warpgate.TargetOptions {
+	var targetOptions = warpgate.TargetOptions{}
+	targetOptions.FromTargetOptionsTargetPostgresOptions(
+		warpgate.TargetOptionsTargetPostgresOptions{
+			Host:     options.Host.ValueString(),
+			Port:     uint16(options.Port.ValueInt64()),
+			Username: options.Username.ValueString(),
+			Password: TerraformStringToNullableString(options.Password),
+			Tls:      generateTargetTls(options.Tls),
+		})
+
+	return targetOptions
+}
+
+func ParsePostgresOptions(options warpgate.TargetOptions) (*provider_models.TargetPostgresOptions, error) {
+	postgresoptions, err := options.AsTargetOptionsTargetPostgresOptions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &provider_models.TargetPostgresOptions{
+		Host:     types.StringValue(postgresoptions.Host),
+		Port:     types.Int64Value(int64(postgresoptions.Port)),
+		Username: types.StringValue(postgresoptions.Username),
+		Password: NullableStringToTerraformString(postgresoptions.Password),
+		Tls: &provider_models.TargetTls{
+			Mode:   types.StringValue(string(postgresoptions.Tls.Mode)),
+			Verify: types.BoolValue(postgresoptions.Tls.Verify),
+		},
+	}, nil
+}
+
+// postgresTargetAddress returns where warpgate connects for the target, the
+// sessions are closed when it changes.
+func postgresTargetAddress(options *provider_models.TargetPostgresOptions) string {
+	return fmt.Sprintf("%s:%d", options.Host.ValueString(), options.Port.ValueInt64())
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	provider_models "terraform-provider-warpgate/provider/models"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccPostgresTargetResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccPostgresTargetResourceConfig("one", "10.10.10.10"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_postgres_target.test", "name", "one"),
+					resource.TestCheckResourceAttr("warpgate_postgres_target.test", "options.host", "10.10.10.10"),
+					resource.TestCheckResourceAttr("warpgate_postgres_target.test", "options.port", "5432"),
+					resource.TestCheckResourceAttr("warpgate_postgres_target.test", "options.username", "postgres"),
+					resource.TestCheckResourceAttr("warpgate_postgres_target.test", "options.password", "A12345678"),
+					resource.TestCheckResourceAttr("warpgate_postgres_target.test", "options.tls.mode", "Required"),
+					resource.TestCheckResourceAttr("warpgate_postgres_target.test", "options.tls.verify", "true"),
+					resource.TestCheckResourceAttr("warpgate_postgres_target.test", "allow_roles.#", "0"),
+					testCheckFuncValidUUID("warpgate_postgres_target.test", "id"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "warpgate_postgres_target.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// ImportState by name testing
+			{
+				ResourceName:      "warpgate_postgres_target.test",
+				ImportState:       true,
+				ImportStateId:     "name:one",
+				ImportStateVerify: true,
+			},
+			// Update and Read testing
+			{
+				Config: testAccPostgresTargetResourceConfig("two", "20.20.20.20"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_postgres_target.test", "name", "two"),
+					resource.TestCheckResourceAttr("warpgate_postgres_target.test", "options.host", "20.20.20.20"),
+					testCheckFuncValidUUID("warpgate_postgres_target.test", "id"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccPostgresTargetResourceConfig(name string, host string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_postgres_target" "test" {
+	name = "%s"
+	options = {
+		host     = "%s"
+		port     = 5432
+		username = "postgres"
+		password = "A12345678"
+		tls = {
+			mode   = "Required"
+			verify = true
+		}
+	}
+}
+`, name, host)
+}
+
+func TestPostgresOptionsRoundTrip(t *testing.T) {
+	options := &provider_models.TargetPostgresOptions{
+		Host:     types.StringValue("10.0.0.3"),
+		Port:     types.Int64Value(5432),
+		Username: types.StringValue("app"),
+		Password: types.StringNull(),
+		Tls: &provider_models.TargetTls{
+			Mode:   types.StringValue("Preferred"),
+			Verify: types.BoolValue(false),
+		},
+	}
+
+	targetOptions := GeneratePostgresOptions(options)
+
+	if kind, _ := targetOptions.Discriminator(); kind != "Postgres" {
+		t.Fatalf("expected Postgres options, got %s", kind)
+	}
+
+	parsed, err := ParsePostgresOptions(targetOptions)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !parsed.Host.Equal(options.Host) || !parsed.Port.Equal(options.Port) || !parsed.Username.Equal(options.Username) ||
+		!parsed.Password.IsNull() || !parsed.Tls.Mode.Equal(options.Tls.Mode) || !parsed.Tls.Verify.Equal(options.Tls.Verify) {
+		t.Errorf("expected %+v, got %+v", options, parsed)
+	}
+}
@@ -2,16 +2,21 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	provider_models "terraform-provider-warpgate/provider/models"
 	"terraform-provider-warpgate/warpgate"
 
 	"github.com/google/uuid"
 
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 
 	// "github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -22,10 +27,16 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &roleResource{}
 var _ resource.ResourceWithImportState = &roleResource{}
+var _ resource.ResourceWithUpgradeState = &roleResource{}
 var _ resource.ResourceWithConfigure = &roleResource{}
 
+// roleStateMigrations upgrades the state of the older schema versions of the
+// role resource, see NewStateUpgraders.
+var roleStateMigrations = []StateMigration{}
+
 func (r roleResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: SchemaVersion(roleStateMigrations),
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:            true,
@@ -35,6 +46,36 @@ func (r roleResource) Schema(ctx context.Context, req resource.SchemaRequest, re
 				},
 			},
 			"name": schema.StringAttribute{Computed: false, Required: true},
+			"force_detach": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Delete the role even if it is still assigned to users or targets, removing it from them. " +
+					"By default the deletion fails listing the users and targets that still have the role. " +
+					"Must be applied before the deletion to take effect.",
+			},
+			"user_ids": schema.SetAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "The ids of the users that have the role.",
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"target_ids": schema.SetAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "The ids of the targets that allow the role.",
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -82,7 +123,7 @@ type roleResource struct {
 }
 
 func (r *roleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var resourceState provider_models.Role
+	var resourceState provider_models.RoleResource
 
 	diags := req.Config.Get(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -91,34 +132,38 @@ func (r *roleResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	response, err := r.provider.client.CreateRoleWithResponse(ctx, warpgate.CreateRoleJSONRequestBody{
+	createTimeout, diags := resourceState.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	role, err := r.provider.client.Roles().Create(ctx, warpgate.CreateRoleJSONRequestBody{
 		Name: resourceState.Name.ValueString(),
 	})
 
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to create role",
-			"Failed to create role",
-		)
-		return
-	}
-
-	if response.StatusCode() != 201 {
-		resp.Diagnostics.AddError(
-			"Failed to create role, wrong error code.",
-			fmt.Sprintf("Failed to create role. (Error code: %d)", response.StatusCode()),
+			fmt.Sprintf("Failed to create role. (Error: %s)", err),
 		)
 		return
 	}
 
-	resourceState.Id = types.StringValue(response.JSON201.Id.String())
+	resourceState.Id = types.StringValue(role.Id.String())
+	resourceState.UserIds = ArrayOfStringToTerraformSet([]string{})
+	resourceState.TargetIds = ArrayOfStringToTerraformSet([]string{})
 
 	diags = resp.State.Set(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *roleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var resourceState provider_models.Role
+	var resourceState provider_models.RoleResource
 
 	diags := req.State.Get(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -127,6 +172,16 @@ func (r *roleResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
+	readTimeout, diags := resourceState.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
 	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
@@ -137,41 +192,45 @@ func (r *roleResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	response, err := r.provider.client.GetRoleWithResponse(ctx, id_as_uuid)
+	role, err := r.provider.client.Roles().Get(ctx, id_as_uuid)
 
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to read role",
+	if errors.Is(err, warpgate.ErrNotFound) {
+		resp.Diagnostics.AddWarning(
+			"Failed to read role, resource not found. Removing from the state.",
 			fmt.Sprintf("Failed to read role with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
+		resp.State.RemoveResource(ctx)
 		return
 	}
 
-	if response.StatusCode() == 404 {
-		resp.Diagnostics.AddWarning(
-			"Failed to read role, resource not found. Removing from the state.",
-			fmt.Sprintf("Failed to read role. (Error code: %d)", response.StatusCode()),
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read role",
+			fmt.Sprintf("Failed to read role with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
-		resp.State.RemoveResource(ctx)
 		return
 	}
 
-	if response.StatusCode() != 200 {
+	users, targets, err := roleMembers(ctx, r.provider.client, role.Name)
+
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"Failed to read role, wrong error code.",
-			fmt.Sprintf("Failed to read role. (Error code: %d)", response.StatusCode()),
+			"Failed to read role",
+			fmt.Sprintf("Failed to read the users and targets of role with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
 		return
 	}
 
-	resourceState.Name = types.StringValue(response.JSON200.Name)
+	resourceState.Name = types.StringValue(role.Name)
+	resourceState.UserIds = ArrayOfStringToTerraformSet(userIds(users))
+	resourceState.TargetIds = ArrayOfStringToTerraformSet(targetIds(targets))
 
 	diags = resp.State.Set(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *roleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var resourceState provider_models.Role
+	var resourceState provider_models.RoleResource
 
 	diags := req.Plan.Get(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -180,6 +239,16 @@ func (r *roleResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
+	updateTimeout, diags := resourceState.Timeouts.Update(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
@@ -190,7 +259,7 @@ func (r *roleResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	response, err := r.provider.client.UpdateRoleWithResponse(ctx, id_as_uuid, warpgate.UpdateRoleJSONRequestBody{
+	role, err := r.provider.client.Roles().Update(ctx, id_as_uuid, warpgate.UpdateRoleJSONRequestBody{
 		Name: resourceState.Name.ValueString(),
 	})
 
@@ -202,33 +271,37 @@ func (r *roleResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	if response.StatusCode() != 200 {
-		resp.Diagnostics.AddError(
-			"Failed to update role, wrong error code.",
-			fmt.Sprintf("Failed to update role. (Error code: %d)", response.StatusCode()),
-		)
-		return
-	}
-
-	if response.JSON200.Id != id_as_uuid || response.JSON200.Name != resourceState.Name.ValueString() {
+	if role.Id != id_as_uuid || role.Name != resourceState.Name.ValueString() {
 		resp.Diagnostics.AddWarning(
 			"Created resource is different from requested.",
 			fmt.Sprintf("Created resource is different from requested. Requested: (%s, %s), Created: (%s, %s)",
-				response.JSON200.Id, response.JSON200.Name,
+				role.Id, role.Name,
 				resourceState.Id, resourceState.Name,
 			),
 		)
 		return
 	}
-	resourceState.Id = types.StringValue(response.JSON200.Id.String())
-	resourceState.Name = types.StringValue(response.JSON200.Name)
+	users, targets, err := roleMembers(ctx, r.provider.client, role.Name)
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read role",
+			fmt.Sprintf("Failed to read the users and targets of role with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		return
+	}
+
+	resourceState.Id = types.StringValue(role.Id.String())
+	resourceState.Name = types.StringValue(role.Name)
+	resourceState.UserIds = ArrayOfStringToTerraformSet(userIds(users))
+	resourceState.TargetIds = ArrayOfStringToTerraformSet(targetIds(targets))
 
 	diags = resp.State.Set(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *roleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var resourceState provider_models.Role
+	var resourceState provider_models.RoleResource
 
 	diags := req.State.Get(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -237,6 +310,16 @@ func (r *roleResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
+	deleteTimeout, diags := resourceState.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
@@ -247,25 +330,116 @@ func (r *roleResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	response, err := r.provider.client.DeleteRoleWithResponse(ctx, id_as_uuid)
+	users, targets, err := roleMembers(ctx, r.provider.client, resourceState.Name.ValueString())
 
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to delete role",
-			fmt.Sprintf("Failed to delete role with id '%s'. (Error: %s)", resourceState.Id, err),
+			fmt.Sprintf("Failed to read the users and targets of role with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
 		return
 	}
 
-	if response.StatusCode() != 204 {
+	if len(users) > 0 || len(targets) > 0 {
+		members := fmt.Sprintf("users [%s] and targets [%s]", strings.Join(usernames(users), ", "), strings.Join(targetNames(targets), ", "))
+
+		if !resourceState.ForceDetach.ValueBool() {
+			resp.Diagnostics.AddError(
+				"Failed to delete role, still in use",
+				fmt.Sprintf("The role with id '%s' is still assigned to %s. "+
+					"Remove the role from them, or set force_detach = true and apply before deleting the role.", resourceState.Id, members),
+			)
+			return
+		}
+
+		resp.Diagnostics.AddWarning(
+			"Role detached",
+			fmt.Sprintf("The role with id '%s' is removed from %s.", resourceState.Id, members),
+		)
+	}
+
+	err = r.provider.client.Roles().Delete(ctx, id_as_uuid)
+
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"Failed to delete role, wrong error code.",
-			fmt.Sprintf("Failed to delete role. (Error code: %d)", response.StatusCode()),
+			"Failed to delete role",
+			fmt.Sprintf("Failed to delete role with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
 		return
 	}
 }
 
 func (r *roleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	id, err := ResolveRoleImportId(ctx, r.provider.client, req.ID)
+	importStateResolvedId(ctx, id, err, "role", resp)
+}
+
+func (r *roleResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return NewStateUpgraders(roleStateMigrations)
+}
+
+// roleMembers returns the users and the targets that have the role, which
+// warpgate lists by name.
+func roleMembers(ctx context.Context, client *warpgate.WarpgateClient, roleName string) (users []warpgate.User, targets []warpgate.Target, err error) {
+	allUsers, err := client.Users().List(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, user := range allUsers {
+		if slices.Contains(user.Roles, roleName) {
+			users = append(users, user)
+		}
+	}
+
+	allTargets, err := client.Targets().List(ctx, "")
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, target := range allTargets {
+		if slices.Contains(target.AllowRoles, roleName) {
+			targets = append(targets, target)
+		}
+	}
+
+	return users, targets, nil
+}
+
+func userIds(users []warpgate.User) []string {
+	ids := []string{}
+	for _, user := range users {
+		ids = append(ids, user.Id.String())
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func usernames(users []warpgate.User) []string {
+	names := []string{}
+	for _, user := range users {
+		names = append(names, user.Username)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func targetIds(targets []warpgate.Target) []string {
+	ids := []string{}
+	for _, target := range targets {
+		ids = append(ids, target.Id.String())
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func targetNames(targets []warpgate.Target) []string {
+	names := []string{}
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	sort.Strings(names)
+	return names
 }
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -29,6 +30,13 @@ func TestAccRoleResource(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			// ImportState by name testing
+			{
+				ResourceName:      "warpgate_role.test",
+				ImportState:       true,
+				ImportStateId:     "name:one",
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: testAccRoleResourceConfig("two"),
@@ -53,3 +61,107 @@ resource "warpgate_role" "test" {
 }
 `, name)
 }
+
+func TestAccRoleResourceInUse(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRoleInUseResourceConfig(true, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_role.test", "user_ids.#", "1"),
+					resource.TestCheckResourceAttrPair("warpgate_role.test", "user_ids.0", "warpgate_user.test", "id"),
+					resource.TestCheckResourceAttr("warpgate_role.test", "target_ids.#", "0"),
+				),
+			},
+			// the role is still assigned to the user
+			{
+				Config:      testAccRoleInUseResourceConfig(false, false),
+				ExpectError: regexp.MustCompile(`still assigned to users \[in-use\]`),
+			},
+			{
+				Config: testAccRoleInUseResourceConfig(true, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_role.test", "force_detach", "true"),
+				),
+			},
+			// the role is removed from the user, which then plans to add it again
+			{
+				Config:             testAccRoleInUseResourceConfig(false, true),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// testAccRoleInUseResourceConfig keeps the role assigned to the user by name
+// when the role is removed from the configuration, so that the role is
+// deleted while still in use.
+func testAccRoleInUseResourceConfig(withRole bool, forceDetach bool) string {
+	role := ""
+	roles := `["in-use"]`
+
+	if withRole {
+		roles = `[warpgate_role.test.name]`
+		role = fmt.Sprintf(`
+resource "warpgate_role" "test" {
+	name         = "in-use"
+	force_detach = %t
+}
+`, forceDetach)
+	}
+
+	return fmt.Sprintf(`
+provider "warpgate" {}
+%s
+resource "warpgate_user" "test" {
+	username = "in-use"
+	roles    = %s
+	credentials = [
+		{
+			kind = "PublicKey"
+			public_key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAUHVLLcZmjfhjMTZk8J5q/RZn8+htJakhvTikkAt+5f user-a@example.com"
+		}
+	]
+}
+`, role, roles)
+}
+
+func TestAccRoleResourceTimeouts(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "warpgate" {
+	request_timeout = "30s"
+}
+
+resource "warpgate_role" "test" {
+	name = "timeouts"
+
+	timeouts {
+		create = "2m"
+		read   = "1m"
+	}
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_role.test", "name", "timeouts"),
+					resource.TestCheckResourceAttr("warpgate_role.test", "timeouts.create", "2m"),
+					resource.TestCheckResourceAttr("warpgate_role.test", "timeouts.read", "1m"),
+				),
+			},
+			// the timeouts are not part of the imported state
+			{
+				ResourceName:            "warpgate_role.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"timeouts"},
+			},
+		},
+	})
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	provider_models "terraform-provider-warpgate/provider/models"
 	"terraform-provider-warpgate/provider/validators"
@@ -9,12 +10,14 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -24,9 +27,16 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &sshTargetResource{}
 var _ resource.ResourceWithImportState = &sshTargetResource{}
+var _ resource.ResourceWithUpgradeState = &sshTargetResource{}
+var _ resource.ResourceWithModifyPlan = &sshTargetResource{}
+
+// sshTargetStateMigrations upgrades the state of the older schema versions of the
+// ssh target resource, see NewStateUpgraders.
+var sshTargetStateMigrations = []StateMigration{}
 
 func (r sshTargetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: SchemaVersion(sshTargetStateMigrations),
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:            true,
@@ -35,45 +45,84 @@ func (r sshTargetResource) Schema(ctx context.Context, req resource.SchemaReques
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"allow_roles": schema.SetAttribute{Computed: true, ElementType: types.StringType},
+			"allow_roles": schema.SetAttribute{
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "The names of the roles allowed to access the target. When set, the roles are assigned and removed by this resource, " +
+					"otherwise they are only read and can be assigned with [target_roles](target_roles.md). Do not use both on the same target: the plan only warns about it once the roles changed outside of the resource since its last apply.",
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"close_sessions_on_change": closeSessionsOnChangeAttribute("on the target when the target is deleted or its host or port change"),
 			"name": schema.StringAttribute{
 				Computed: false,
 				Required: true,
 			},
 			"options": schema.SingleNestedAttribute{
-				Computed: false,
-				Required: true,
-				Attributes: map[string]schema.Attribute{
-					"host": schema.StringAttribute{
-						Computed:   false,
-						Required:   true,
-						Validators: []validator.String{validators.IsDomain()},
-					},
-					"port": schema.Int64Attribute{
-						Computed:   false,
-						Required:   true,
-						Validators: []validator.Int64{int64validator.Between(1, 65535)},
-					},
-					"username": schema.StringAttribute{
-						Computed: false,
-						Required: true,
-					},
-					"password": schema.StringAttribute{
-						Computed:  false,
-						Optional:  true,
-						Sensitive: true,
-					},
-					"auth_kind": schema.StringAttribute{
-						Computed: false,
-						Required: true,
-						Validators: []validator.String{
-							stringvalidator.OneOf(
-								string(warpgate.Password),
-								string(warpgate.PublicKey),
-							),
-						},
-					},
-				},
+				Computed:   false,
+				Required:   true,
+				Attributes: sshTargetOptionsAttributes(),
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// sshTargetOptionsAttributes are the options of warpgate_ssh_target and the
+// ssh options of warpgate_target.
+func sshTargetOptionsAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"host": schema.StringAttribute{
+			Computed:   false,
+			Required:   true,
+			Validators: []validator.String{validators.IsHostOrIp()},
+		},
+		"port": schema.Int64Attribute{
+			Computed:   false,
+			Required:   true,
+			Validators: []validator.Int64{int64validator.Between(1, 65535)},
+		},
+		"username": schema.StringAttribute{
+			Computed: false,
+			Required: true,
+		},
+		"password": schema.StringAttribute{
+			Computed:  false,
+			Optional:  true,
+			Sensitive: true,
+		},
+		"password_wo": schema.StringAttribute{
+			Optional:  true,
+			Sensitive: true,
+			WriteOnly: true,
+			Description: "Write-only alternative to `password` (requires Terraform 1.11), never stored in the state or in the plan. " +
+				"Change `password_wo_version` to update the password.",
+			Validators: []validator.String{
+				stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("password")),
+				stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("password_wo_version")),
+			},
+		},
+		"password_wo_version": schema.Int64Attribute{
+			Optional:    true,
+			Description: "Triggers the update of `password_wo`, which terraform cannot compare with the previous value.",
+		},
+		"auth_kind": schema.StringAttribute{
+			Computed: false,
+			Required: true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(
+					string(warpgate.Password),
+					string(warpgate.PublicKey),
+				),
 			},
 		},
 	}
@@ -121,7 +170,7 @@ type sshTargetResource struct {
 }
 
 func (r *sshTargetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var resourceState provider_models.TargetSsh
+	var resourceState provider_models.TargetSshResource
 
 	diags := req.Config.Get(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -130,18 +179,30 @@ func (r *sshTargetResource) Create(ctx context.Context, req resource.CreateReque
 		return
 	}
 
+	createTimeout, diags := resourceState.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	password := sshTargetPassword(resourceState.Options)
+
 	var targetOptions = &warpgate.TargetOptions{}
 	targetOptions.FromTargetOptionsTargetSSHOptions(
 		warpgate.TargetOptionsTargetSSHOptions{
-			Kind:     "Ssh",
+			Kind:     warpgate.TargetKindSsh,
 			Host:     resourceState.Options.Host.ValueString(),
 			Port:     uint16(resourceState.Options.Port.ValueInt64()),
 			Username: resourceState.Options.Username.ValueString(),
-			Auth:     GenerateSshAuth(resourceState),
+			Auth:     GenerateSshAuth(resourceState.Options.TargetSSHOptions, password),
 		},
 	)
 
-	response, err := r.provider.client.CreateTargetWithResponse(ctx, warpgate.CreateTargetJSONRequestBody{
+	target, err := r.provider.client.Targets().Create(ctx, warpgate.CreateTargetJSONRequestBody{
 		Name:    resourceState.Name.ValueString(),
 		Options: *targetOptions,
 	})
@@ -149,28 +210,24 @@ func (r *sshTargetResource) Create(ctx context.Context, req resource.CreateReque
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to create ssh target",
-			"Failed to create ssh target",
+			fmt.Sprintf("Failed to create ssh target. (Error: %s)", err),
 		)
 		return
 	}
 
-	if response.StatusCode() != 201 {
-		resp.Diagnostics.AddError(
-			"Failed to create ssh target, wrong error code.",
-			fmt.Sprintf("Failed to create ssh target. (Error code: %d)", response.StatusCode()),
-		)
-		return
-	}
+	resourceState.Id = types.StringValue(target.Id.String())
+
+	resourceState.AllowRoles, diags = applyInlineRoles(ctx, r.provider.client, targetRoleReconciler(r.provider.client, target.Id), target.AllowRoles, resourceState.AllowRoles, resp.Private)
+	resp.Diagnostics.Append(diags...)
 
-	resourceState.Id = types.StringValue(response.JSON201.Id.String())
-	resourceState.AllowRoles = ArrayOfStringToTerraformSet(response.JSON201.AllowRoles)
+	resourceState.Options.PasswordWo = types.StringNull()
 
 	diags = resp.State.Set(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (r *sshTargetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var resourceState provider_models.TargetSsh
+	var resourceState provider_models.TargetSshResource
 
 	diags := req.State.Get(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -179,6 +236,16 @@ func (r *sshTargetResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
+	readTimeout, diags := resourceState.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
 	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
@@ -189,39 +256,39 @@ func (r *sshTargetResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	response, err := r.provider.client.GetTargetWithResponse(ctx, id_as_uuid)
+	target, err := r.provider.client.Targets().Get(ctx, id_as_uuid)
 
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to read ssh target",
+	if errors.Is(err, warpgate.ErrNotFound) {
+		resp.Diagnostics.AddWarning(
+			"Failed to read ssh target, resource not found. Removing from the state.",
 			fmt.Sprintf("Failed to read ssh target with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
+		resp.State.RemoveResource(ctx)
 		return
 	}
 
-	if response.StatusCode() == 404 {
-		resp.Diagnostics.AddWarning(
-			"Failed to read ssh target, resource not found. Removing from the state.",
-			fmt.Sprintf("Failed to read ssh target. (Error code: %d)", response.StatusCode()),
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read ssh target",
+			fmt.Sprintf("Failed to read ssh target with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
-		resp.State.RemoveResource(ctx)
 		return
 	}
 
-	if response.StatusCode() != 200 {
+	if err := checkTargetKind(target, warpgate.TargetKindSsh); err != nil {
 		resp.Diagnostics.AddError(
-			"Failed to read ssh target, wrong error code.",
-			fmt.Sprintf("Failed to read ssh target. (Error code: %d)", response.StatusCode()),
+			"Failed to read ssh target. Wrong kind",
+			fmt.Sprintf("Failed to read ssh target with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
 		return
 	}
 
-	sshoptions, err := ParseSshOptions(response.JSON200.Options)
+	sshoptions, err := ParseSshOptions(target.Options)
 
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to read ssh target. Wrong options",
-			fmt.Sprintf("Failed to read ssh target %v. Wrong options type. (Error: %v ", response.JSON200, err),
+			fmt.Sprintf("Failed to read ssh target %v. Wrong options type. (Error: %v ", target, err),
 		)
 		return
 	}
@@ -234,14 +301,29 @@ func (r *sshTargetResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	resourceState.AllowRoles = ArrayOfStringToTerraformSet(response.JSON200.AllowRoles)
-	resourceState.Name = types.StringValue(response.JSON200.Name)
-	resourceState.Options = &provider_models.TargetSSHOptions{
-		Host:     sshoptions.Host,
-		Port:     sshoptions.Port,
-		Username: sshoptions.Username,
-		AuthKind: sshoptions.AuthKind,
-		Password: sshoptions.Password,
+	resourceState.AllowRoles = ArrayOfStringToTerraformSet(target.AllowRoles)
+	resourceState.Name = types.StringValue(target.Name)
+	passwordWoVersion := types.Int64Null()
+
+	if resourceState.Options != nil {
+		passwordWoVersion = resourceState.Options.PasswordWoVersion
+	}
+
+	// the password set with password_wo must not be read back into the state
+	if !passwordWoVersion.IsNull() {
+		sshoptions.Password = types.StringNull()
+	}
+
+	resourceState.Options = &provider_models.TargetSSHResourceOptions{
+		TargetSSHOptions: provider_models.TargetSSHOptions{
+			Host:     sshoptions.Host,
+			Port:     sshoptions.Port,
+			Username: sshoptions.Username,
+			AuthKind: sshoptions.AuthKind,
+			Password: sshoptions.Password,
+		},
+		PasswordWo:        types.StringNull(),
+		PasswordWoVersion: passwordWoVersion,
 	}
 
 	diags = resp.State.Set(ctx, &resourceState)
@@ -249,15 +331,29 @@ func (r *sshTargetResource) Read(ctx context.Context, req resource.ReadRequest,
 }
 
 func (r *sshTargetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var resourcePlan provider_models.TargetSsh
+	var resourceState provider_models.TargetSshResource
+	var resourcePlan provider_models.TargetSshResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
 
-	diags := req.Plan.Get(ctx, &resourcePlan)
+	diags = req.Plan.Get(ctx, &resourcePlan)
 	resp.Diagnostics.Append(diags...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	updateTimeout, diags := resourcePlan.Timeouts.Update(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	id_as_uuid, err := uuid.Parse(resourcePlan.Id.ValueString())
 
 	if err != nil {
@@ -268,18 +364,32 @@ func (r *sshTargetResource) Update(ctx context.Context, req resource.UpdateReque
 		return
 	}
 
+	// write-only values are only in the configuration
+	var passwordWo types.String
+
+	diags = req.Config.GetAttribute(ctx, path.Root("options").AtName("password_wo"), &passwordWo)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resourcePlan.Options.PasswordWo = passwordWo
+	password := sshTargetPassword(resourcePlan.Options)
+	resourcePlan.Options.PasswordWo = types.StringNull()
+
 	var targetOptions = &warpgate.TargetOptions{}
 	targetOptions.FromTargetOptionsTargetSSHOptions(
 		warpgate.TargetOptionsTargetSSHOptions{
-			Kind:     "Ssh",
+			Kind:     warpgate.TargetKindSsh,
 			Host:     resourcePlan.Options.Host.ValueString(),
 			Port:     uint16(resourcePlan.Options.Port.ValueInt64()),
 			Username: resourcePlan.Options.Username.ValueString(),
-			Auth:     GenerateSshAuth(resourcePlan),
+			Auth:     GenerateSshAuth(resourcePlan.Options.TargetSSHOptions, password),
 		},
 	)
 
-	response, err := r.provider.client.UpdateTargetWithResponse(ctx, id_as_uuid, warpgate.UpdateTargetJSONRequestBody{
+	target, err := r.provider.client.Targets().Update(ctx, id_as_uuid, warpgate.UpdateTargetJSONRequestBody{
 		Name:    resourcePlan.Name.ValueString(),
 		Options: *targetOptions,
 	})
@@ -292,25 +402,23 @@ func (r *sshTargetResource) Update(ctx context.Context, req resource.UpdateReque
 		return
 	}
 
-	if response.StatusCode() != 200 {
-		resp.Diagnostics.AddError(
-			"Failed to update ssh target, wrong error code.",
-			fmt.Sprintf("Failed to update ssh target. (Error code: %d)", response.StatusCode()),
-		)
-		return
-	}
-
 	// probably unnecessary check
-	if response.JSON200.Id != id_as_uuid || response.JSON200.Name != resourcePlan.Name.ValueString() {
+	if target.Id != id_as_uuid || target.Name != resourcePlan.Name.ValueString() {
 		resp.Diagnostics.AddWarning(
 			"Created resource is different from requested.",
 			fmt.Sprintf("Created resource is different from requested. Requested: (%s, %s), Created: (%s, %s)",
-				response.JSON200.Id, response.JSON200.Name,
+				target.Id, target.Name,
 				resourcePlan.Id, resourcePlan.Name,
 			),
 		)
 	}
-	resourcePlan.AllowRoles = ArrayOfStringToTerraformSet(response.JSON200.AllowRoles)
+
+	resourcePlan.AllowRoles, diags = applyInlineRoles(ctx, r.provider.client, targetRoleReconciler(r.provider.client, id_as_uuid), target.AllowRoles, resourcePlan.AllowRoles, resp.Private)
+	resp.Diagnostics.Append(diags...)
+
+	if resourcePlan.CloseSessionsOnChange.ValueBool() && (!resourceState.Options.Host.Equal(resourcePlan.Options.Host) || !resourceState.Options.Port.Equal(resourcePlan.Options.Port)) {
+		resp.Diagnostics.Append(closeSessionsOnChange(ctx, r.provider.client, fmt.Sprintf("ssh target '%s'", resourceState.Name.ValueString()), targetSessions(id_as_uuid))...)
+	}
 
 	tflog.Debug(ctx, fmt.Sprintf("Updating ssh_target state: %v", resourcePlan))
 
@@ -319,7 +427,7 @@ func (r *sshTargetResource) Update(ctx context.Context, req resource.UpdateReque
 }
 
 func (r *sshTargetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var resourceState provider_models.TargetSsh
+	var resourceState provider_models.TargetSshResource
 
 	diags := req.State.Get(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -328,6 +436,16 @@ func (r *sshTargetResource) Delete(ctx context.Context, req resource.DeleteReque
 		return
 	}
 
+	deleteTimeout, diags := resourceState.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
@@ -338,7 +456,7 @@ func (r *sshTargetResource) Delete(ctx context.Context, req resource.DeleteReque
 		return
 	}
 
-	response, err := r.provider.client.DeleteTargetWithResponse(ctx, id_as_uuid)
+	err = r.provider.client.Targets().Delete(ctx, id_as_uuid)
 
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -348,35 +466,52 @@ func (r *sshTargetResource) Delete(ctx context.Context, req resource.DeleteReque
 		return
 	}
 
-	if response.StatusCode() != 204 {
-		resp.Diagnostics.AddError(
-			"Failed to delete ssh target, wrong error code.",
-			fmt.Sprintf("Failed to delete ssh target. (Error code: %d)", response.StatusCode()),
-		)
-		return
+	if resourceState.CloseSessionsOnChange.ValueBool() {
+		resp.Diagnostics.Append(closeSessionsOnChange(ctx, r.provider.client, fmt.Sprintf("ssh target '%s'", resourceState.Name.ValueString()), targetSessions(id_as_uuid))...)
 	}
 }
 
+// ModifyPlan warns when the roles configured on the target changed outside of
+// the resource since the last apply, see warnInlineRolesChanged.
+func (r *sshTargetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	warnInlineRolesChanged(ctx, req, resp, "allow_roles", "warpgate_target_roles")
+}
+
 func (r *sshTargetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	id, err := ResolveTargetImportId(ctx, r.provider.client, req.ID, warpgate.TargetKindSsh)
+	importStateResolvedId(ctx, id, err, "ssh target", resp)
+}
+
+func (r *sshTargetResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return NewStateUpgraders(sshTargetStateMigrations)
+}
+
+// sshTargetPassword returns the password to send to warpgate, from password
+// or from the write-only password_wo.
+func sshTargetPassword(options *provider_models.TargetSSHResourceOptions) string {
+	if !options.PasswordWo.IsNull() {
+		return options.PasswordWo.ValueString()
+	}
+
+	return options.Password.ValueString()
 }
 
-func GenerateSshAuth(resourceState provider_models.TargetSsh) warpgate.SSHTargetAuth {
+func GenerateSshAuth(sshOptions provider_models.TargetSSHOptions, password string) warpgate.SSHTargetAuth {
 	// var auth warpgate.SSHTargetAuth
 
 	var options = &warpgate.SSHTargetAuth{}
 
-	if resourceState.Options.AuthKind.ValueString() == string(warpgate.Password) {
+	if sshOptions.AuthKind.ValueString() == string(warpgate.Password) {
 		options.FromSSHTargetAuthSshTargetPasswordAuth(
 			warpgate.SSHTargetAuthSshTargetPasswordAuth{
-				Password: resourceState.Options.Password.ValueString(),
-				Kind:     resourceState.Options.AuthKind.ValueString(),
+				Password: password,
+				Kind:     sshOptions.AuthKind.ValueString(),
 			},
 		)
-	} else if resourceState.Options.AuthKind.ValueString() == string(warpgate.PublicKey) {
+	} else if sshOptions.AuthKind.ValueString() == string(warpgate.PublicKey) {
 		options.FromSSHTargetAuthSshTargetPublicKeyAuth(
 			warpgate.SSHTargetAuthSshTargetPublicKeyAuth{
-				Kind: resourceState.Options.AuthKind.ValueString(),
+				Kind: sshOptions.AuthKind.ValueString(),
 			},
 		)
 	}
@@ -39,6 +39,13 @@ func TestAccSshTargetPublicKeyResource(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			// ImportState by name testing
+			{
+				ResourceName:      "warpgate_ssh_target.test",
+				ImportState:       true,
+				ImportStateId:     "name:one",
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: testAccSshTargetPublicKeyResourceConfig("two", "20.20.20.20"),
@@ -176,6 +183,36 @@ func TestAccSshTargetMixedAuthResource(t *testing.T) {
 	})
 }
 
+func TestAccSshTargetPasswordWriteOnlyResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing, the password is not in the state
+			{
+				Config: testAccSshTargetPasswordWriteOnlyResourceConfig("one", "A12345678", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_ssh_target.test", "options.auth_kind", "Password"),
+					resource.TestCheckResourceAttr("warpgate_ssh_target.test", "options.password_wo_version", "1"),
+					resource.TestCheckNoResourceAttr("warpgate_ssh_target.test", "options.password"),
+					resource.TestCheckNoResourceAttr("warpgate_ssh_target.test", "options.password_wo"),
+					testCheckFuncValidUUID("warpgate_ssh_target.test", "id"),
+				),
+			},
+			// Update of the password
+			{
+				Config: testAccSshTargetPasswordWriteOnlyResourceConfig("one", "B12345678", 2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_ssh_target.test", "options.password_wo_version", "2"),
+					resource.TestCheckNoResourceAttr("warpgate_ssh_target.test", "options.password"),
+					resource.TestCheckNoResourceAttr("warpgate_ssh_target.test", "options.password_wo"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
 func testAccSshTargetPublicKeyResourceConfig(name string, host string) string {
 	return fmt.Sprintf(`
 provider "warpgate" {}
@@ -208,3 +245,21 @@ resource "warpgate_ssh_target" "test" {
 }
 `, name, host)
 }
+
+func testAccSshTargetPasswordWriteOnlyResourceConfig(name string, password string, version int) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_ssh_target" "test" {
+	name = "%s"
+	options = {
+		host = "10.10.10.10"
+		port = 22
+		username = "root"
+		auth_kind = "Password"
+		password_wo = "%s"
+		password_wo_version = %d
+	}
+}
+`, name, password, version)
+}
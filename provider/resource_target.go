@@ -0,0 +1,823 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/provider/validators"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &targetResource{}
+var _ resource.ResourceWithImportState = &targetResource{}
+var _ resource.ResourceWithUpgradeState = &targetResource{}
+var _ resource.ResourceWithConfigValidators = &targetResource{}
+var _ resource.ResourceWithModifyPlan = &targetResource{}
+
+// targetStateMigrations upgrades the state of the older schema versions of the
+// target resource, see NewStateUpgraders.
+var targetStateMigrations = []StateMigration{}
+
+// targetKindAttributes maps the kind of a target to the attribute holding its
+// options.
+var targetKindAttributes = map[string]string{
+	warpgate.TargetKindSsh:      "ssh",
+	warpgate.TargetKindHttp:     "http",
+	warpgate.TargetKindMySql:    "mysql",
+	warpgate.TargetKindPostgres: "postgres",
+	warpgate.TargetKindWebAdmin: "web_admin",
+}
+
+func (r targetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version:             SchemaVersion(targetStateMigrations),
+		MarkdownDescription: "A target of any kind. Exactly one of `ssh`, `http`, `mysql`, `postgres` and `web_admin` must be set, changing the kind replaces the target.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Id of the target in warpgate",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"allow_roles": schema.SetAttribute{
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: "The names of the roles allowed to access the target. When set, the roles are assigned and removed by this resource, " +
+					"otherwise they are only read and can be assigned with [target_roles](target_roles.md). Do not use both on the same target: the plan only warns about it once the roles changed outside of the resource since its last apply.",
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"close_sessions_on_change": closeSessionsOnChangeAttribute("on the target when the target is deleted or the address of its options changes"),
+			"name": schema.StringAttribute{
+				Computed: false,
+				Required: true,
+			},
+			"kind": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Kind of the target in warpgate (`Ssh`, `Http`, `MySql`, `Postgres` or `WebAdmin`), set from the options.",
+			},
+			"ssh": schema.SingleNestedAttribute{
+				Optional:   true,
+				Attributes: sshTargetOptionsAttributes(),
+			},
+			"http": schema.SingleNestedAttribute{
+				Optional:   true,
+				Attributes: httpTargetOptionsAttributes(),
+			},
+			"mysql": schema.SingleNestedAttribute{
+				Optional:   true,
+				Attributes: databaseTargetOptionsAttributes(),
+			},
+			"postgres": schema.SingleNestedAttribute{
+				Optional:   true,
+				Attributes: databaseTargetOptionsAttributes(),
+			},
+			"web_admin": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "The web admin target built into warpgate. It cannot be created, only imported, and deleting the resource only removes it from the state.",
+				Attributes:          map[string]schema.Attribute{},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// databaseTargetOptionsAttributes are the mysql and postgres options of
+// warpgate_target.
+func databaseTargetOptionsAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"host": schema.StringAttribute{
+			Required:   true,
+			Validators: []validator.String{validators.IsHostOrIp()},
+		},
+		"port": schema.Int64Attribute{
+			Required:   true,
+			Validators: []validator.Int64{int64validator.Between(1, 65535)},
+		},
+		"username": schema.StringAttribute{
+			Required: true,
+		},
+		"password": schema.StringAttribute{
+			Optional:  true,
+			Sensitive: true,
+		},
+		"password_wo": schema.StringAttribute{
+			Optional:  true,
+			Sensitive: true,
+			WriteOnly: true,
+			Description: "Write-only alternative to `password` (requires Terraform 1.11), never stored in the state or in the plan. " +
+				"Change `password_wo_version` to update the password.",
+			Validators: []validator.String{
+				stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("password")),
+				stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("password_wo_version")),
+			},
+		},
+		"password_wo_version": schema.Int64Attribute{
+			Optional:    true,
+			Description: "Triggers the update of `password_wo`, which terraform cannot compare with the previous value.",
+		},
+		"tls": targetTlsAttribute(),
+	}
+}
+
+func NewTargetResource() resource.Resource {
+	return &targetResource{}
+}
+
+func (r *targetResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_target"
+}
+
+func (r *targetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*warpgateProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *warpgateProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !provider.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"Expected a configured provider but it wasn't. Please report this issue to the provider developers.",
+		)
+
+		return
+	}
+
+	r.provider = provider
+}
+
+type targetResource struct {
+	provider *warpgateProvider
+}
+
+func (r *targetResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.ExactlyOneOf(
+			path.MatchRoot("ssh"),
+			path.MatchRoot("http"),
+			path.MatchRoot("mysql"),
+			path.MatchRoot("postgres"),
+			path.MatchRoot("web_admin"),
+		),
+	}
+}
+
+// ModifyPlan sets the kind from the options and replaces the target when the
+// kind changes, since warpgate cannot change the kind of a target. It also
+// warns when the allow_roles changed outside of the resource since the last
+// apply, see warnInlineRolesChanged.
+func (r *targetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	warnInlineRolesChanged(ctx, req, resp, "allow_roles", "warpgate_target_roles")
+
+	kind, diags := plannedTargetKind(ctx, req.Plan)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() || kind.IsUnknown() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("kind"), kind)...)
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var priorKind types.String
+
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("kind"), &priorKind)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !priorKind.IsNull() && !priorKind.Equal(kind) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("kind"))
+	}
+}
+
+// plannedTargetKind returns the kind of the options set in the plan, unknown
+// if they are not known yet.
+func plannedTargetKind(ctx context.Context, plan tfsdk.Plan) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	for kind, attribute := range targetKindAttributes {
+		var options types.Object
+
+		diags.Append(plan.GetAttribute(ctx, path.Root(attribute), &options)...)
+
+		if diags.HasError() {
+			return types.StringUnknown(), diags
+		}
+
+		if options.IsUnknown() {
+			return types.StringUnknown(), diags
+		}
+
+		if !options.IsNull() {
+			return types.StringValue(kind), diags
+		}
+	}
+
+	return types.StringUnknown(), diags
+}
+
+func (r *targetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var resourceState provider_models.TargetResource
+
+	diags := req.Config.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := resourceState.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	if resourceState.WebAdmin != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create target",
+			"The web admin target is built into warpgate and cannot be created, import it instead.",
+		)
+		return
+	}
+
+	targetOptions, diags := GenerateTargetOptions(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	target, err := r.provider.client.Targets().Create(ctx, warpgate.CreateTargetJSONRequestBody{
+		Name:    resourceState.Name.ValueString(),
+		Options: targetOptions,
+	})
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create target",
+			fmt.Sprintf("Failed to create target. (Error: %s)", err),
+		)
+		return
+	}
+
+	kind, _ := target.Options.Discriminator()
+
+	resourceState.Id = types.StringValue(target.Id.String())
+
+	resourceState.AllowRoles, diags = applyInlineRoles(ctx, r.provider.client, targetRoleReconciler(r.provider.client, target.Id), target.AllowRoles, resourceState.AllowRoles, resp.Private)
+	resp.Diagnostics.Append(diags...)
+
+	resourceState.Kind = types.StringValue(kind)
+	clearTargetWriteOnlyAttributes(&resourceState)
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *targetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var resourceState provider_models.TargetResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := resourceState.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse the id as uuid",
+			fmt.Sprintf("Failed to parse the id %s as uuid", resourceState.Id.String()),
+		)
+		return
+	}
+
+	target, err := r.provider.client.Targets().Get(ctx, id_as_uuid)
+
+	if errors.Is(err, warpgate.ErrNotFound) {
+		resp.Diagnostics.AddWarning(
+			"Failed to read target, resource not found. Removing from the state.",
+			fmt.Sprintf("Failed to read target with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read target",
+			fmt.Sprintf("Failed to read target with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		return
+	}
+
+	err = ParseTargetOptions(target.Options, &resourceState)
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read target. Wrong options",
+			fmt.Sprintf("Failed to read the options of target with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		return
+	}
+
+	resourceState.AllowRoles = ArrayOfStringToTerraformSet(target.AllowRoles)
+	resourceState.Name = types.StringValue(target.Name)
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *targetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var resourceState provider_models.TargetResource
+	var resourcePlan provider_models.TargetResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	diags = req.Plan.Get(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := resourcePlan.Timeouts.Update(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	id_as_uuid, err := uuid.Parse(resourcePlan.Id.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse the id as uuid",
+			fmt.Sprintf("Failed to parse the id '%s' as uuid", resourcePlan.Id),
+		)
+		return
+	}
+
+	// write-only values are only in the configuration
+	diags = getTargetWriteOnlyAttributes(ctx, req.Config, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	targetOptions, diags := GenerateTargetOptions(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+	clearTargetWriteOnlyAttributes(&resourcePlan)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	target, err := r.provider.client.Targets().Update(ctx, id_as_uuid, warpgate.UpdateTargetJSONRequestBody{
+		Name:    resourcePlan.Name.ValueString(),
+		Options: targetOptions,
+	})
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update target",
+			fmt.Sprintf("Failed to update target with id '%s'. (Error: %s)", resourcePlan.Id, err),
+		)
+		return
+	}
+
+	kind, _ := target.Options.Discriminator()
+
+	resourcePlan.AllowRoles, diags = applyInlineRoles(ctx, r.provider.client, targetRoleReconciler(r.provider.client, id_as_uuid), target.AllowRoles, resourcePlan.AllowRoles, resp.Private)
+	resp.Diagnostics.Append(diags...)
+
+	if resourcePlan.CloseSessionsOnChange.ValueBool() && targetResourceAddress(resourceState) != targetResourceAddress(resourcePlan) {
+		resp.Diagnostics.Append(closeSessionsOnChange(ctx, r.provider.client, fmt.Sprintf("target '%s'", resourceState.Name.ValueString()), targetSessions(id_as_uuid))...)
+	}
+
+	resourcePlan.Kind = types.StringValue(kind)
+
+	tflog.Debug(ctx, fmt.Sprintf("Updating target state: %v", resourcePlan))
+
+	diags = resp.State.Set(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *targetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var resourceState provider_models.TargetResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := resourceState.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	if resourceState.Kind.ValueString() == warpgate.TargetKindWebAdmin {
+		resp.Diagnostics.AddWarning(
+			"The web admin target cannot be deleted",
+			fmt.Sprintf("The target with id '%s' is built into warpgate, it is only removed from the state.", resourceState.Id),
+		)
+		return
+	}
+
+	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse the id as uuid",
+			fmt.Sprintf("Failed to parse the id '%s' as uuid", resourceState.Id),
+		)
+		return
+	}
+
+	err = r.provider.client.Targets().Delete(ctx, id_as_uuid)
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete target",
+			fmt.Sprintf("Failed to delete target with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		return
+	}
+
+	if resourceState.CloseSessionsOnChange.ValueBool() {
+		resp.Diagnostics.Append(closeSessionsOnChange(ctx, r.provider.client, fmt.Sprintf("target '%s'", resourceState.Name.ValueString()), targetSessions(id_as_uuid))...)
+	}
+}
+
+func (r *targetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	id, err := ResolveTargetImportId(ctx, r.provider.client, req.ID, "")
+	importStateResolvedId(ctx, id, err, "target", resp)
+}
+
+func (r *targetResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return NewStateUpgraders(targetStateMigrations)
+}
+
+// GenerateTargetOptions returns the warpgate options of the kind set in the
+// model.
+func GenerateTargetOptions(ctx context.Context, target *provider_models.TargetResource) (warpgate.TargetOptions, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var options warpgate.TargetOptions
+	var err error
+
+	switch {
+	case target.Ssh != nil:
+		err = options.FromTargetOptionsTargetSSHOptions(warpgate.TargetOptionsTargetSSHOptions{
+			Host:     target.Ssh.Host.ValueString(),
+			Port:     uint16(target.Ssh.Port.ValueInt64()),
+			Username: target.Ssh.Username.ValueString(),
+			Auth:     GenerateSshAuth(target.Ssh.TargetSSHOptions, sshTargetPassword(target.Ssh)),
+		})
+
+	case target.Http != nil:
+		var headers map[string]string
+
+		headers, diags = MergeHttpHeaders(ctx, target.Http)
+
+		err = options.FromTargetOptionsTargetHTTPOptions(warpgate.TargetOptionsTargetHTTPOptions{
+			ExternalHost: TerraformStringToNullableString(target.Http.ExternalHost),
+			Url:          target.Http.Url.ValueString(),
+			Headers:      &headers,
+			Tls:          generateTargetTls(target.Http.Tls),
+		})
+
+	case target.MySql != nil:
+		err = options.FromTargetOptionsTargetMySqlOptions(warpgate.TargetOptionsTargetMySqlOptions{
+			Host:     target.MySql.Host.ValueString(),
+			Port:     uint16(target.MySql.Port.ValueInt64()),
+			Username: target.MySql.Username.ValueString(),
+			Password: mysqlTargetPassword(target.MySql),
+			Tls:      generateTargetTls(target.MySql.Tls),
+		})
+
+	case target.Postgres != nil:
+		err = options.FromTargetOptionsTargetPostgresOptions(warpgate.TargetOptionsTargetPostgresOptions{
+			Host:     target.Postgres.Host.ValueString(),
+			Port:     uint16(target.Postgres.Port.ValueInt64()),
+			Username: target.Postgres.Username.ValueString(),
+			Password: postgresTargetPassword(target.Postgres),
+			Tls:      generateTargetTls(target.Postgres.Tls),
+		})
+
+	case target.WebAdmin != nil:
+		err = options.FromTargetOptionsTargetWebAdminOptions(warpgate.TargetOptionsTargetWebAdminOptions{})
+
+	default:
+		err = errors.New("one of ssh, http, mysql, postgres and web_admin must be set")
+	}
+
+	if err != nil {
+		diags.AddError(
+			"Failed to generate the target options",
+			fmt.Sprintf("Failed to generate the options of target '%s'. (Error: %s)", target.Name.ValueString(), err),
+		)
+	}
+
+	return options, diags
+}
+
+// ParseTargetOptions reads the options of any kind into the model, the
+// options of the other kinds are removed. The values that warpgate does not
+// return as set (write-only versions, sensitive headers) are kept from the
+// prior model.
+func ParseTargetOptions(options warpgate.TargetOptions, target *provider_models.TargetResource) error {
+	kind, err := options.Discriminator()
+
+	if err != nil {
+		return err
+	}
+
+	prior := *target
+
+	target.Kind = types.StringValue(kind)
+	target.Ssh = nil
+	target.Http = nil
+	target.MySql = nil
+	target.Postgres = nil
+	target.WebAdmin = nil
+
+	switch kind {
+	case warpgate.TargetKindSsh:
+		sshoptions, err := ParseSshOptions(options)
+
+		if err != nil {
+			return err
+		}
+
+		passwordWoVersion := types.Int64Null()
+
+		if prior.Ssh != nil {
+			passwordWoVersion = prior.Ssh.PasswordWoVersion
+		}
+
+		// the password set with password_wo must not be read back into the state
+		if !passwordWoVersion.IsNull() {
+			sshoptions.Password = types.StringNull()
+		}
+
+		target.Ssh = &provider_models.TargetSSHResourceOptions{
+			TargetSSHOptions:  *sshoptions,
+			PasswordWo:        types.StringNull(),
+			PasswordWoVersion: passwordWoVersion,
+		}
+
+	case warpgate.TargetKindHttp:
+		httpoptions, err := ParseHttpOptions(options, prior.Http)
+
+		if err != nil {
+			return err
+		}
+
+		target.Http = httpoptions
+
+	case warpgate.TargetKindMySql:
+		mysqloptions, err := ParseMySqlOptions(options)
+
+		if err != nil {
+			return err
+		}
+
+		passwordWoVersion := types.Int64Null()
+
+		if prior.MySql != nil {
+			passwordWoVersion = prior.MySql.PasswordWoVersion
+		}
+
+		if !passwordWoVersion.IsNull() {
+			mysqloptions.Password = types.StringNull()
+		}
+
+		target.MySql = &provider_models.TargetMySqlResourceOptions{
+			TargetMySqlOptions: *mysqloptions,
+			PasswordWo:         types.StringNull(),
+			PasswordWoVersion:  passwordWoVersion,
+		}
+
+	case warpgate.TargetKindPostgres:
+		postgresoptions, err := ParsePostgresOptions(options)
+
+		if err != nil {
+			return err
+		}
+
+		passwordWoVersion := types.Int64Null()
+
+		if prior.Postgres != nil {
+			passwordWoVersion = prior.Postgres.PasswordWoVersion
+		}
+
+		if !passwordWoVersion.IsNull() {
+			postgresoptions.Password = types.StringNull()
+		}
+
+		target.Postgres = &provider_models.TargetPostgresResourceOptions{
+			TargetPostgresOptions: *postgresoptions,
+			PasswordWo:            types.StringNull(),
+			PasswordWoVersion:     passwordWoVersion,
+		}
+
+	case warpgate.TargetKindWebAdmin:
+		target.WebAdmin = &provider_models.TargetWebAdminOptions{}
+
+	default:
+		return fmt.Errorf("targets of kind %s are not supported", kind)
+	}
+
+	return nil
+}
+
+func ParseMySqlOptions(options warpgate.TargetOptions) (*provider_models.TargetMySqlOptions, error) {
+	mysqloptions, err := options.AsTargetOptionsTargetMySqlOptions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &provider_models.TargetMySqlOptions{
+		Host:     types.StringValue(mysqloptions.Host),
+		Port:     types.Int64Value(int64(mysqloptions.Port)),
+		Username: types.StringValue(mysqloptions.Username),
+		Password: NullableStringToTerraformString(mysqloptions.Password),
+		Tls: &provider_models.TargetTls{
+			Mode:   types.StringValue(string(mysqloptions.Tls.Mode)),
+			Verify: types.BoolValue(mysqloptions.Tls.Verify),
+		},
+	}, nil
+}
+
+// mysqlTargetPassword returns the password to send to warpgate, from password
+// or from the write-only password_wo, nil if none is set.
+func mysqlTargetPassword(options *provider_models.TargetMySqlResourceOptions) *string {
+	if !options.PasswordWo.IsNull() {
+		return options.PasswordWo.ValueStringPointer()
+	}
+
+	return TerraformStringToNullableString(options.Password)
+}
+
+// postgresTargetPassword is mysqlTargetPassword for the postgres options.
+func postgresTargetPassword(options *provider_models.TargetPostgresResourceOptions) *string {
+	if !options.PasswordWo.IsNull() {
+		return options.PasswordWo.ValueStringPointer()
+	}
+
+	return TerraformStringToNullableString(options.Password)
+}
+
+func generateTargetTls(tls *provider_models.TargetTls) warpgate.Tls {
+	return warpgate.Tls{
+		Mode:   warpgate.TlsMode(tls.Mode.ValueString()),
+		Verify: tls.Verify.ValueBool(),
+	}
+}
+
+// getTargetWriteOnlyAttributes copies the write-only passwords, which are
+// only in the configuration, into the plan.
+func getTargetWriteOnlyAttributes(ctx context.Context, config tfsdk.Config, target *provider_models.TargetResource) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if target.Ssh != nil {
+		diags.Append(config.GetAttribute(ctx, path.Root("ssh").AtName("password_wo"), &target.Ssh.PasswordWo)...)
+	}
+
+	if target.MySql != nil {
+		diags.Append(config.GetAttribute(ctx, path.Root("mysql").AtName("password_wo"), &target.MySql.PasswordWo)...)
+	}
+
+	if target.Postgres != nil {
+		diags.Append(config.GetAttribute(ctx, path.Root("postgres").AtName("password_wo"), &target.Postgres.PasswordWo)...)
+	}
+
+	return diags
+}
+
+func clearTargetWriteOnlyAttributes(target *provider_models.TargetResource) {
+	if target.Ssh != nil {
+		target.Ssh.PasswordWo = types.StringNull()
+	}
+
+	if target.MySql != nil {
+		target.MySql.PasswordWo = types.StringNull()
+	}
+
+	if target.Postgres != nil {
+		target.Postgres.PasswordWo = types.StringNull()
+	}
+}
+
+// checkTargetKind returns an error naming the actual kind of the target when
+// it is not the expected one, e.g. when an http target is imported into
+// warpgate_ssh_target.
+func checkTargetKind(target *warpgate.Target, expected string) error {
+	kind, err := target.Options.Discriminator()
+
+	if err != nil {
+		return err
+	}
+
+	if kind != expected {
+		return fmt.Errorf("target '%s' is a %s target, not a %s target. Manage it with warpgate_target or with the resource of its kind", target.Name, kind, expected)
+	}
+
+	return nil
+}
+
+// targetResourceAddress returns where warpgate connects for the target, the
+// sessions are closed when it changes.
+func targetResourceAddress(target provider_models.TargetResource) string {
+	switch {
+	case target.Ssh != nil:
+		return fmt.Sprintf("%s:%d", target.Ssh.Host.ValueString(), target.Ssh.Port.ValueInt64())
+	case target.Http != nil:
+		return target.Http.Url.ValueString()
+	case target.MySql != nil:
+		return fmt.Sprintf("%s:%d", target.MySql.Host.ValueString(), target.MySql.Port.ValueInt64())
+	case target.Postgres != nil:
+		return postgresTargetAddress(&target.Postgres.TargetPostgresOptions)
+	}
+
+	return ""
+}
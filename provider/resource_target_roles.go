@@ -8,8 +8,8 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
-	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -21,9 +21,15 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &targetRolesResource{}
 var _ resource.ResourceWithImportState = &targetRolesResource{}
+var _ resource.ResourceWithUpgradeState = &targetRolesResource{}
+
+// targetRolesStateMigrations upgrades the state of the older schema versions of the
+// target roles resource, see NewStateUpgraders.
+var targetRolesStateMigrations = []StateMigration{}
 
 func (r targetRolesResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: SchemaVersion(targetRolesStateMigrations),
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:            false,
@@ -48,6 +54,14 @@ func (r targetRolesResource) Schema(ctx context.Context, req resource.SchemaRequ
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -102,6 +116,16 @@ func (r *targetRolesResource) Create(ctx context.Context, req resource.CreateReq
 		return
 	}
 
+	createTimeout, diags := resourceState.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	targetUUID, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
@@ -112,36 +136,15 @@ func (r *targetRolesResource) Create(ctx context.Context, req resource.CreateReq
 		return
 	}
 
-	// for _, roleId := range GetArraySortedToString(resourceState.RoleIds) {
-	for _, roleId := range resourceState.RoleIds.Elements() {
-
-		roleUUID, err := uuid.Parse(roleId.String())
-
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Failed to parse target id.",
-				fmt.Sprintf("Invalid target id %s (Err: %s)", resourceState.Id, err),
-			)
-			return
-		}
-		response, err := r.provider.client.AddTargetRoleWithResponse(ctx, targetUUID, roleUUID)
-
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Failed to create role",
-				fmt.Sprintf("Failed to create role (Err: %s)", err),
-			)
-			return
-		}
-
-		if response.StatusCode() != 201 {
-			resp.Diagnostics.AddError(
-				"Failed to create role, wrong error code.",
-				fmt.Sprintf("Failed to add role %s to target %s. (Error code: %d)", roleId, resourceState.Id, response.StatusCode()),
-			)
-			return
-		}
-	}
+	resourcePlanRoleIds := []string{}
+	resourceState.RoleIds.ElementsAs(ctx, &resourcePlanRoleIds, true)
+
+	// the roles actually added are saved even if some failed, so that the next
+	// apply only retries the missing ones
+	achieved, diags := r.roleReconciler(targetUUID).Reconcile(ctx, []string{}, resourcePlanRoleIds)
+	resp.Diagnostics.Append(diags...)
+
+	resourceState.RoleIds = ArrayOfStringToTerraformSet(achieved)
 
 	diags = resp.State.Set(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -157,6 +160,16 @@ func (r *targetRolesResource) Read(ctx context.Context, req resource.ReadRequest
 		return
 	}
 
+	readTimeout, diags := resourceState.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
 	targetUUID, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
@@ -167,7 +180,7 @@ func (r *targetRolesResource) Read(ctx context.Context, req resource.ReadRequest
 		return
 	}
 
-	response, err := r.provider.client.GetTargetRolesWithResponse(ctx, targetUUID)
+	roles, err := r.provider.client.Targets().Roles(ctx, targetUUID)
 
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -177,19 +190,7 @@ func (r *targetRolesResource) Read(ctx context.Context, req resource.ReadRequest
 		return
 	}
 
-	if response.StatusCode() != 200 {
-		resp.Diagnostics.AddError(
-			"Failed to read target roles, wrong error code.",
-			fmt.Sprintf("Failed to read roles of target with id '%s'. (Error code: %d)", resourceState.Id, response.StatusCode()),
-		)
-		return
-	}
-
-	if response.JSON200 != nil {
-		resourceState.RoleIds = ArrayOfRolesToTerraformSet(*response.JSON200)
-	} else {
-		resourceState.RoleIds = types.SetNull(types.StringType)
-	}
+	resourceState.RoleIds = ArrayOfRolesToTerraformSet(roles)
 
 	diags = resp.State.Set(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -209,6 +210,16 @@ func (r *targetRolesResource) Update(ctx context.Context, req resource.UpdateReq
 		return
 	}
 
+	updateTimeout, diags := resourcePlan.Timeouts.Update(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	targetUUID, err := uuid.Parse(resourcePlan.Id.ValueString())
 
 	if err != nil {
@@ -225,70 +236,10 @@ func (r *targetRolesResource) Update(ctx context.Context, req resource.UpdateReq
 	resourceState.RoleIds.ElementsAs(ctx, &resourceStateRoleIds, true)
 	resourcePlan.RoleIds.ElementsAs(ctx, &resourcePlanRoleIds, true)
 
-	_, toBeCreated, toBeDeleted := ArrayIntersection(resourcePlanRoleIds, resourceStateRoleIds)
-
-	for _, roleId := range toBeDeleted {
-		roleUUID, err := uuid.Parse(roleId)
-
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Failed to parse target id.",
-				fmt.Sprintf("Invalid target id %s (Err: %s)", resourceState.Id, err),
-			)
-			return
-		}
-		response, err := r.provider.client.DeleteTargetRoleWithResponse(ctx, targetUUID, roleUUID)
-
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Failed to delete role",
-				fmt.Sprintf("Failed to delete role (Err: %s)", err),
-			)
-			return
-		}
-
-		if response.StatusCode() == 409 {
-			resp.Diagnostics.AddWarning(
-				"Failed to delete role, conflict.",
-				fmt.Sprintf("Failed to remove role %s from target %s. (Error code: %d)", roleId, resourceState.Id, response.StatusCode()),
-			)
-		} else if response.StatusCode() != 204 {
-			resp.Diagnostics.AddError(
-				"Failed to delete role, wrong error code.",
-				fmt.Sprintf("Failed to remove role %s from target %s. (Error code: %d)", roleId, resourceState.Id, response.StatusCode()),
-			)
-			return
-		}
-	}
+	achieved, diags := r.roleReconciler(targetUUID).Reconcile(ctx, resourceStateRoleIds, resourcePlanRoleIds)
+	resp.Diagnostics.Append(diags...)
 
-	for _, roleId := range toBeCreated {
-		roleUUID, err := uuid.Parse(roleId)
-
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Failed to parse target id.",
-				fmt.Sprintf("Invalid target id %s (Err: %s)", resourceState.Id, err),
-			)
-			return
-		}
-		response, err := r.provider.client.AddTargetRoleWithResponse(ctx, targetUUID, roleUUID)
-
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Failed to create role",
-				fmt.Sprintf("Failed to create role (Err: %s)", err),
-			)
-			return
-		}
-
-		if response.StatusCode() != 201 {
-			resp.Diagnostics.AddError(
-				"Failed to create role, wrong error code.",
-				fmt.Sprintf("Failed to add role %s to target %s. (Error code: %d)", roleId, resourceState.Id, response.StatusCode()),
-			)
-			return
-		}
-	}
+	resourcePlan.RoleIds = ArrayOfStringToTerraformSet(achieved)
 
 	diags = resp.State.Set(ctx, &resourcePlan)
 	resp.Diagnostics.Append(diags...)
@@ -304,6 +255,16 @@ func (r *targetRolesResource) Delete(ctx context.Context, req resource.DeleteReq
 		return
 	}
 
+	deleteTimeout, diags := resourceState.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	targetUUID, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
@@ -314,42 +275,31 @@ func (r *targetRolesResource) Delete(ctx context.Context, req resource.DeleteReq
 		return
 	}
 
-	for _, roleId := range resourceState.RoleIds.Elements() {
-
-		roleUUID, err := uuid.Parse(roleId.String())
-
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Failed to parse target id.",
-				fmt.Sprintf("Invalid target id %s (Err: %s)", resourceState.Id, err),
-			)
-			return
-		}
-		response, err := r.provider.client.DeleteTargetRoleWithResponse(ctx, targetUUID, roleUUID)
-
-		if err != nil {
-			resp.Diagnostics.AddError(
-				"Failed to delete role",
-				fmt.Sprintf("Failed to delete role (Err: %s)", err),
-			)
-			return
-		}
-
-		if response.StatusCode() == 409 {
-			resp.Diagnostics.AddWarning(
-				"Failed to delete role, conflict.",
-				fmt.Sprintf("Failed to remove role %s from target %s. (Error code: %d)", roleId, resourceState.Id, response.StatusCode()),
-			)
-		} else if response.StatusCode() != 204 {
-			resp.Diagnostics.AddError(
-				"Failed to delete role, wrong error code.",
-				fmt.Sprintf("Failed to remove role %s from target %s. (Error code: %d)", roleId, resourceState.Id, response.StatusCode()),
-			)
-			return
-		}
+	resourceStateRoleIds := []string{}
+	resourceState.RoleIds.ElementsAs(ctx, &resourceStateRoleIds, true)
+
+	achieved, diags := r.roleReconciler(targetUUID).Reconcile(ctx, resourceStateRoleIds, []string{})
+	resp.Diagnostics.Append(diags...)
+
+	// the resource is removed from the state only without errors, otherwise
+	// the roles that are still assigned are kept
+	if resp.Diagnostics.HasError() {
+		resourceState.RoleIds = ArrayOfStringToTerraformSet(achieved)
+
+		diags = resp.State.Set(ctx, &resourceState)
+		resp.Diagnostics.Append(diags...)
 	}
 }
 
 func (r *targetRolesResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	id, err := ResolveTargetImportId(ctx, r.provider.client, req.ID, "")
+	importStateResolvedId(ctx, id, err, "target roles", resp)
+}
+
+func (r *targetRolesResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return NewStateUpgraders(targetRolesStateMigrations)
+}
+
+func (r *targetRolesResource) roleReconciler(targetUUID uuid.UUID) RoleReconciler {
+	return targetRoleReconciler(r.provider.client, targetUUID)
 }
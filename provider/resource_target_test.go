@@ -0,0 +1,474 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/warpgate"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccTargetResource(t *testing.T) {
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccTargetSshResourceConfig("one", "10.10.10.10"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_target.test", "name", "one"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "kind", "Ssh"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "ssh.host", "10.10.10.10"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "ssh.auth_kind", "PublicKey"),
+					resource.TestCheckNoResourceAttr("warpgate_target.test", "http.url"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "allow_roles.#", "0"),
+					testCheckFuncValidUUID("warpgate_target.test", "id"),
+					testCheckFuncSaveAttr("warpgate_target.test", "id", &id),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "warpgate_target.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update and Read testing, same kind
+			{
+				Config: testAccTargetSshResourceConfig("two", "20.20.20.20"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_target.test", "name", "two"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "ssh.host", "20.20.20.20"),
+					testCheckFuncAttrEqual("warpgate_target.test", "id", &id),
+				),
+			},
+			// Replace testing, the kind changes
+			{
+				Config: testAccTargetHttpResourceConfig("two"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_target.test", "kind", "Http"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "http.url", "http://10.10.10.10"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "http.tls.mode", "Disabled"),
+					resource.TestCheckNoResourceAttr("warpgate_target.test", "ssh.host"),
+					testCheckFuncAttrNotEqual("warpgate_target.test", "id", &id),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccTargetInlineRolesResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTargetInlineRolesResourceConfig(`[warpgate_role.developers.name, warpgate_role.ops.name]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_target.test", "allow_roles.#", "2"),
+					resource.TestCheckTypeSetElemAttr("warpgate_target.test", "allow_roles.*", "target-developers"),
+					resource.TestCheckTypeSetElemAttr("warpgate_target.test", "allow_roles.*", "target-ops"),
+				),
+			},
+			{
+				Config: testAccTargetInlineRolesResourceConfig(`[warpgate_role.ops.name]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_target.test", "allow_roles.#", "1"),
+					resource.TestCheckTypeSetElemAttr("warpgate_target.test", "allow_roles.*", "target-ops"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTargetMySqlResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "warpgate" {}
+
+resource "warpgate_target" "test" {
+	name = "database"
+	mysql = {
+		host     = "10.10.10.10"
+		port     = 3306
+		username = "root"
+		password = "A12345678"
+		tls = {
+			mode   = "Preferred"
+			verify = true
+		}
+	}
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_target.test", "kind", "MySql"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "mysql.port", "3306"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "mysql.password", "A12345678"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "mysql.tls.mode", "Preferred"),
+				),
+			},
+			{
+				ResourceName:      "warpgate_target.test",
+				ImportState:       true,
+				ImportStateId:     "name:database",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccTargetPostgresResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "warpgate" {}
+
+resource "warpgate_target" "test" {
+	name = "billing"
+	postgres = {
+		host                = "10.10.10.11"
+		port                = 5432
+		username            = "app"
+		password_wo         = "A12345678"
+		password_wo_version = 1
+		tls = {
+			mode   = "Required"
+			verify = true
+		}
+	}
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_target.test", "kind", "Postgres"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "postgres.port", "5432"),
+					resource.TestCheckNoResourceAttr("warpgate_target.test", "postgres.password"),
+					resource.TestCheckNoResourceAttr("warpgate_target.test", "postgres.password_wo"),
+					resource.TestCheckResourceAttr("warpgate_target.test", "postgres.password_wo_version", "1"),
+				),
+			},
+			{
+				ResourceName:            "warpgate_target.test",
+				ImportState:             true,
+				ImportStateId:           "name:billing",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"postgres.password", "postgres.password_wo_version"},
+			},
+		},
+	})
+}
+
+func TestAccTargetWebAdminResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// the built-in admin target can only be imported
+			{
+				Config: `
+provider "warpgate" {}
+
+import {
+	to = warpgate_target.admin
+	id = "name:warpgate:admin"
+}
+
+resource "warpgate_target" "admin" {
+	name      = "warpgate:admin"
+	web_admin = {}
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_target.admin", "kind", "WebAdmin"),
+					testCheckFuncValidUUID("warpgate_target.admin", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestTargetOptionsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	tls := &provider_models.TargetTls{Mode: types.StringValue("Required"), Verify: types.BoolValue(true)}
+
+	cases := map[string]provider_models.TargetResource{
+		warpgate.TargetKindSsh: {
+			Ssh: &provider_models.TargetSSHResourceOptions{
+				TargetSSHOptions: provider_models.TargetSSHOptions{
+					Host:     types.StringValue("10.0.0.1"),
+					Port:     types.Int64Value(22),
+					Username: types.StringValue("root"),
+					Password: types.StringValue("secret"),
+					AuthKind: types.StringValue("Password"),
+				},
+				PasswordWo:        types.StringNull(),
+				PasswordWoVersion: types.Int64Null(),
+			},
+		},
+		warpgate.TargetKindHttp: {
+			Http: &provider_models.TargetHttpOptions{
+				ExternalHost:     types.StringNull(),
+				Url:              types.StringValue("https://10.0.0.1"),
+				Headers:          types.MapNull(types.StringType),
+				SensitiveHeaders: types.MapNull(types.StringType),
+				Tls:              tls,
+			},
+		},
+		warpgate.TargetKindMySql: {
+			MySql: &provider_models.TargetMySqlResourceOptions{
+				TargetMySqlOptions: provider_models.TargetMySqlOptions{
+					Host:     types.StringValue("10.0.0.2"),
+					Port:     types.Int64Value(3306),
+					Username: types.StringValue("root"),
+					Password: types.StringValue("secret"),
+					Tls:      tls,
+				},
+				PasswordWo:        types.StringNull(),
+				PasswordWoVersion: types.Int64Null(),
+			},
+		},
+		warpgate.TargetKindPostgres: {
+			Postgres: &provider_models.TargetPostgresResourceOptions{
+				TargetPostgresOptions: provider_models.TargetPostgresOptions{
+					Host:     types.StringValue("10.0.0.3"),
+					Port:     types.Int64Value(5432),
+					Username: types.StringValue("app"),
+					Password: types.StringValue("secret"),
+					Tls:      tls,
+				},
+				PasswordWo:        types.StringNull(),
+				PasswordWoVersion: types.Int64Null(),
+			},
+		},
+		warpgate.TargetKindWebAdmin: {
+			WebAdmin: &provider_models.TargetWebAdminOptions{},
+		},
+	}
+
+	for kind, target := range cases {
+		options, diags := GenerateTargetOptions(ctx, &target)
+
+		if diags.HasError() {
+			t.Fatalf("%s: %v", kind, diags)
+		}
+
+		if discriminator, _ := options.Discriminator(); discriminator != kind {
+			t.Errorf("%s: generated options of kind %s", kind, discriminator)
+		}
+
+		// parse into a target of another kind, the other options are removed
+		parsed := provider_models.TargetResource{Ssh: cases[warpgate.TargetKindSsh].Ssh}
+
+		if kind == warpgate.TargetKindSsh {
+			parsed = provider_models.TargetResource{Http: cases[warpgate.TargetKindHttp].Http}
+		}
+
+		if err := ParseTargetOptions(options, &parsed); err != nil {
+			t.Fatalf("%s: %s", kind, err)
+		}
+
+		parsed.Kind = types.StringNull()
+		target.Kind = types.StringNull()
+
+		if !reflect.DeepEqual(parsed, target) {
+			t.Errorf("%s: expected %#v, got %#v", kind, target, parsed)
+		}
+	}
+}
+
+func TestTargetOptionsPasswordWo(t *testing.T) {
+	ctx := context.Background()
+
+	target := provider_models.TargetResource{
+		MySql: &provider_models.TargetMySqlResourceOptions{
+			TargetMySqlOptions: provider_models.TargetMySqlOptions{
+				Host:     types.StringValue("10.0.0.2"),
+				Port:     types.Int64Value(3306),
+				Username: types.StringValue("root"),
+				Password: types.StringNull(),
+				Tls:      &provider_models.TargetTls{Mode: types.StringValue("Disabled"), Verify: types.BoolValue(false)},
+			},
+			PasswordWo:        types.StringValue("write-only"),
+			PasswordWoVersion: types.Int64Value(1),
+		},
+	}
+
+	options, diags := GenerateTargetOptions(ctx, &target)
+
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	mysql, err := options.AsTargetOptionsTargetMySqlOptions()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mysql.Password == nil || *mysql.Password != "write-only" {
+		t.Errorf("expected the write-only password to be sent, got %v", mysql.Password)
+	}
+
+	if err := ParseTargetOptions(options, &target); err != nil {
+		t.Fatal(err)
+	}
+
+	if !target.MySql.Password.IsNull() || target.MySql.PasswordWoVersion.ValueInt64() != 1 {
+		t.Errorf("expected the write-only password not to be read back, got %+v", target.MySql)
+	}
+}
+
+func TestTargetOptionsPostgresPasswordWo(t *testing.T) {
+	ctx := context.Background()
+
+	target := provider_models.TargetResource{
+		Postgres: &provider_models.TargetPostgresResourceOptions{
+			TargetPostgresOptions: provider_models.TargetPostgresOptions{
+				Host:     types.StringValue("10.0.0.3"),
+				Port:     types.Int64Value(5432),
+				Username: types.StringValue("app"),
+				Password: types.StringNull(),
+				Tls:      &provider_models.TargetTls{Mode: types.StringValue("Required"), Verify: types.BoolValue(true)},
+			},
+			PasswordWo:        types.StringValue("write-only"),
+			PasswordWoVersion: types.Int64Value(1),
+		},
+	}
+
+	options, diags := GenerateTargetOptions(ctx, &target)
+
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	postgres, err := options.AsTargetOptionsTargetPostgresOptions()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if postgres.Password == nil || *postgres.Password != "write-only" {
+		t.Errorf("expected the write-only password to be sent, got %v", postgres.Password)
+	}
+
+	if err := ParseTargetOptions(options, &target); err != nil {
+		t.Fatal(err)
+	}
+
+	if !target.Postgres.Password.IsNull() || target.Postgres.PasswordWoVersion.ValueInt64() != 1 {
+		t.Errorf("expected the write-only password not to be read back, got %+v", target.Postgres)
+	}
+
+	if address := targetResourceAddress(target); address != "10.0.0.3:5432" {
+		t.Errorf("expected the address 10.0.0.3:5432, got %s", address)
+	}
+}
+
+func testCheckFuncSaveAttr(name string, key string, value *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		is, err := modulePrimaryInstanceState(s.RootModule(), name)
+
+		if err != nil {
+			return err
+		}
+
+		*value = is.Attributes[key]
+
+		return nil
+	}
+}
+
+func testCheckFuncAttrEqual(name string, key string, value *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		return resource.TestCheckResourceAttr(name, key, *value)(s)
+	}
+}
+
+func testCheckFuncAttrNotEqual(name string, key string, value *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		is, err := modulePrimaryInstanceState(s.RootModule(), name)
+
+		if err != nil {
+			return err
+		}
+
+		if is.Attributes[key] == *value {
+			return fmt.Errorf("%s: expected attribute '%s' to change from %s", name, key, *value)
+		}
+
+		return nil
+	}
+}
+
+func testAccTargetSshResourceConfig(name string, host string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_target" "test" {
+	name = "%s"
+	ssh = {
+		host      = "%s"
+		port      = 22
+		username  = "root"
+		auth_kind = "PublicKey"
+	}
+}
+`, name, host)
+}
+
+func testAccTargetHttpResourceConfig(name string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_target" "test" {
+	name = "%s"
+	http = {
+		url = "http://10.10.10.10"
+		tls = {
+			mode   = "Disabled"
+			verify = false
+		}
+	}
+}
+`, name)
+}
+
+func testAccTargetInlineRolesResourceConfig(roles string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_role" "developers" {
+	name = "target-developers"
+}
+
+resource "warpgate_role" "ops" {
+	name = "target-ops"
+}
+
+resource "warpgate_target" "test" {
+	name        = "inline-roles"
+	allow_roles = %s
+	http = {
+		url = "http://10.10.10.10"
+		tls = {
+			mode   = "Disabled"
+			verify = false
+		}
+	}
+}
+`, roles)
+}
@@ -4,20 +4,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/provider/sshkeys"
+	"terraform-provider-warpgate/provider/validators"
 	"terraform-provider-warpgate/warpgate"
 
 	"github.com/google/uuid"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -25,18 +33,32 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces
 var _ resource.Resource = &userTargetResource{}
 var _ resource.ResourceWithImportState = &userTargetResource{}
+var _ resource.ResourceWithUpgradeState = &userTargetResource{}
+var _ resource.ResourceWithModifyPlan = &userTargetResource{}
 
 var credentialsAttributes = map[string]attr.Type{
-	"kind":       types.StringType,
-	"hash":       types.StringType,
-	"email":      types.StringType,
-	"provider":   types.StringType,
-	"public_key": types.StringType,
-	"totp_key":   types.ListType{ElemType: types.Int64Type},
+	"kind":               types.StringType,
+	"hash":               types.StringType,
+	"email":              types.StringType,
+	"provider":           types.StringType,
+	"public_key":         sshkeys.PublicKeyType{},
+	"fingerprint_sha256": types.StringType,
+	"key_type":           types.StringType,
+	"totp_key":           types.ListType{ElemType: types.Int64Type},
+}
+
+// userStateMigrations upgrades the state of the older schema versions of the
+// user resource, see NewStateUpgraders.
+var userStateMigrations = []StateMigration{}
+
+var ssoCredentialAttributes = map[string]attr.Type{
+	"email":    types.StringType,
+	"provider": types.StringType,
 }
 
 func (r userTargetResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: SchemaVersion(userStateMigrations),
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:            true,
@@ -46,18 +68,112 @@ func (r userTargetResource) Schema(ctx context.Context, req resource.SchemaReque
 				},
 			},
 			"roles": schema.SetAttribute{
-				Computed:            true,
-				MarkdownDescription: "The list of roles that the user belong to. To assign a new role to the user refer to [user_roles](user_roles.md) ",
-				ElementType:         types.StringType,
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "The names of the roles that the user belong to. When set, the roles are assigned and removed by this resource, " +
+					"otherwise they are only read and can be assigned with [user_roles](user_roles.md). Do not use both on the same user: the plan only warns about it once the roles changed outside of the resource since its last apply.",
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"username": schema.StringAttribute{
 				Computed:            false,
 				Required:            true,
 				MarkdownDescription: "The username of the user.",
 			},
+			"close_sessions_on_change": closeSessionsOnChangeAttribute("of the user when the user is deleted or a credential is removed, " +
+				"including the write-only ones replaced by a new `_version`"),
+			"password_hash_wo": schema.StringAttribute{
+				Optional:  true,
+				Sensitive: true,
+				WriteOnly: true,
+				Description: "Write-only hashed password (requires Terraform 1.11), sent as an additional `Password` credential " +
+					"and never stored in the state or in the plan. Change `password_hash_wo_version` to update it.",
+				Validators: []validator.String{
+					stringvalidator.AlsoRequires(path.MatchRoot("password_hash_wo_version")),
+				},
+			},
+			"password_hash_wo_version": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Triggers the update of `password_hash_wo`, which terraform cannot compare with the previous value.",
+			},
+			"totp_key_wo": schema.ListAttribute{
+				ElementType: types.Int64Type,
+				Optional:    true,
+				Sensitive:   true,
+				WriteOnly:   true,
+				Description: "Write-only totp secret key as array of uint8 (requires Terraform 1.11), sent as an additional `Totp` credential " +
+					"and never stored in the state or in the plan. Change `totp_key_wo_version` to update it.",
+				Validators: []validator.List{
+					listvalidator.AlsoRequires(path.MatchRoot("totp_key_wo_version")),
+				},
+			},
+			"totp_key_wo_version": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Triggers the update of `totp_key_wo`, which terraform cannot compare with the previous value.",
+			},
+			"password": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "The `Password` credential of the user, an alternative to `credentials`.",
+				Attributes: map[string]schema.Attribute{
+					"hash": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "The hashed password.",
+					},
+				},
+			},
+			"totp": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "The `Totp` credential of the user, an alternative to `credentials`.",
+				Attributes: map[string]schema.Attribute{
+					"key": schema.ListAttribute{
+						ElementType: types.Int64Type,
+						Required:    true,
+						Sensitive:   true,
+						Description: "The totp secret key as array of uint8.",
+					},
+				},
+			},
+			"sso": schema.SetNestedAttribute{
+				Optional:    true,
+				Description: "The `Sso` credentials of the user, an alternative to `credentials`.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"email": schema.StringAttribute{
+							Required:    true,
+							Description: "The email of the user in the sso system.",
+						},
+						"provider": schema.StringAttribute{
+							Optional:    true,
+							Description: "The sso provider name defined in the configuration file.",
+						},
+					},
+				},
+			},
+			"public_keys": schema.SetAttribute{
+				ElementType: sshkeys.PublicKeyType{},
+				Optional:    true,
+				Description: "The ssh public keys that the user uses to connect via ssh (`PublicKey` credentials), an alternative to `credentials`.",
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(validators.IsSshPublicKey()),
+				},
+			},
 			"credentials": schema.SetNestedAttribute{
 				Computed: false,
-				Required: true,
+				Optional: true,
+				Description: "The credentials of the user. Any change of an element replaces it in the plan, " +
+					"use `password`, `totp`, `sso` and `public_keys` for readable diffs. When neither of them is set, " +
+					"the credentials are not managed by this resource and can be added with the per-credential resources (e.g. `warpgate_user_public_key`).",
+				Validators: []validator.Set{
+					setvalidator.ConflictsWith(
+						path.MatchRoot("password"),
+						path.MatchRoot("totp"),
+						path.MatchRoot("sso"),
+						path.MatchRoot("public_keys"),
+					),
+				},
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"kind": schema.StringAttribute{
@@ -128,6 +244,7 @@ func (r userTargetResource) Schema(ctx context.Context, req resource.SchemaReque
 						},
 						/////////////////////////////////////////////////////////////////////////////////
 						"public_key": schema.StringAttribute{
+							CustomType:  sshkeys.PublicKeyType{},
 							Computed:    false,
 							Required:    false,
 							Optional:    true,
@@ -139,8 +256,17 @@ func (r userTargetResource) Schema(ctx context.Context, req resource.SchemaReque
 									path.MatchRelative().AtParent().AtName("provider"),
 									path.MatchRelative().AtParent().AtName("totp_key"),
 								),
+								validators.IsSshPublicKey(),
 							},
 						},
+						"fingerprint_sha256": schema.StringAttribute{
+							Computed:    true,
+							Description: "The SHA256 fingerprint of the public key, as printed by `ssh-keygen -l`. Only for kind: `PublicKey`",
+						},
+						"key_type": schema.StringAttribute{
+							Computed:    true,
+							Description: "The type of the public key (e.g. `ssh-ed25519`). Only for kind: `PublicKey`",
+						},
 						/////////////////////////////////////////////////////////////////////////////////
 						"totp_key": schema.ListAttribute{
 							ElementType: types.Int64Type,
@@ -165,8 +291,19 @@ func (r userTargetResource) Schema(ctx context.Context, req resource.SchemaReque
 				// Validators: []validator.List{
 				// 	listvalidator.SizeAtMost(2),
 				// },
+				PlanModifiers: []planmodifier.Set{
+					userCredentialsPlanModifier{},
+				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -214,37 +351,46 @@ type userTargetResource struct {
 func (r *userTargetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var resourceState provider_models.User
 
-	diags := req.Config.Get(ctx, &resourceState)
+	diags := req.Plan.Get(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
 
+	resp.Diagnostics.Append(getUserWriteOnlyAttributes(ctx, req.Config, &resourceState)...)
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	response, err := r.provider.client.CreateUserWithResponse(ctx, warpgate.UserDataRequest{
+	createTimeout, diags := resourceState.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	credentials := GenerateWarpgateUserAuthCredentials(ctx, resourceState)
+	clearUserWriteOnlyAttributes(&resourceState)
+
+	user, err := r.provider.client.Users().Create(ctx, warpgate.UserDataRequest{
 		Username:    resourceState.Username.ValueString(),
-		Credentials: GenerateWarpgateUserAuthCredentials(ctx, resourceState),
+		Credentials: credentials,
 		// CredentialPolicy: &warpgate.UserRequireCredentialsPolicy{},
 	})
 
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to create user",
-			"Failed to create user",
+			fmt.Sprintf("Failed to create user. (Error: %s)", err),
 		)
 		return
 	}
 
-	if response.StatusCode() != 201 {
-		resp.Diagnostics.AddError(
-			"Failed to create user, wrong error code.",
-			fmt.Sprintf("Failed to create user. (Error code: %d)", response.StatusCode()),
-		)
-		return
-	}
+	resourceState.Id = types.StringValue(user.Id.String())
 
-	resourceState.Id = types.StringValue(response.JSON201.Id.String())
-	resourceState.Roles = ArrayOfStringToTerraformSet(response.JSON201.Roles)
+	resourceState.Roles, diags = applyInlineRoles(ctx, r.provider.client, userRoleReconciler(r.provider.client, user.Id), user.Roles, resourceState.Roles, resp.Private)
+	resp.Diagnostics.Append(diags...)
 
 	diags = resp.State.Set(ctx, &resourceState)
 	resp.Diagnostics.Append(diags...)
@@ -260,49 +406,51 @@ func (r *userTargetResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
+	readTimeout, diags := resourceState.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
 
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to parse the id as uuid",
-			fmt.Sprintf("Failed to parse the id %s as uuid", resourceState.Id.String()),
-		)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	response, err := r.provider.client.GetUserWithResponse(ctx, id_as_uuid)
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Failed to read user",
-			fmt.Sprintf("Failed to read user with id '%s'. (Error: %s)", resourceState.Id, err),
+			"Failed to parse the id as uuid",
+			fmt.Sprintf("Failed to parse the id %s as uuid", resourceState.Id.String()),
 		)
 		return
 	}
 
-	if response.StatusCode() == 404 {
+	warpgateUser, err := r.provider.client.Users().Get(ctx, id_as_uuid)
+
+	if errors.Is(err, warpgate.ErrNotFound) {
 		resp.Diagnostics.AddWarning(
 			"Failed to read user, resource not found. Removing from the state.",
-			fmt.Sprintf("Failed to read user. (Error code: %d)", response.StatusCode()),
+			fmt.Sprintf("Failed to read user with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
 		resp.State.RemoveResource(ctx)
 		return
 	}
 
-	if response.StatusCode() != 200 {
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"Failed to read user, wrong error code.",
-			fmt.Sprintf("Failed to read user. (Error code: %d)", response.StatusCode()),
+			"Failed to read user",
+			fmt.Sprintf("Failed to read user with id '%s'. (Error: %s)", resourceState.Id, err),
 		)
 		return
 	}
 
-	user, err := ParseUser(response.JSON200)
+	user, err := ParseUser(ctx, warpgateUser)
 
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to read user. Wrong options",
-			fmt.Sprintf("Failed to read user %v. Wrong options type. (Error: %v ", response.JSON200, err),
+			fmt.Sprintf("Failed to read user %v. Wrong options type. (Error: %v ", user, err),
 		)
 		return
 	}
@@ -315,8 +463,34 @@ func (r *userTargetResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
+	credentials, diags := withoutWriteOnlyCredentials(ctx, user.Credentials, resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// after an import only the id is known, the credentials are read into
+	// credentials, which holds any number of credentials of every kind
+	imported := resourceState.Username.IsNull()
+
 	resourceState.Roles = user.Roles
-	resourceState.Credentials = user.Credentials
+
+	if resourceState.UsesTypedCredentials() {
+		err = setTypedUserCredentials(ctx, &resourceState, credentials, resourceState)
+
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to read user",
+				fmt.Sprintf("Failed to read the credentials of user with id '%s'. (Error: %s)", resourceState.Id, err),
+			)
+			return
+		}
+
+		resourceState.Credentials = types.SetNull(types.ObjectType{AttrTypes: credentialsAttributes})
+	} else if resourceState.ManagesCredentials() || imported {
+		resourceState.Credentials = credentials
+	}
 	// resourceState.Credentials = types.SetNull(types.ObjectType{AttrTypes: credentialsAttributes})
 	resourceState.Username = user.Username
 
@@ -325,15 +499,29 @@ func (r *userTargetResource) Read(ctx context.Context, req resource.ReadRequest,
 }
 
 func (r *userTargetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var resourceState provider_models.User
 	var resourcePlan provider_models.User
 
-	diags := req.Plan.Get(ctx, &resourcePlan)
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	diags = req.Plan.Get(ctx, &resourcePlan)
 	resp.Diagnostics.Append(diags...)
 
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	updateTimeout, diags := resourcePlan.Timeouts.Update(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	id_as_uuid, err := uuid.Parse(resourcePlan.Id.ValueString())
 
 	if err != nil {
@@ -344,39 +532,69 @@ func (r *userTargetResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
-	response, err := r.provider.client.UpdateUserWithResponse(ctx, id_as_uuid, warpgate.UserDataRequest{
-		Username:    resourcePlan.Username.ValueString(),
-		Credentials: GenerateWarpgateUserAuthCredentials(ctx, resourcePlan),
-		// CredentialPolicy: &warpgate.UserRequireCredentialsPolicy{},
-	})
+	diags = getUserWriteOnlyAttributes(ctx, req.Config, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
 
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to update user",
-			fmt.Sprintf("Failed to update user with id '%s'. (Error: %s)", resourcePlan.Id, err),
-		)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if response.StatusCode() != 200 {
+	credentials := GenerateWarpgateUserAuthCredentials(ctx, resourcePlan)
+	clearUserWriteOnlyAttributes(&resourcePlan)
+
+	var user *warpgate.User
+
+	if resourcePlan.ManagesCredentials() {
+		user, err = r.provider.client.Users().Update(ctx, id_as_uuid, warpgate.UserDataRequest{
+			Username:    resourcePlan.Username.ValueString(),
+			Credentials: credentials,
+			// CredentialPolicy: &warpgate.UserRequireCredentialsPolicy{},
+		})
+	} else {
+		// the credentials are left to the per-credential resources, they are
+		// kept with the same read-modify-write used by them
+		user, err = r.provider.client.Users().Credentials(id_as_uuid).Update(ctx, func(user *warpgate.UserDataRequest) error {
+			user.Username = resourcePlan.Username.ValueString()
+			return nil
+		})
+	}
+
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"Failed to update user, wrong error code.",
-			fmt.Sprintf("Failed to update user. (Error code: %d)", response.StatusCode()),
+			"Failed to update user",
+			fmt.Sprintf("Failed to update user with id '%s'. (Error: %s)", resourcePlan.Id, err),
 		)
 		return
 	}
 
 	// probably unnecessary check
-	if response.JSON200.Id != id_as_uuid || response.JSON200.Username != resourcePlan.Username.ValueString() {
+	if user.Id != id_as_uuid || user.Username != resourcePlan.Username.ValueString() {
 		resp.Diagnostics.AddWarning(
 			"Created resource is different from requested.",
 			fmt.Sprintf("Created resource is different from requested. Requested: (%s, %s), Created: (%s, %s)",
-				response.JSON200.Id, response.JSON200.Username,
+				user.Id, user.Username,
 				resourcePlan.Id, resourcePlan.Username,
 			),
 		)
 	}
-	resourcePlan.Roles = ArrayOfStringToTerraformSet(response.JSON200.Roles)
+
+	resourcePlan.Roles, diags = applyInlineRoles(ctx, r.provider.client, userRoleReconciler(r.provider.client, id_as_uuid), user.Roles, resourcePlan.Roles, resp.Private)
+	resp.Diagnostics.Append(diags...)
+
+	if resourcePlan.CloseSessionsOnChange.ValueBool() {
+		removed, err := userCredentialsRemoved(ctx, resourceState, resourcePlan)
+
+		if err != nil {
+			resp.Diagnostics.AddWarning(
+				"Failed to close sessions",
+				fmt.Sprintf("Failed to compare the credentials of user with id '%s'. (Error: %s)", resourcePlan.Id, err),
+			)
+		}
+
+		if removed {
+			resp.Diagnostics.Append(closeSessionsOnChange(ctx, r.provider.client, fmt.Sprintf("user '%s'", resourceState.Username.ValueString()), userSessions(resourceState.Username.ValueString()))...)
+		}
+	}
 
 	tflog.Debug(ctx, fmt.Sprintf("Updating user state: %v", resourcePlan))
 
@@ -394,6 +612,16 @@ func (r *userTargetResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
+	deleteTimeout, diags := resourceState.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	id_as_uuid, err := uuid.Parse(resourceState.Id.ValueString())
 
 	if err != nil {
@@ -404,7 +632,7 @@ func (r *userTargetResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
-	response, err := r.provider.client.DeleteUserWithResponse(ctx, id_as_uuid)
+	err = r.provider.client.Users().Delete(ctx, id_as_uuid)
 
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -414,17 +642,64 @@ func (r *userTargetResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
-	if response.StatusCode() != 204 {
-		resp.Diagnostics.AddError(
-			"Failed to delete user, wrong error code.",
-			fmt.Sprintf("Failed to delete user. (Error code: %d)", response.StatusCode()),
-		)
+	if resourceState.CloseSessionsOnChange.ValueBool() {
+		resp.Diagnostics.Append(closeSessionsOnChange(ctx, r.provider.client, fmt.Sprintf("user '%s'", resourceState.Username.ValueString()), userSessions(resourceState.Username.ValueString()))...)
+	}
+}
+
+// ModifyPlan warns when the roles configured on the user changed outside of
+// the resource since the last apply, see warnInlineRolesChanged, and checks
+// the provider of the Sso credentials.
+func (r *userTargetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	warnInlineRolesChanged(ctx, req, resp, "roles", "warpgate_user_roles")
+
+	if req.Plan.Raw.IsNull() || r.provider == nil {
 		return
 	}
+
+	// only the sets holding Sso credentials are read, the other attributes
+	// may still be unknown
+	var credentialsPlan, ssoPlan types.Set
+
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("credentials"), &credentialsPlan)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("sso"), &ssoPlan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ssoProviders := newSsoProviderCheck(r.provider.client)
+
+	if !credentialsPlan.IsUnknown() {
+		var credentials []provider_models.UserAuthCredential
+
+		resp.Diagnostics.Append(credentialsPlan.ElementsAs(ctx, &credentials, true)...)
+
+		for _, credential := range credentials {
+			if credential.Kind.ValueString() == string(warpgate.Sso) {
+				resp.Diagnostics.Append(ssoProviders.check(ctx, path.Root("credentials"), credential.Email, credential.Provider)...)
+			}
+		}
+	}
+
+	if !ssoPlan.IsUnknown() {
+		var sso []provider_models.UserSsoCredential
+
+		resp.Diagnostics.Append(ssoPlan.ElementsAs(ctx, &sso, true)...)
+
+		for _, credential := range sso {
+			resp.Diagnostics.Append(ssoProviders.check(ctx, path.Root("sso"), credential.Email, credential.Provider)...)
+		}
+	}
 }
 
 func (r *userTargetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	id, err := ResolveUserImportId(ctx, r.provider.client, req.ID)
+	importStateResolvedId(ctx, id, err, "user", resp)
+}
+
+func (r *userTargetResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return NewStateUpgraders(userStateMigrations)
 }
 
 func ParseUserCredential(credential warpgate.UserAuthCredential) (result types.Object, err error) {
@@ -435,12 +710,14 @@ func ParseUserCredential(credential warpgate.UserAuthCredential) (result types.O
 	}
 
 	value := map[string]attr.Value{
-		"kind":       types.StringNull(),
-		"hash":       types.StringNull(),
-		"email":      types.StringNull(),
-		"provider":   types.StringNull(),
-		"public_key": types.StringNull(),
-		"totp_key":   types.ListNull(types.Int64Type),
+		"kind":               types.StringNull(),
+		"hash":               types.StringNull(),
+		"email":              types.StringNull(),
+		"provider":           types.StringNull(),
+		"public_key":         sshkeys.NewPublicKeyNull(),
+		"fingerprint_sha256": types.StringNull(),
+		"key_type":           types.StringNull(),
+		"totp_key":           types.ListNull(types.Int64Type),
 	}
 
 	switch discriminator {
@@ -462,7 +739,12 @@ func ParseUserCredential(credential warpgate.UserAuthCredential) (result types.O
 		}
 
 		value["kind"] = types.StringValue(auth.Kind)
-		value["public_key"] = types.StringValue(auth.Key)
+		value["public_key"] = sshkeys.NewPublicKeyValue(auth.Key)
+
+		if fingerprint, keyType, err := sshkeys.Fingerprint(auth.Key); err == nil {
+			value["fingerprint_sha256"] = types.StringValue(fingerprint)
+			value["key_type"] = types.StringValue(keyType)
+		}
 
 	case "Sso":
 		auth, err := credential.AsUserAuthCredentialUserSsoCredential()
@@ -495,7 +777,10 @@ func ParseUserCredential(credential warpgate.UserAuthCredential) (result types.O
 	return
 }
 
-func ParseUser(user *warpgate.User) (result *provider_models.User, err error) {
+// ParseUser maps a warpgate user to the model, with the credentials in
+// credentials. The typed attributes are set by setTypedUserCredentials, which
+// fails for the users with more than one password or totp.
+func ParseUser(ctx context.Context, user *warpgate.User) (result *provider_models.User, err error) {
 
 	result = &provider_models.User{
 		Id:       types.StringValue(user.Id.String()),
@@ -524,6 +809,75 @@ func ParseUser(user *warpgate.User) (result *provider_models.User, err error) {
 	return
 }
 
+// setTypedUserCredentials sets password, totp, sso and public_keys of user
+// from credentials. password and totp hold a single credential, an error is
+// returned when warpgate has more than one of them: keeping only one in the
+// state would remove the others at the next update.
+// The empty sets are null unless they are empty in the prior state.
+func setTypedUserCredentials(ctx context.Context, user *provider_models.User, credentials types.Set, prior provider_models.User) error {
+	var current []provider_models.UserAuthCredential
+
+	if !credentials.IsNull() {
+		if diags := credentials.ElementsAs(ctx, &current, false); diags.HasError() {
+			return fmt.Errorf("error reading credentials: %v", diags)
+		}
+	}
+
+	var password *provider_models.UserPasswordCredential
+	var totp *provider_models.UserTotpCredential
+	sso := []attr.Value{}
+	publicKeys := []attr.Value{}
+
+	for _, credential := range current {
+		switch credential.Kind.ValueString() {
+		case string(warpgate.Password):
+			if password != nil {
+				return errors.New("the user has more than one Password credential, which the password attribute cannot hold: " +
+					"manage them with credentials or with warpgate_user_password")
+			}
+
+			password = &provider_models.UserPasswordCredential{Hash: credential.Hash}
+
+		case string(warpgate.Totp):
+			if totp != nil {
+				return errors.New("the user has more than one Totp credential, which the totp attribute cannot hold: " +
+					"manage them with credentials or with warpgate_user_totp")
+			}
+
+			totp = &provider_models.UserTotpCredential{Key: credential.TotpKey}
+
+		case string(warpgate.Sso):
+			sso = append(sso, types.ObjectValueMust(ssoCredentialAttributes, map[string]attr.Value{
+				"email":    credential.Email,
+				"provider": credential.Provider,
+			}))
+
+		case string(warpgate.PublicKey):
+			publicKeys = append(publicKeys, credential.PublicKey)
+		}
+	}
+
+	user.Password = password
+	user.Totp = totp
+
+	if len(sso) == 0 && (prior.Sso.IsNull() || prior.Sso.IsUnknown()) {
+		user.Sso = types.SetNull(types.ObjectType{AttrTypes: ssoCredentialAttributes})
+	} else {
+		user.Sso = types.SetValueMust(types.ObjectType{AttrTypes: ssoCredentialAttributes}, sso)
+	}
+
+	if len(publicKeys) == 0 && (prior.PublicKeys.IsNull() || prior.PublicKeys.IsUnknown()) {
+		user.PublicKeys = types.SetNull(sshkeys.PublicKeyType{})
+	} else {
+		user.PublicKeys = types.SetValueMust(sshkeys.PublicKeyType{}, publicKeys)
+	}
+
+	return nil
+}
+
+// GenerateWarpgateUserAuthCredentials maps the credentials of the user, set
+// either with credentials or with the typed attributes, and the write-only
+// ones to the warpgate api.
 func GenerateWarpgateUserAuthCredentials(ctx context.Context, user provider_models.User) (result []warpgate.UserAuthCredential) {
 
 	credentials, err := user.CredentialsAsArray(ctx)
@@ -579,5 +933,218 @@ func GenerateWarpgateUserAuthCredentials(ctx context.Context, user provider_mode
 		result = append(result, credential)
 	}
 
+	if !user.PasswordHashWo.IsNull() && !user.PasswordHashWo.IsUnknown() {
+		var credential warpgate.UserAuthCredential
+
+		credential.FromUserAuthCredentialUserPasswordCredential(
+			warpgate.UserAuthCredentialUserPasswordCredential{
+				Kind: string(warpgate.Password),
+				Hash: user.PasswordHashWo.ValueString(),
+			},
+		)
+
+		result = append(result, credential)
+	}
+
+	if !user.TotpKeyWo.IsNull() && !user.TotpKeyWo.IsUnknown() {
+		var credential warpgate.UserAuthCredential
+
+		credential.FromUserAuthCredentialUserTotpCredential(
+			warpgate.UserAuthCredentialUserTotpCredential{
+				Kind: string(warpgate.Totp),
+				Key:  TerraformListToArrayOfUint16(user.TotpKeyWo),
+			},
+		)
+
+		result = append(result, credential)
+	}
+
+	return
+}
+
+// userCredentialsRemoved reports if any credential of the prior state is not
+// planned anymore, including the write-only ones replaced by a new version.
+func userCredentialsRemoved(ctx context.Context, prior provider_models.User, planned provider_models.User) (bool, error) {
+	if !prior.PasswordHashWoVersion.IsNull() && !prior.PasswordHashWoVersion.Equal(planned.PasswordHashWoVersion) {
+		return true, nil
+	}
+
+	if !prior.TotpKeyWoVersion.IsNull() && !prior.TotpKeyWoVersion.Equal(planned.TotpKeyWoVersion) {
+		return true, nil
+	}
+
+	priorCredentials, err := prior.CredentialsAsArray(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	plannedCredentials, err := planned.CredentialsAsArray(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, priorCredential := range priorCredentials {
+		if !slices.ContainsFunc(plannedCredentials, func(credential provider_models.UserAuthCredential) bool {
+			return credential.Kind.Equal(priorCredential.Kind) &&
+				credential.Hash.Equal(priorCredential.Hash) &&
+				credential.Email.Equal(priorCredential.Email) &&
+				credential.Provider.Equal(priorCredential.Provider) &&
+				(credential.PublicKey.Equal(priorCredential.PublicKey) ||
+					sshkeys.Equal(credential.PublicKey.ValueString(), priorCredential.PublicKey.ValueString())) &&
+				credential.TotpKey.Equal(priorCredential.TotpKey)
+		}) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// getUserWriteOnlyAttributes copies the write-only attributes, that are
+// always null in the plan, from the configuration.
+func getUserWriteOnlyAttributes(ctx context.Context, config tfsdk.Config, user *provider_models.User) (diags diag.Diagnostics) {
+	diags.Append(config.GetAttribute(ctx, path.Root("password_hash_wo"), &user.PasswordHashWo)...)
+	diags.Append(config.GetAttribute(ctx, path.Root("totp_key_wo"), &user.TotpKeyWo)...)
+
 	return
 }
+
+// clearUserWriteOnlyAttributes nulls the write-only attributes before the
+// user is saved into the state.
+func clearUserWriteOnlyAttributes(user *provider_models.User) {
+	user.PasswordHashWo = types.StringNull()
+	user.TotpKeyWo = types.ListNull(types.Int64Type)
+}
+
+// withoutWriteOnlyCredentials removes from the credentials read from warpgate
+// the ones set with password_hash_wo and totp_key_wo, i.e. the Password and
+// Totp credentials that are not in the prior state.
+func withoutWriteOnlyCredentials(ctx context.Context, credentials types.Set, prior provider_models.User) (result types.Set, diags diag.Diagnostics) {
+	if prior.PasswordHashWoVersion.IsNull() && prior.TotpKeyWoVersion.IsNull() {
+		return credentials, nil
+	}
+
+	priorCredentials, err := prior.CredentialsAsArray(ctx)
+
+	if err != nil {
+		diags.AddError("Failed to read the credentials of the state", err.Error())
+		return credentials, diags
+	}
+
+	isInPriorState := func(credential provider_models.UserAuthCredential) bool {
+		for _, priorCredential := range priorCredentials {
+			if priorCredential.Kind.Equal(credential.Kind) &&
+				priorCredential.Hash.Equal(credential.Hash) &&
+				priorCredential.TotpKey.Equal(credential.TotpKey) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var current []provider_models.UserAuthCredential
+
+	if !credentials.IsNull() {
+		diags.Append(credentials.ElementsAs(ctx, &current, false)...)
+
+		if diags.HasError() {
+			return credentials, diags
+		}
+	}
+
+	filtered := []provider_models.UserAuthCredential{}
+
+	for _, credential := range current {
+		kind := credential.Kind.ValueString()
+
+		if kind == string(warpgate.Password) && !prior.PasswordHashWoVersion.IsNull() && !isInPriorState(credential) {
+			continue
+		}
+
+		if kind == string(warpgate.Totp) && !prior.TotpKeyWoVersion.IsNull() && !isInPriorState(credential) {
+			continue
+		}
+
+		filtered = append(filtered, credential)
+	}
+
+	if len(filtered) == 0 && prior.Credentials.IsNull() {
+		return types.SetNull(types.ObjectType{AttrTypes: credentialsAttributes}), diags
+	}
+
+	result, setDiags := types.SetValueFrom(ctx, types.ObjectType{AttrTypes: credentialsAttributes}, filtered)
+	diags.Append(setDiags...)
+
+	return result, diags
+}
+
+// userCredentialsPlanModifier computes the fingerprint and the type of the
+// public keys. Keys that differ only in comment or whitespace are handled by
+// the semantic equality of sshkeys.PublicKeyType.
+type userCredentialsPlanModifier struct{}
+
+func (m userCredentialsPlanModifier) Description(ctx context.Context) string {
+	return "Computes the fingerprint and the type of the public keys."
+}
+
+func (m userCredentialsPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m userCredentialsPlanModifier) PlanModifySet(ctx context.Context, req planmodifier.SetRequest, resp *planmodifier.SetResponse) {
+	if req.PlanValue.IsNull() || req.PlanValue.IsUnknown() {
+		return
+	}
+
+	for _, element := range req.PlanValue.Elements() {
+		if element.IsUnknown() {
+			return
+		}
+	}
+
+	var planned []provider_models.UserAuthCredential
+
+	resp.Diagnostics.Append(req.PlanValue.ElementsAs(ctx, &planned, false)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for i, credential := range planned {
+		planned[i] = planUserCredential(credential)
+	}
+
+	planValue, diags := types.SetValueFrom(ctx, req.PlanValue.ElementType(ctx), planned)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.PlanValue = planValue
+}
+
+func planUserCredential(credential provider_models.UserAuthCredential) provider_models.UserAuthCredential {
+	if credential.Kind.IsUnknown() || credential.PublicKey.IsUnknown() {
+		credential.FingerprintSha256 = types.StringUnknown()
+		credential.KeyType = types.StringUnknown()
+		return credential
+	}
+
+	credential.FingerprintSha256 = types.StringNull()
+	credential.KeyType = types.StringNull()
+
+	if credential.Kind.ValueString() != string(warpgate.PublicKey) || credential.PublicKey.IsNull() {
+		return credential
+	}
+
+	if fingerprint, keyType, err := sshkeys.Fingerprint(credential.PublicKey.ValueString()); err == nil {
+		credential.FingerprintSha256 = types.StringValue(fingerprint)
+		credential.KeyType = types.StringValue(keyType)
+	}
+
+	return credential
+}
@@ -0,0 +1,303 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &userPasswordResource{}
+var _ resource.ResourceWithUpgradeState = &userPasswordResource{}
+
+// userPasswordStateMigrations upgrades the state of the older schema versions
+// of the user password resource, see NewStateUpgraders.
+var userPasswordStateMigrations = []StateMigration{}
+
+func (r userPasswordResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version:     SchemaVersion(userPasswordStateMigrations),
+		Description: "A `Password` credential of a user, added and removed without changing the other credentials.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Id of the credential in warpgate",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_id": userCredentialUserIdAttribute(),
+			"password": schema.StringAttribute{
+				Required:    true,
+				Sensitive:   true,
+				Description: "The password of the user, hashed by warpgate or, on the servers without the per-credential endpoints, by the provider.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"hash": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The hash of the password sent to the servers without the per-credential endpoints, null otherwise.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+type userPasswordResource struct {
+	provider *warpgateProvider
+}
+
+func NewUserPasswordResource() resource.Resource {
+	return &userPasswordResource{}
+}
+
+func (r *userPasswordResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_password"
+}
+
+func (r *userPasswordResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*warpgateProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *warpgateProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !provider.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"Expected a configured provider but it wasn't. Please report this issue to the provider developers.",
+		)
+
+		return
+	}
+
+	r.provider = provider
+}
+
+func (r *userPasswordResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var resourceState provider_models.UserPasswordResource
+
+	diags := req.Plan.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := resourceState.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	userId, err := uuid.Parse(resourceState.UserId.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse user id.",
+			fmt.Sprintf("Invalid user id %s (Err: %s)", resourceState.UserId, err),
+		)
+		return
+	}
+
+	hash, err := newUserPasswordHash(resourceState.Password.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to hash password",
+			fmt.Sprintf("Failed to hash the password of user with id '%s'. (Error: %s)", resourceState.UserId, err),
+		)
+		return
+	}
+
+	resourceState.Hash = types.StringValue(hash)
+
+	id, supported, err := addUserCredential(ctx, r.provider.client, userId, userPasswordCredential(resourceState))
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to add password",
+			fmt.Sprintf("Failed to add password to user with id '%s'. (Error: %s)", resourceState.UserId, err),
+		)
+		return
+	}
+
+	resourceState.Id = types.StringValue(id.String())
+
+	if supported {
+		// hashed by warpgate
+		resourceState.Hash = types.StringNull()
+	}
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *userPasswordResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var resourceState provider_models.UserPasswordResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := resourceState.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	userId, id, ok := parseUserCredentialIds(resourceState.UserId, resourceState.Id, &resp.Diagnostics)
+
+	if !ok {
+		return
+	}
+
+	credential, err := findUserCredential(ctx, r.provider.client, userId, id, userPasswordCredential(resourceState))
+
+	if !readUserCredential(ctx, "password", resourceState.Id, credential, err, resp) {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update only changes the timeouts, any other change replaces the credential.
+func (r *userPasswordResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var resourcePlan provider_models.UserPasswordResource
+
+	diags := req.Plan.Get(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *userPasswordResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var resourceState provider_models.UserPasswordResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := resourceState.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	userId, id, ok := parseUserCredentialIds(resourceState.UserId, resourceState.Id, &resp.Diagnostics)
+
+	if !ok {
+		return
+	}
+
+	err := removeUserCredential(ctx, r.provider.client, userId, id, userPasswordCredential(resourceState))
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to remove password",
+			fmt.Sprintf("Failed to remove password with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		return
+	}
+}
+
+func (r *userPasswordResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return NewStateUpgraders(userPasswordStateMigrations)
+}
+
+func userPasswordCredential(password provider_models.UserPasswordResource) userCredential {
+	union := warpgate.UserAuthCredential{}
+	_ = union.FromUserAuthCredentialUserPasswordCredential(warpgate.UserAuthCredentialUserPasswordCredential{
+		Kind: string(warpgate.Password),
+		Hash: password.Hash.ValueString(),
+	})
+
+	return userCredential{
+		kind:  warpgate.Password,
+		new:   warpgate.NewUserCredential{Password: password.Password.ValueString()},
+		union: union,
+		matches: func(credential warpgate.UserAuthCredential) bool {
+			if !credentialOfKind(credential, warpgate.Password) || password.Hash.IsNull() {
+				return false
+			}
+
+			existing, err := credential.AsUserAuthCredentialUserPasswordCredential()
+			return err == nil && existing.Hash == password.Hash.ValueString()
+		},
+	}
+}
+
+// newUserPasswordHash hashes the password with a random salt, for the servers
+// without the per-credential endpoints that only accept hashes.
+func newUserPasswordHash(password string) (string, error) {
+	salt := make([]byte, 16)
+
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	return HashPassword(password, hex.EncodeToString(salt))
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccUserPasswordResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccUserPasswordResourceConfig("first password"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("warpgate_user_password.test", "id"),
+					resource.TestCheckResourceAttrPair("warpgate_user_password.test", "user_id", "warpgate_user.test", "id"),
+					resource.TestCheckResourceAttr("warpgate_user_password.test", "password", "first password"),
+				),
+			},
+			// Replace testing
+			{
+				Config: testAccUserPasswordResourceConfig("second password"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_user_password.test", "password", "second password"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccUserPasswordResourceConfig(password string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_user" "test" {
+	username = "password-credential"
+}
+
+resource "warpgate_user_password" "test" {
+	user_id  = warpgate_user.test.id
+	password = "%s"
+}
+`, password)
+}
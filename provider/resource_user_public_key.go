@@ -0,0 +1,302 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/provider/sshkeys"
+	"terraform-provider-warpgate/provider/validators"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &userPublicKeyResource{}
+var _ resource.ResourceWithUpgradeState = &userPublicKeyResource{}
+
+// userPublicKeyStateMigrations upgrades the state of the older schema versions
+// of the user public key resource, see NewStateUpgraders.
+var userPublicKeyStateMigrations = []StateMigration{}
+
+func (r userPublicKeyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version:     SchemaVersion(userPublicKeyStateMigrations),
+		Description: "A `PublicKey` credential of a user, added and removed without changing the other credentials.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Id of the credential in warpgate",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_id": userCredentialUserIdAttribute(),
+			"public_key": schema.StringAttribute{
+				Required:    true,
+				CustomType:  sshkeys.PublicKeyType{},
+				Description: "The ssh public key that the user uses to connect via ssh.",
+				Validators: []validator.String{
+					validators.IsSshPublicKey(),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(
+						userPublicKeyChanged,
+						"Changing the key replaces the credential, a change of the comment only updates the state.",
+						"Changing the key replaces the credential, a change of the comment only updates the state.",
+					),
+				},
+			},
+			"fingerprint_sha256": schema.StringAttribute{
+				Computed:    true,
+				Description: "The SHA256 fingerprint of the public key, as printed by `ssh-keygen -l`.",
+			},
+			"key_type": schema.StringAttribute{
+				Computed:    true,
+				Description: "The type of the public key (e.g. `ssh-ed25519`).",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+type userPublicKeyResource struct {
+	provider *warpgateProvider
+}
+
+func NewUserPublicKeyResource() resource.Resource {
+	return &userPublicKeyResource{}
+}
+
+func (r *userPublicKeyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_public_key"
+}
+
+func (r *userPublicKeyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*warpgateProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *warpgateProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !provider.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"Expected a configured provider but it wasn't. Please report this issue to the provider developers.",
+		)
+
+		return
+	}
+
+	r.provider = provider
+}
+
+func (r *userPublicKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var resourceState provider_models.UserPublicKeyResource
+
+	diags := req.Plan.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := resourceState.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	userId, err := uuid.Parse(resourceState.UserId.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse user id.",
+			fmt.Sprintf("Invalid user id %s (Err: %s)", resourceState.UserId, err),
+		)
+		return
+	}
+
+	id, _, err := addUserCredential(ctx, r.provider.client, userId, userPublicKeyCredential(resourceState))
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to add public key",
+			fmt.Sprintf("Failed to add public key to user with id '%s'. (Error: %s)", resourceState.UserId, err),
+		)
+		return
+	}
+
+	resourceState.Id = types.StringValue(id.String())
+	setUserPublicKeyFingerprint(&resourceState)
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *userPublicKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var resourceState provider_models.UserPublicKeyResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := resourceState.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	userId, id, ok := parseUserCredentialIds(resourceState.UserId, resourceState.Id, &resp.Diagnostics)
+
+	if !ok {
+		return
+	}
+
+	credential, err := findUserCredential(ctx, r.provider.client, userId, id, userPublicKeyCredential(resourceState))
+
+	if !readUserCredential(ctx, "public key", resourceState.Id, credential, err, resp) {
+		return
+	}
+
+	if credential.OpensshPublicKey != "" && !sshkeys.Equal(credential.OpensshPublicKey, resourceState.PublicKey.ValueString()) {
+		resourceState.PublicKey = sshkeys.NewPublicKeyValue(credential.OpensshPublicKey)
+		setUserPublicKeyFingerprint(&resourceState)
+	}
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update only changes the timeouts or the comment of the key in the state,
+// any other change replaces the credential.
+func (r *userPublicKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var resourcePlan provider_models.UserPublicKeyResource
+
+	diags := req.Plan.Get(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	setUserPublicKeyFingerprint(&resourcePlan)
+
+	diags = resp.State.Set(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *userPublicKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var resourceState provider_models.UserPublicKeyResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := resourceState.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	userId, id, ok := parseUserCredentialIds(resourceState.UserId, resourceState.Id, &resp.Diagnostics)
+
+	if !ok {
+		return
+	}
+
+	err := removeUserCredential(ctx, r.provider.client, userId, id, userPublicKeyCredential(resourceState))
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to remove public key",
+			fmt.Sprintf("Failed to remove public key with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		return
+	}
+}
+
+func (r *userPublicKeyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return NewStateUpgraders(userPublicKeyStateMigrations)
+}
+
+func userPublicKeyCredential(publicKey provider_models.UserPublicKeyResource) userCredential {
+	union := warpgate.UserAuthCredential{}
+	_ = union.FromUserAuthCredentialUserPublicKeyCredential(warpgate.UserAuthCredentialUserPublicKeyCredential{
+		Kind: string(warpgate.PublicKey),
+		Key:  publicKey.PublicKey.ValueString(),
+	})
+
+	return userCredential{
+		kind:  warpgate.PublicKey,
+		new:   warpgate.NewUserCredential{OpensshPublicKey: publicKey.PublicKey.ValueString()},
+		union: union,
+		matches: func(credential warpgate.UserAuthCredential) bool {
+			if !credentialOfKind(credential, warpgate.PublicKey) {
+				return false
+			}
+
+			existing, err := credential.AsUserAuthCredentialUserPublicKeyCredential()
+			return err == nil && sshkeys.Equal(existing.Key, publicKey.PublicKey.ValueString())
+		},
+	}
+}
+
+func setUserPublicKeyFingerprint(publicKey *provider_models.UserPublicKeyResource) {
+	publicKey.FingerprintSha256 = types.StringNull()
+	publicKey.KeyType = types.StringNull()
+
+	if fingerprint, keyType, err := sshkeys.Fingerprint(publicKey.PublicKey.ValueString()); err == nil {
+		publicKey.FingerprintSha256 = types.StringValue(fingerprint)
+		publicKey.KeyType = types.StringValue(keyType)
+	}
+}
+
+// userPublicKeyChanged requires the replacement of the credential only when
+// the key itself changes, not its comment or whitespace.
+func userPublicKeyChanged(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+	resp.RequiresReplace = !sshkeys.Equal(req.StateValue.ValueString(), req.PlanValue.ValueString())
+}
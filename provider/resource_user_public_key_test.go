@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccUserPublicKeyResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccUserPublicKeyResourceConfig(testUserPublicKeyA),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("warpgate_user_public_key.test", "id"),
+					resource.TestCheckResourceAttrPair("warpgate_user_public_key.test", "user_id", "warpgate_user.test", "id"),
+					resource.TestCheckResourceAttr("warpgate_user_public_key.test", "public_key", testUserPublicKeyA),
+					resource.TestCheckResourceAttr("warpgate_user_public_key.test", "key_type", "ssh-ed25519"),
+					resource.TestCheckNoResourceAttr("warpgate_user.test", "credentials.#"),
+				),
+			},
+			// The credentials added by the resource are kept by the user
+			{
+				Config:   testAccUserPublicKeyResourceConfig(testUserPublicKeyA),
+				PlanOnly: true,
+			},
+			// Replace testing
+			{
+				Config: testAccUserPublicKeyResourceConfig(testUserPublicKeyB),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_user_public_key.test", "public_key", testUserPublicKeyB),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccUserPublicKeyResourceConfig(publicKey string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_user" "test" {
+	username = "public-key-credential"
+}
+
+resource "warpgate_user_public_key" "test" {
+	user_id    = warpgate_user.test.id
+	public_key = "%s"
+}
+`, publicKey)
+}
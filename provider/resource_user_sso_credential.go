@@ -0,0 +1,293 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &userSsoCredentialResource{}
+var _ resource.ResourceWithUpgradeState = &userSsoCredentialResource{}
+var _ resource.ResourceWithModifyPlan = &userSsoCredentialResource{}
+
+// userSsoCredentialStateMigrations upgrades the state of the older schema versions
+// of the user sso credential resource, see NewStateUpgraders.
+var userSsoCredentialStateMigrations = []StateMigration{}
+
+func (r userSsoCredentialResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version:     SchemaVersion(userSsoCredentialStateMigrations),
+		Description: "A `Sso` credential of a user, added and removed without changing the other credentials.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Id of the credential in warpgate",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_id": userCredentialUserIdAttribute(),
+			"email": schema.StringAttribute{
+				Required:    true,
+				Description: "The email of the user in the sso system.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"sso_provider": schema.StringAttribute{
+				Optional:    true,
+				Description: "The sso provider name defined in the configuration file.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+type userSsoCredentialResource struct {
+	provider *warpgateProvider
+}
+
+func NewUserSsoCredentialResource() resource.Resource {
+	return &userSsoCredentialResource{}
+}
+
+func (r *userSsoCredentialResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_sso_credential"
+}
+
+func (r *userSsoCredentialResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*warpgateProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *warpgateProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !provider.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"Expected a configured provider but it wasn't. Please report this issue to the provider developers.",
+		)
+
+		return
+	}
+
+	r.provider = provider
+}
+
+func (r *userSsoCredentialResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var resourceState provider_models.UserSsoCredentialResource
+
+	diags := req.Plan.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := resourceState.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	userId, err := uuid.Parse(resourceState.UserId.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse user id.",
+			fmt.Sprintf("Invalid user id %s (Err: %s)", resourceState.UserId, err),
+		)
+		return
+	}
+
+	id, _, err := addUserCredential(ctx, r.provider.client, userId, userSsoCredential(resourceState))
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to add sso credential",
+			fmt.Sprintf("Failed to add sso credential to user with id '%s'. (Error: %s)", resourceState.UserId, err),
+		)
+		return
+	}
+
+	resourceState.Id = types.StringValue(id.String())
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *userSsoCredentialResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var resourceState provider_models.UserSsoCredentialResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := resourceState.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	userId, id, ok := parseUserCredentialIds(resourceState.UserId, resourceState.Id, &resp.Diagnostics)
+
+	if !ok {
+		return
+	}
+
+	credential, err := findUserCredential(ctx, r.provider.client, userId, id, userSsoCredential(resourceState))
+
+	if !readUserCredential(ctx, "sso credential", resourceState.Id, credential, err, resp) {
+		return
+	}
+
+	if credential.Email != "" {
+		resourceState.Email = types.StringValue(credential.Email)
+		resourceState.Provider = types.StringPointerValue(credential.Provider)
+	}
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update only changes the timeouts, any other change replaces the credential.
+func (r *userSsoCredentialResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var resourcePlan provider_models.UserSsoCredentialResource
+
+	diags := req.Plan.Get(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *userSsoCredentialResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var resourceState provider_models.UserSsoCredentialResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := resourceState.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	userId, id, ok := parseUserCredentialIds(resourceState.UserId, resourceState.Id, &resp.Diagnostics)
+
+	if !ok {
+		return
+	}
+
+	err := removeUserCredential(ctx, r.provider.client, userId, id, userSsoCredential(resourceState))
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to remove sso credential",
+			fmt.Sprintf("Failed to remove sso credential with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		return
+	}
+}
+
+// ModifyPlan checks that the sso_provider is configured on the server.
+func (r *userSsoCredentialResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.provider == nil {
+		return
+	}
+
+	var resourcePlan provider_models.UserSsoCredentialResource
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &resourcePlan)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(newSsoProviderCheck(r.provider.client).check(ctx, path.Root("sso_provider"), resourcePlan.Email, resourcePlan.Provider)...)
+}
+
+func (r *userSsoCredentialResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return NewStateUpgraders(userSsoCredentialStateMigrations)
+}
+
+func userSsoCredential(sso provider_models.UserSsoCredentialResource) userCredential {
+	provider := sso.Provider.ValueStringPointer()
+
+	union := warpgate.UserAuthCredential{}
+	_ = union.FromUserAuthCredentialUserSsoCredential(warpgate.UserAuthCredentialUserSsoCredential{
+		Kind:     string(warpgate.Sso),
+		Email:    sso.Email.ValueString(),
+		Provider: provider,
+	})
+
+	return userCredential{
+		kind:  warpgate.Sso,
+		new:   warpgate.NewUserCredential{Email: sso.Email.ValueString(), Provider: provider},
+		union: union,
+		matches: func(credential warpgate.UserAuthCredential) bool {
+			if !credentialOfKind(credential, warpgate.Sso) {
+				return false
+			}
+
+			existing, err := credential.AsUserAuthCredentialUserSsoCredential()
+			return err == nil && existing.Email == sso.Email.ValueString() &&
+				types.StringPointerValue(existing.Provider).Equal(types.StringPointerValue(provider))
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccUserSsoCredentialResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccUserSsoCredentialResourceConfig("alice@example.com", "google"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("warpgate_user_sso_credential.test", "id"),
+					resource.TestCheckResourceAttrPair("warpgate_user_sso_credential.test", "user_id", "warpgate_user.test", "id"),
+					resource.TestCheckResourceAttr("warpgate_user_sso_credential.test", "email", "alice@example.com"),
+					resource.TestCheckResourceAttr("warpgate_user_sso_credential.test", "sso_provider", "google"),
+				),
+			},
+			// Replace testing
+			{
+				Config: testAccUserSsoCredentialResourceConfig("alice@example.org", "google"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_user_sso_credential.test", "email", "alice@example.org"),
+				),
+			},
+			// Unknown provider testing
+			{
+				Config:      testAccUserSsoCredentialResourceConfig("alice@example.org", "gogle"),
+				ExpectError: regexp.MustCompile(`Unknown sso provider`),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccUserSsoCredentialResourceConfig(email string, ssoProvider string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_user" "test" {
+	username = "sso-credential"
+}
+
+resource "warpgate_user_sso_credential" "test" {
+	user_id      = warpgate_user.test.id
+	email        = "%s"
+	sso_provider = "%s"
+}
+`, email, ssoProvider)
+}
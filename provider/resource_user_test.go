@@ -1,15 +1,30 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/provider/sshkeys"
+	"terraform-provider-warpgate/warpgate"
 	"testing"
 
 	"github.com/bxcodec/faker/v4"
 	"github.com/bxcodec/faker/v4/pkg/options"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 )
 
+const (
+	testUserPublicKeyA = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAUHVLLcZmjfhjMTZk8J5q/RZn8+htJakhvTikkAt+5f user-a@example.com"
+	testUserPublicKeyB = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIN1Y1xyNh61UymYLaCp3Q/oq3WdwuQhWYmwztxBdH+Jx user-b@example.com"
+	testUserPublicKeyC = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIP1tnpjN/c3Sg8VhAKqZVGNjQvFWTuI1H1t8F+MwvzNF user-c@example.com"
+	// testUserPublicKeyC with another comment
+	testUserPublicKeyCLaptop = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIP1tnpjN/c3Sg8VhAKqZVGNjQvFWTuI1H1t8F+MwvzNF laptop"
+)
+
 func TestAccUserResource(t *testing.T) {
 
 	type Data struct {
@@ -46,13 +61,17 @@ func TestAccUserResource(t *testing.T) {
 					}),
 
 					resource.TestCheckTypeSetElemNestedAttrs("warpgate_user.test", "credentials.*", map[string]string{
-						"kind":       "PublicKey",
-						"public_key": "AAAAAAAAAAA",
+						"kind":               "PublicKey",
+						"public_key":         testUserPublicKeyA,
+						"fingerprint_sha256": "SHA256:ug9edX55FAfpkL8yPrXtiYNjuwRUJ4XfNG2wfCA53SY",
+						"key_type":           "ssh-ed25519",
 					}),
 
 					resource.TestCheckTypeSetElemNestedAttrs("warpgate_user.test", "credentials.*", map[string]string{
-						"kind":       "PublicKey",
-						"public_key": "BBBBBBBBBBB",
+						"kind":               "PublicKey",
+						"public_key":         testUserPublicKeyB,
+						"fingerprint_sha256": "SHA256:8UgDb9dst95avQCi62hWm8QU0ZsDRkXApT3ZnJuV9kk",
+						"key_type":           "ssh-ed25519",
 					}),
 
 					resource.TestCheckTypeSetElemNestedAttrs("warpgate_user.test", "credentials.*", map[string]string{
@@ -75,6 +94,13 @@ func TestAccUserResource(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			// ImportState by name testing
+			{
+				ResourceName:      "warpgate_user.test",
+				ImportState:       true,
+				ImportStateId:     "username:one",
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: testAccUserResourceConfig("two", totp_key_string),
@@ -95,13 +121,17 @@ func TestAccUserResource(t *testing.T) {
 					}),
 
 					resource.TestCheckTypeSetElemNestedAttrs("warpgate_user.test", "credentials.*", map[string]string{
-						"kind":       "PublicKey",
-						"public_key": "AAAAAAAAAAA",
+						"kind":               "PublicKey",
+						"public_key":         testUserPublicKeyA,
+						"fingerprint_sha256": "SHA256:ug9edX55FAfpkL8yPrXtiYNjuwRUJ4XfNG2wfCA53SY",
+						"key_type":           "ssh-ed25519",
 					}),
 
 					resource.TestCheckTypeSetElemNestedAttrs("warpgate_user.test", "credentials.*", map[string]string{
-						"kind":       "PublicKey",
-						"public_key": "BBBBBBBBBBB",
+						"kind":               "PublicKey",
+						"public_key":         testUserPublicKeyB,
+						"fingerprint_sha256": "SHA256:8UgDb9dst95avQCi62hWm8QU0ZsDRkXApT3ZnJuV9kk",
+						"key_type":           "ssh-ed25519",
 					}),
 
 					resource.TestCheckTypeSetElemNestedAttrs("warpgate_user.test", "credentials.*", map[string]string{
@@ -118,6 +148,29 @@ func TestAccUserResource(t *testing.T) {
 					testCheckFuncValidUUID("warpgate_user.test", "id"),
 				),
 			},
+			// Changing only the comment of a public key keeps the configured key
+			// in the state, whatever warpgate returns
+			{
+				Config: strings.NewReplacer(
+					"user-a@example.com", "laptop",
+					"user-b@example.com\"", "\\n\"",
+				).Replace(testAccUserResourceConfig("two", totp_key_string)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("warpgate_user.test", "credentials.*", map[string]string{
+						"kind":               "PublicKey",
+						"public_key":         strings.Replace(testUserPublicKeyA, "user-a@example.com", "laptop", 1),
+						"fingerprint_sha256": "SHA256:ug9edX55FAfpkL8yPrXtiYNjuwRUJ4XfNG2wfCA53SY",
+					}),
+				),
+			},
+			// and no diff is planned after the refresh
+			{
+				Config: strings.NewReplacer(
+					"user-a@example.com", "laptop",
+					"user-b@example.com\"", "\\n\"",
+				).Replace(testAccUserResourceConfig("two", totp_key_string)),
+				PlanOnly: true,
+			},
 			// Update and Read testing
 			{
 				Config: testAccUserUpdateRemoveCredentialsResourceConfig("two", totp_key_string),
@@ -132,8 +185,10 @@ func TestAccUserResource(t *testing.T) {
 					}),
 
 					resource.TestCheckTypeSetElemNestedAttrs("warpgate_user.test", "credentials.*", map[string]string{
-						"kind":       "PublicKey",
-						"public_key": "CCCCCCCCCCC",
+						"kind":               "PublicKey",
+						"public_key":         testUserPublicKeyC,
+						"fingerprint_sha256": "SHA256:a63jb8HWQQdikzbXMUgg03snkTgBP+CGWjOfkLFsBrM",
+						"key_type":           "ssh-ed25519",
 					}),
 
 					resource.TestCheckTypeSetElemNestedAttrs("warpgate_user.test", "credentials.*", map[string]string{
@@ -155,6 +210,135 @@ func TestAccUserResource(t *testing.T) {
 	})
 }
 
+func TestAccUserWriteOnlyCredentialsResource(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing, the write-only credentials are not in the state
+			{
+				Config: testAccUserWriteOnlyCredentialsResourceConfig("one", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_user.test", "credentials.#", "1"),
+					resource.TestCheckResourceAttr("warpgate_user.test", "credentials.0.kind", "PublicKey"),
+					resource.TestCheckResourceAttr("warpgate_user.test", "password_hash_wo_version", "1"),
+					resource.TestCheckResourceAttr("warpgate_user.test", "totp_key_wo_version", "1"),
+					resource.TestCheckNoResourceAttr("warpgate_user.test", "password_hash_wo"),
+					resource.TestCheckNoResourceAttr("warpgate_user.test", "totp_key_wo.#"),
+				),
+			},
+			// No changes are planned for the credentials read from warpgate
+			{
+				Config:   testAccUserWriteOnlyCredentialsResourceConfig("one", 1),
+				PlanOnly: true,
+			},
+			// Update of the write-only credentials
+			{
+				Config: testAccUserWriteOnlyCredentialsResourceConfig("one", 2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_user.test", "credentials.#", "1"),
+					resource.TestCheckResourceAttr("warpgate_user.test", "password_hash_wo_version", "2"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccUserInlineRolesResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create with the roles assigned by the user resource
+			{
+				Config: testAccUserInlineRolesResourceConfig(`[warpgate_role.developers.name]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_user.test", "roles.#", "1"),
+					resource.TestCheckTypeSetElemAttr("warpgate_user.test", "roles.*", "inline-developers"),
+				),
+			},
+			// Update of the roles
+			{
+				Config: testAccUserInlineRolesResourceConfig(`[warpgate_role.ops.name]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_user.test", "roles.#", "1"),
+					resource.TestCheckTypeSetElemAttr("warpgate_user.test", "roles.*", "inline-ops"),
+				),
+			},
+			{
+				Config: testAccUserInlineRolesResourceConfig(`[]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_user.test", "roles.#", "0"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccUserTypedCredentialsResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccUserTypedCredentialsResourceConfig(testUserPublicKeyA, testUserPublicKeyB),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("warpgate_user.test", "credentials.#"),
+					resource.TestCheckResourceAttrSet("warpgate_user.test", "password.hash"),
+					resource.TestCheckResourceAttr("warpgate_user.test", "totp.key.#", "4"),
+					resource.TestCheckResourceAttr("warpgate_user.test", "sso.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs("warpgate_user.test", "sso.*", map[string]string{
+						"email":    "typed@example.com",
+						"provider": "google",
+					}),
+					resource.TestCheckResourceAttr("warpgate_user.test", "public_keys.#", "2"),
+					resource.TestCheckTypeSetElemAttr("warpgate_user.test", "public_keys.*", testUserPublicKeyA),
+				),
+			},
+			// No changes are planned for the credentials read from warpgate
+			{
+				Config:   testAccUserTypedCredentialsResourceConfig(testUserPublicKeyA, testUserPublicKeyB),
+				PlanOnly: true,
+			},
+			// Update of a single public key
+			{
+				Config: testAccUserTypedCredentialsResourceConfig(testUserPublicKeyA, testUserPublicKeyC),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_user.test", "public_keys.#", "2"),
+					resource.TestCheckTypeSetElemAttr("warpgate_user.test", "public_keys.*", testUserPublicKeyC),
+					resource.TestCheckResourceAttr("warpgate_user.test", "sso.#", "1"),
+				),
+			},
+			// Changing only the comment of a public key keeps the configured key
+			{
+				Config: testAccUserTypedCredentialsResourceConfig(testUserPublicKeyA, testUserPublicKeyCLaptop),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_user.test", "public_keys.#", "2"),
+					resource.TestCheckTypeSetElemAttr("warpgate_user.test", "public_keys.*", testUserPublicKeyCLaptop),
+				),
+			},
+			// and no diff is planned after the refresh
+			{
+				Config:   testAccUserTypedCredentialsResourceConfig(testUserPublicKeyA, testUserPublicKeyCLaptop),
+				PlanOnly: true,
+			},
+			// the import reads the credentials into credentials, the first
+			// apply moves them to the typed attributes
+			{
+				ResourceName:            "warpgate_user.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"credentials", "password", "totp", "sso", "public_keys"},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
 func testAccUserResourceConfig(name string, totp_key string) string {
 
 	return fmt.Sprintf(`
@@ -166,20 +350,20 @@ resource "warpgate_user" "test" {
 		{
 			kind = "Sso"
 			email = "test@example.com"
-			provider = "" // requires a provider added in the yaml file
+			provider = "google" // requires the provider in the yaml file, see testacc_setup.sh
 		},
 		{
 			kind = "Sso"
 			email = "test2@example.com"
-			provider = "" // requires a provider added in the yaml file
+			provider = "google" // requires the provider in the yaml file, see testacc_setup.sh
 		},
 		{
 			kind = "PublicKey"
-			public_key = "AAAAAAAAAAA"
+			public_key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAUHVLLcZmjfhjMTZk8J5q/RZn8+htJakhvTikkAt+5f user-a@example.com"
 		},
 		{
 			kind = "PublicKey"
-			public_key = "BBBBBBBBBBB"
+			public_key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIN1Y1xyNh61UymYLaCp3Q/oq3WdwuQhWYmwztxBdH+Jx user-b@example.com"
 		},
 		{
 			kind = "Password"
@@ -209,11 +393,11 @@ resource "warpgate_user" "test" {
 		{
 			kind = "Sso"
 			email = "test@example.com"
-			provider = "" // requires a provider added in the yaml file
+			provider = "google" // requires the provider in the yaml file, see testacc_setup.sh
 		},
 		{
 			kind = "PublicKey"
-			public_key = "CCCCCCCCCCC"
+			public_key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIP1tnpjN/c3Sg8VhAKqZVGNjQvFWTuI1H1t8F+MwvzNF user-c@example.com"
 		},
 		{
 			kind = "Password"
@@ -223,3 +407,346 @@ resource "warpgate_user" "test" {
 }
 `, name, totp_key)
 }
+
+func testAccUserInlineRolesResourceConfig(roles string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_role" "developers" {
+	name = "inline-developers"
+}
+
+resource "warpgate_role" "ops" {
+	name = "inline-ops"
+}
+
+resource "warpgate_user" "test" {
+	username = "inline-roles"
+	roles    = %s
+	credentials = [
+		{
+			kind = "PublicKey"
+			public_key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAUHVLLcZmjfhjMTZk8J5q/RZn8+htJakhvTikkAt+5f user-a@example.com"
+		}
+	]
+}
+`, roles)
+}
+
+func testAccUserTypedCredentialsResourceConfig(publicKeys ...string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_user" "test" {
+	username = "typed-credentials"
+
+	password = {
+		hash = "$argon2id$v=19$m=65536,t=1,p=2$5rAIZSCP/YX+JM8m7mo4gQ$TSGk41+4MOzCPbDOjB2AdU18Mz57Df4hmWyNjoilu7k"
+	}
+
+	totp = {
+		key = [1, 2, 3, 4]
+	}
+
+	sso = [{
+		email    = "typed@example.com"
+		provider = "google"
+	}]
+
+	public_keys = ["%s"]
+}
+`, strings.Join(publicKeys, `", "`))
+}
+
+func testAccUserWriteOnlyCredentialsResourceConfig(name string, version int) string {
+
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_user" "test" {
+	username = "%s"
+	credentials = [
+		{
+			kind = "PublicKey"
+			public_key = "%s"
+		}
+	]
+	password_hash_wo = "$argon2id$v=19$m=65536,t=1,p=2$5rAIZSCP/YX+JM8m7mo4gQ$TSGk41+4MOzCPbDOjB2AdU18Mz57Df4hmWyNjoilu7k"
+	password_hash_wo_version = %d
+	totp_key_wo = [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+	totp_key_wo_version = %d
+}
+`, name, testUserPublicKeyA, version, version)
+}
+
+func TestWithoutWriteOnlyCredentials(t *testing.T) {
+	ctx := context.Background()
+	credentialType := types.ObjectType{AttrTypes: credentialsAttributes}
+
+	password := func(hash string) provider_models.UserAuthCredential {
+		return provider_models.UserAuthCredential{
+			Kind:              types.StringValue("Password"),
+			Hash:              types.StringValue(hash),
+			Email:             types.StringNull(),
+			Provider:          types.StringNull(),
+			TotpKey:           types.ListNull(types.Int64Type),
+			PublicKey:         sshkeys.NewPublicKeyNull(),
+			FingerprintSha256: types.StringNull(),
+			KeyType:           types.StringNull(),
+		}
+	}
+
+	credentialsSet := func(credentials ...provider_models.UserAuthCredential) types.Set {
+		set, diags := types.SetValueFrom(ctx, credentialType, credentials)
+
+		if diags.HasError() {
+			t.Fatal(diags)
+		}
+
+		return set
+	}
+
+	read := credentialsSet(password("configured"), password("write-only"))
+
+	prior := provider_models.User{
+		Credentials:           credentialsSet(password("configured")),
+		PasswordHashWoVersion: types.Int64Value(1),
+		TotpKeyWoVersion:      types.Int64Null(),
+	}
+
+	result, diags := withoutWriteOnlyCredentials(ctx, read, prior)
+
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	if !result.Equal(credentialsSet(password("configured"))) {
+		t.Errorf("expected only the configured password, got %v", result)
+	}
+
+	// without write-only credentials nothing is removed
+	prior.PasswordHashWoVersion = types.Int64Null()
+
+	result, diags = withoutWriteOnlyCredentials(ctx, read, prior)
+
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	if !result.Equal(read) {
+		t.Errorf("expected all the credentials, got %v", result)
+	}
+}
+
+func TestUserCredentialsRemoved(t *testing.T) {
+	ctx := context.Background()
+
+	credential := func(kind string, hash string) attr.Value {
+		return types.ObjectValueMust(credentialsAttributes, map[string]attr.Value{
+			"kind":               types.StringValue(kind),
+			"hash":               types.StringValue(hash),
+			"email":              types.StringNull(),
+			"provider":           types.StringNull(),
+			"public_key":         sshkeys.NewPublicKeyNull(),
+			"fingerprint_sha256": types.StringNull(),
+			"key_type":           types.StringNull(),
+			"totp_key":           types.ListNull(types.Int64Type),
+		})
+	}
+
+	publicKey := func(key string) attr.Value {
+		return types.ObjectValueMust(credentialsAttributes, map[string]attr.Value{
+			"kind":               types.StringValue("PublicKey"),
+			"hash":               types.StringNull(),
+			"email":              types.StringNull(),
+			"provider":           types.StringNull(),
+			"public_key":         sshkeys.NewPublicKeyValue(key),
+			"fingerprint_sha256": types.StringNull(),
+			"key_type":           types.StringNull(),
+			"totp_key":           types.ListNull(types.Int64Type),
+		})
+	}
+
+	user := func(version int64, credentials ...attr.Value) provider_models.User {
+		return provider_models.User{
+			Credentials:           types.SetValueMust(types.ObjectType{AttrTypes: credentialsAttributes}, credentials),
+			PasswordHashWoVersion: types.Int64Value(version),
+			TotpKeyWoVersion:      types.Int64Null(),
+		}
+	}
+
+	cases := map[string]struct {
+		prior    provider_models.User
+		planned  provider_models.User
+		expected bool
+	}{
+		"unchanged":  {user(1, credential("Password", "a")), user(1, credential("Password", "a")), false},
+		"added":      {user(1, credential("Password", "a")), user(1, credential("Password", "a"), credential("Password", "b")), false},
+		"removed":    {user(1, credential("Password", "a"), credential("Password", "b")), user(1, credential("Password", "a")), true},
+		"changed":    {user(1, credential("Password", "a")), user(1, credential("Password", "b")), true},
+		"write-only": {user(1, credential("Password", "a")), user(2, credential("Password", "a")), true},
+		"key comment": {
+			user(1, publicKey(testUserPublicKeyA)),
+			user(1, publicKey(strings.Replace(testUserPublicKeyA, "user-a@example.com", "laptop", 1))),
+			false,
+		},
+		"key changed": {user(1, publicKey(testUserPublicKeyA)), user(1, publicKey(testUserPublicKeyB)), true},
+	}
+
+	for name, c := range cases {
+		removed, err := userCredentialsRemoved(ctx, c.prior, c.planned)
+
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+
+		if removed != c.expected {
+			t.Errorf("%s: expected %t, got %t", name, c.expected, removed)
+		}
+	}
+}
+
+func TestTypedUserCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	user := provider_models.User{
+		Credentials: types.SetNull(types.ObjectType{AttrTypes: credentialsAttributes}),
+		Password:    &provider_models.UserPasswordCredential{Hash: types.StringValue("hash")},
+		Totp:        &provider_models.UserTotpCredential{Key: ArrayOfUint16ToTerraformList([]uint16{1, 2, 3})},
+		Sso: types.SetValueMust(types.ObjectType{AttrTypes: ssoCredentialAttributes}, []attr.Value{
+			types.ObjectValueMust(ssoCredentialAttributes, map[string]attr.Value{
+				"email":    types.StringValue("alice@example.com"),
+				"provider": types.StringValue("google"),
+			}),
+		}),
+		PublicKeys: types.SetValueMust(sshkeys.PublicKeyType{}, []attr.Value{
+			sshkeys.NewPublicKeyValue(testUserPublicKeyA),
+			sshkeys.NewPublicKeyValue(testUserPublicKeyB),
+		}),
+		PasswordHashWo:        types.StringNull(),
+		TotpKeyWo:             types.ListNull(types.Int64Type),
+		PasswordHashWoVersion: types.Int64Null(),
+		TotpKeyWoVersion:      types.Int64Null(),
+	}
+
+	credentials := GenerateWarpgateUserAuthCredentials(ctx, user)
+
+	if len(credentials) != 5 {
+		t.Fatalf("expected 5 credentials, got %d", len(credentials))
+	}
+
+	parsed, err := ParseUser(ctx, &warpgate.User{Username: "alice", Credentials: credentials})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(parsed.Credentials.Elements()) != 5 {
+		t.Errorf("expected 5 credentials, got %v", parsed.Credentials)
+	}
+
+	if err := setTypedUserCredentials(ctx, parsed, parsed.Credentials, provider_models.User{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Password == nil || !parsed.Password.Hash.Equal(user.Password.Hash) {
+		t.Errorf("expected password %v, got %v", user.Password, parsed.Password)
+	}
+
+	if parsed.Totp == nil || !parsed.Totp.Key.Equal(user.Totp.Key) {
+		t.Errorf("expected totp %v, got %v", user.Totp, parsed.Totp)
+	}
+
+	if !parsed.Sso.Equal(user.Sso) {
+		t.Errorf("expected sso %v, got %v", user.Sso, parsed.Sso)
+	}
+
+	if !parsed.PublicKeys.Equal(user.PublicKeys) {
+		t.Errorf("expected public keys %v, got %v", user.PublicKeys, parsed.PublicKeys)
+	}
+
+	// a single password attribute cannot hold many passwords
+	credentials = GenerateWarpgateUserAuthCredentials(ctx, provider_models.User{
+		Credentials: types.SetNull(types.ObjectType{AttrTypes: credentialsAttributes}),
+		Password:    &provider_models.UserPasswordCredential{Hash: types.StringValue("first")},
+	})
+	credentials = append(credentials, GenerateWarpgateUserAuthCredentials(ctx, provider_models.User{
+		Credentials: types.SetNull(types.ObjectType{AttrTypes: credentialsAttributes}),
+		Password:    &provider_models.UserPasswordCredential{Hash: types.StringValue("second")},
+	})...)
+
+	parsed, err = ParseUser(ctx, &warpgate.User{Username: "alice", Credentials: credentials})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var typed provider_models.User
+
+	if err := setTypedUserCredentials(ctx, &typed, parsed.Credentials, provider_models.User{}); err == nil || !strings.Contains(err.Error(), "more than one Password credential") {
+		t.Errorf("expected an error for two passwords, got %v", err)
+	}
+
+	prior := provider_models.User{
+		Sso: types.SetValueMust(types.ObjectType{AttrTypes: ssoCredentialAttributes}, []attr.Value{}),
+	}
+
+	parsed, err = ParseUser(ctx, &warpgate.User{Username: "alice", Credentials: credentials[:1]})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := setTypedUserCredentials(ctx, &typed, parsed.Credentials, prior); err != nil {
+		t.Fatal(err)
+	}
+
+	if typed.Password == nil || typed.Password.Hash.ValueString() != "first" {
+		t.Errorf("expected the single password, got %v", typed.Password)
+	}
+
+	// the sets empty in the prior state are kept empty instead of null
+	if typed.Sso.IsNull() || len(typed.Sso.Elements()) != 0 {
+		t.Errorf("expected an empty sso set, got %v", typed.Sso)
+	}
+
+	if !typed.PublicKeys.IsNull() {
+		t.Errorf("expected null public keys, got %v", typed.PublicKeys)
+	}
+}
+
+func TestPlanUserCredential(t *testing.T) {
+	configured := strings.Replace(testUserPublicKeyA, "user-a@example.com", "laptop", 1)
+
+	planned := planUserCredential(provider_models.UserAuthCredential{
+		Kind:      types.StringValue("PublicKey"),
+		PublicKey: sshkeys.NewPublicKeyValue(configured),
+	})
+
+	if planned.PublicKey.ValueString() != configured {
+		t.Errorf("expected the configured key, got %q", planned.PublicKey.ValueString())
+	}
+
+	if planned.FingerprintSha256.ValueString() != "SHA256:ug9edX55FAfpkL8yPrXtiYNjuwRUJ4XfNG2wfCA53SY" || planned.KeyType.ValueString() != "ssh-ed25519" {
+		t.Errorf("unexpected planned credential %v", planned)
+	}
+
+	planned = planUserCredential(provider_models.UserAuthCredential{
+		Kind:      types.StringValue("PublicKey"),
+		PublicKey: sshkeys.NewPublicKeyUnknown(),
+	})
+
+	if !planned.FingerprintSha256.IsUnknown() || !planned.KeyType.IsUnknown() {
+		t.Errorf("expected unknown fingerprint and key type for an unknown key, got %v", planned)
+	}
+
+	planned = planUserCredential(provider_models.UserAuthCredential{
+		Kind:      types.StringValue("Password"),
+		Hash:      types.StringValue("hash"),
+		PublicKey: sshkeys.NewPublicKeyNull(),
+	})
+
+	if !planned.FingerprintSha256.IsNull() || !planned.KeyType.IsNull() {
+		t.Errorf("expected null fingerprint and key type for a password, got %v", planned)
+	}
+}
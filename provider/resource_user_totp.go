@@ -0,0 +1,271 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var _ resource.Resource = &userTotpResource{}
+var _ resource.ResourceWithUpgradeState = &userTotpResource{}
+
+// userTotpStateMigrations upgrades the state of the older schema versions
+// of the user totp resource, see NewStateUpgraders.
+var userTotpStateMigrations = []StateMigration{}
+
+func (r userTotpResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version:     SchemaVersion(userTotpStateMigrations),
+		Description: "A `Totp` credential of a user, added and removed without changing the other credentials.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Id of the credential in warpgate",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"user_id": userCredentialUserIdAttribute(),
+			"key": schema.ListAttribute{
+				ElementType: types.Int64Type,
+				Required:    true,
+				Sensitive:   true,
+				Description: "The totp secret key as array of uint8.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueInt64sAre(int64validator.Between(0, 255)),
+				},
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+type userTotpResource struct {
+	provider *warpgateProvider
+}
+
+func NewUserTotpResource() resource.Resource {
+	return &userTotpResource{}
+}
+
+func (r *userTotpResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_totp"
+}
+
+func (r *userTotpResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	provider, ok := req.ProviderData.(*warpgateProvider)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *warpgateProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	if !provider.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"Expected a configured provider but it wasn't. Please report this issue to the provider developers.",
+		)
+
+		return
+	}
+
+	r.provider = provider
+}
+
+func (r *userTotpResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var resourceState provider_models.UserTotpResource
+
+	diags := req.Plan.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := resourceState.Timeouts.Create(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	userId, err := uuid.Parse(resourceState.UserId.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to parse user id.",
+			fmt.Sprintf("Invalid user id %s (Err: %s)", resourceState.UserId, err),
+		)
+		return
+	}
+
+	id, _, err := addUserCredential(ctx, r.provider.client, userId, userTotpCredential(resourceState))
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to add totp",
+			fmt.Sprintf("Failed to add totp to user with id '%s'. (Error: %s)", resourceState.UserId, err),
+		)
+		return
+	}
+
+	resourceState.Id = types.StringValue(id.String())
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *userTotpResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var resourceState provider_models.UserTotpResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := resourceState.Timeouts.Read(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	userId, id, ok := parseUserCredentialIds(resourceState.UserId, resourceState.Id, &resp.Diagnostics)
+
+	if !ok {
+		return
+	}
+
+	credential, err := findUserCredential(ctx, r.provider.client, userId, id, userTotpCredential(resourceState))
+
+	if !readUserCredential(ctx, "totp", resourceState.Id, credential, err, resp) {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update only changes the timeouts, any other change replaces the credential.
+func (r *userTotpResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var resourcePlan provider_models.UserTotpResource
+
+	diags := req.Plan.Get(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &resourcePlan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *userTotpResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var resourceState provider_models.UserTotpResource
+
+	diags := req.State.Get(ctx, &resourceState)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := resourceState.Timeouts.Delete(ctx, defaultResourceTimeout)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	userId, id, ok := parseUserCredentialIds(resourceState.UserId, resourceState.Id, &resp.Diagnostics)
+
+	if !ok {
+		return
+	}
+
+	err := removeUserCredential(ctx, r.provider.client, userId, id, userTotpCredential(resourceState))
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to remove totp",
+			fmt.Sprintf("Failed to remove totp with id '%s'. (Error: %s)", resourceState.Id, err),
+		)
+		return
+	}
+}
+
+func (r *userTotpResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return NewStateUpgraders(userTotpStateMigrations)
+}
+
+func userTotpCredential(totp provider_models.UserTotpResource) userCredential {
+	key := TerraformListToArrayOfUint16(totp.Key)
+
+	union := warpgate.UserAuthCredential{}
+	_ = union.FromUserAuthCredentialUserTotpCredential(warpgate.UserAuthCredentialUserTotpCredential{
+		Kind: string(warpgate.Totp),
+		Key:  key,
+	})
+
+	return userCredential{
+		kind:  warpgate.Totp,
+		new:   warpgate.NewUserCredential{SecretKey: key},
+		union: union,
+		matches: func(credential warpgate.UserAuthCredential) bool {
+			if !credentialOfKind(credential, warpgate.Totp) {
+				return false
+			}
+
+			existing, err := credential.AsUserAuthCredentialUserTotpCredential()
+			return err == nil && slices.Equal(existing.Key, key)
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccUserTotpResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccUserTotpResourceConfig("[1, 2, 3, 4]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("warpgate_user_totp.test", "id"),
+					resource.TestCheckResourceAttrPair("warpgate_user_totp.test", "user_id", "warpgate_user.test", "id"),
+					resource.TestCheckResourceAttr("warpgate_user_totp.test", "key.#", "4"),
+				),
+			},
+			// Replace testing
+			{
+				Config: testAccUserTotpResourceConfig("[5, 6, 7]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("warpgate_user_totp.test", "key.#", "3"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccUserTotpResourceConfig(key string) string {
+	return fmt.Sprintf(`
+provider "warpgate" {}
+
+resource "warpgate_user" "test" {
+	username = "totp-credential"
+}
+
+resource "warpgate_user_totp" "test" {
+	user_id = warpgate_user.test.id
+	key     = %s
+}
+`, key)
+}
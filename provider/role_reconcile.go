@@ -0,0 +1,227 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// roleReconcileConcurrency bounds the requests sent at the same time while
+// adding and removing the roles of a user or target.
+const roleReconcileConcurrency = 4
+
+// RoleAssignment adds or removes a single role, e.g. UsersService.AssignRole.
+type RoleAssignment func(ctx context.Context, roleId uuid.UUID) error
+
+// RoleReconciler brings the roles of a user or target from the current set
+// to the desired one.
+type RoleReconciler struct {
+	// ObjectName is used in the diagnostics, e.g. "user 7cd9e8a3-...".
+	ObjectName string
+	Add        RoleAssignment
+	Remove     RoleAssignment
+}
+
+type roleChangeResult struct {
+	roleId  string
+	add     bool
+	applied bool
+	diags   diag.Diagnostics
+}
+
+// Reconcile adds and removes the roles concurrently. All the failures are
+// collected into the diagnostics instead of stopping at the first one, and
+// the returned set is the one actually assigned after the reconciliation, to
+// be saved into the state even if some of the changes failed.
+func (r RoleReconciler) Reconcile(ctx context.Context, current []string, desired []string) (achieved []string, diags diag.Diagnostics) {
+	_, toBeAdded, toBeRemoved := ArrayIntersection(desired, current)
+
+	results := make(chan roleChangeResult, len(toBeAdded)+len(toBeRemoved))
+	semaphore := make(chan struct{}, roleReconcileConcurrency)
+	var wg sync.WaitGroup
+
+	run := func(roleId string, add bool) {
+		defer wg.Done()
+
+		semaphore <- struct{}{}
+		defer func() { <-semaphore }()
+
+		results <- r.change(ctx, roleId, add)
+	}
+
+	for _, roleId := range toBeRemoved {
+		wg.Add(1)
+		go run(roleId, false)
+	}
+
+	for _, roleId := range toBeAdded {
+		wg.Add(1)
+		go run(roleId, true)
+	}
+
+	wg.Wait()
+	close(results)
+
+	assigned := map[string]bool{}
+	for _, roleId := range current {
+		assigned[roleId] = true
+	}
+
+	var sorted []roleChangeResult
+	for result := range results {
+		sorted = append(sorted, result)
+	}
+
+	// deterministic order of the diagnostics
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].roleId < sorted[j].roleId })
+
+	for _, result := range sorted {
+		diags.Append(result.diags...)
+
+		if result.applied {
+			assigned[result.roleId] = result.add
+		}
+	}
+
+	achieved = []string{}
+	for roleId, isAssigned := range assigned {
+		if isAssigned {
+			achieved = append(achieved, roleId)
+		}
+	}
+
+	sort.Strings(achieved)
+
+	return achieved, diags
+}
+
+func (r RoleReconciler) change(ctx context.Context, roleId string, add bool) (result roleChangeResult) {
+	result = roleChangeResult{roleId: roleId, add: add}
+
+	roleUUID, err := uuid.Parse(roleId)
+
+	if err != nil {
+		result.diags.AddError(
+			"Failed to parse role id.",
+			fmt.Sprintf("Invalid role id %s (Err: %s)", roleId, err),
+		)
+		return
+	}
+
+	if add {
+		err = r.Add(ctx, roleUUID)
+
+		if err != nil {
+			result.diags.AddError(
+				"Failed to add role",
+				fmt.Sprintf("Failed to add role %s to %s. (Error: %s)", roleId, r.ObjectName, err),
+			)
+			return
+		}
+
+		result.applied = true
+		return
+	}
+
+	err = r.Remove(ctx, roleUUID)
+
+	switch {
+	case err == nil:
+		result.applied = true
+	case errors.Is(err, warpgate.ErrConflict):
+		result.diags.AddWarning(
+			"Failed to delete role, conflict.",
+			fmt.Sprintf("Failed to remove role %s from %s. (Error: %s)", roleId, r.ObjectName, err),
+		)
+		result.applied = true
+	default:
+		result.diags.AddError(
+			"Failed to delete role",
+			fmt.Sprintf("Failed to remove role %s from %s. (Error: %s)", roleId, r.ObjectName, err),
+		)
+	}
+
+	return
+}
+
+// userRoleReconciler adds and removes the roles of a user.
+func userRoleReconciler(client *warpgate.WarpgateClient, userUUID uuid.UUID) RoleReconciler {
+	return RoleReconciler{
+		ObjectName: fmt.Sprintf("user %s", userUUID),
+		Add: func(ctx context.Context, roleId uuid.UUID) error {
+			return client.Users().AssignRole(ctx, userUUID, roleId)
+		},
+		Remove: func(ctx context.Context, roleId uuid.UUID) error {
+			return client.Users().UnassignRole(ctx, userUUID, roleId)
+		},
+	}
+}
+
+// targetRoleReconciler adds and removes the roles allowed to access a target.
+func targetRoleReconciler(client *warpgate.WarpgateClient, targetUUID uuid.UUID) RoleReconciler {
+	return RoleReconciler{
+		ObjectName: fmt.Sprintf("target %s", targetUUID),
+		Add: func(ctx context.Context, roleId uuid.UUID) error {
+			return client.Targets().AssignRole(ctx, targetUUID, roleId)
+		},
+		Remove: func(ctx context.Context, roleId uuid.UUID) error {
+			return client.Targets().UnassignRole(ctx, targetUUID, roleId)
+		},
+	}
+}
+
+// ReconcileNames is Reconcile for the roles configured by name, as the
+// roles of warpgate_user and the allow_roles of the targets. The names are
+// resolved to ids through the list of the roles.
+func (r RoleReconciler) ReconcileNames(ctx context.Context, roles []warpgate.Role, current []string, desired []string) (achieved []string, diags diag.Diagnostics) {
+	idsByName := map[string]string{}
+	namesById := map[string]string{}
+
+	for _, role := range roles {
+		idsByName[role.Name] = role.Id.String()
+		namesById[role.Id.String()] = role.Name
+	}
+
+	toIds := func(names []string) (ids []string) {
+		for _, name := range names {
+			id, ok := idsByName[name]
+
+			if !ok {
+				diags.AddError(
+					"Role not found",
+					fmt.Sprintf("Failed to find the role '%s' of %s.", name, r.ObjectName),
+				)
+				continue
+			}
+
+			ids = append(ids, id)
+		}
+
+		return
+	}
+
+	currentIds := toIds(current)
+	desiredIds := toIds(desired)
+
+	if diags.HasError() {
+		return current, diags
+	}
+
+	achievedIds, reconcileDiags := r.Reconcile(ctx, currentIds, desiredIds)
+	diags.Append(reconcileDiags...)
+
+	achieved = []string{}
+	for _, id := range achievedIds {
+		achieved = append(achieved, namesById[id])
+	}
+
+	sort.Strings(achieved)
+
+	return achieved, diags
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"terraform-provider-warpgate/warpgate"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRoleReconcilerPartialFailure(t *testing.T) {
+	kept := uuid.New().String()
+	removed := uuid.New().String()
+	notRemoved := uuid.New().String()
+	added := uuid.New().String()
+	notAdded := uuid.New().String()
+	unreachable := uuid.New().String()
+	conflict := uuid.New().String()
+
+	var mutex sync.Mutex
+	calls := map[string]int{}
+
+	reconciler := RoleReconciler{
+		ObjectName: "user test",
+		Add: func(ctx context.Context, roleId uuid.UUID) error {
+			mutex.Lock()
+			calls[roleId.String()]++
+			mutex.Unlock()
+
+			switch roleId.String() {
+			case notAdded:
+				return &warpgate.StatusError{Operation: "add role to user", StatusCode: 404}
+			case unreachable:
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+		Remove: func(ctx context.Context, roleId uuid.UUID) error {
+			mutex.Lock()
+			calls[roleId.String()]++
+			mutex.Unlock()
+
+			switch roleId.String() {
+			case notRemoved:
+				return &warpgate.StatusError{Operation: "remove role from user", StatusCode: 500}
+			case conflict:
+				return &warpgate.StatusError{Operation: "remove role from user", StatusCode: 409}
+			}
+			return nil
+		},
+	}
+
+	achieved, diags := reconciler.Reconcile(
+		context.Background(),
+		[]string{kept, removed, notRemoved, conflict},
+		[]string{kept, added, notAdded, unreachable},
+	)
+
+	if len(diags.Errors()) != 3 {
+		t.Errorf("expected an error for each failed role, got %v", diags)
+	}
+
+	if len(diags.Warnings()) != 1 {
+		t.Errorf("expected a warning for the conflict, got %v", diags)
+	}
+
+	expected := []string{kept, added, notRemoved}
+	sort.Strings(expected)
+
+	if !reflect.DeepEqual(achieved, expected) {
+		t.Errorf("expected the achieved roles %v, got %v", expected, achieved)
+	}
+
+	if calls[kept] != 0 {
+		t.Errorf("unchanged roles must not be requested")
+	}
+
+	for _, roleId := range []string{removed, notRemoved, conflict, added, notAdded, unreachable} {
+		if calls[roleId] != 1 {
+			t.Errorf("expected a single request for role %s, got %d", roleId, calls[roleId])
+		}
+	}
+}
+
+func TestRoleReconcilerConcurrency(t *testing.T) {
+	var running, maxRunning int32
+
+	assignment := func(ctx context.Context, roleId uuid.UUID) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}
+
+	desired := []string{}
+	for i := 0; i < 3*roleReconcileConcurrency; i++ {
+		desired = append(desired, uuid.New().String())
+	}
+
+	achieved, diags := RoleReconciler{ObjectName: "user test", Add: assignment, Remove: assignment}.
+		Reconcile(context.Background(), []string{}, desired)
+
+	if diags.HasError() || len(achieved) != len(desired) {
+		t.Fatalf("unexpected result %v, %v", achieved, diags)
+	}
+
+	if maxRunning > roleReconcileConcurrency {
+		t.Errorf("expected at most %d concurrent requests, got %d", roleReconcileConcurrency, maxRunning)
+	}
+
+	if maxRunning < 2 {
+		t.Errorf("expected concurrent requests, got %d", maxRunning)
+	}
+}
+
+func TestRoleReconcilerNames(t *testing.T) {
+	roles := []warpgate.Role{
+		{Id: uuid.New(), Name: "developers"},
+		{Id: uuid.New(), Name: "ops"},
+		{Id: uuid.New(), Name: "readers"},
+	}
+
+	var mutex sync.Mutex
+	added := []uuid.UUID{}
+	removed := []uuid.UUID{}
+
+	reconciler := RoleReconciler{
+		ObjectName: "user test",
+		Add: func(ctx context.Context, roleId uuid.UUID) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			added = append(added, roleId)
+			return nil
+		},
+		Remove: func(ctx context.Context, roleId uuid.UUID) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			removed = append(removed, roleId)
+			return nil
+		},
+	}
+
+	achieved, diags := reconciler.ReconcileNames(context.Background(), roles, []string{"developers", "ops"}, []string{"ops", "readers"})
+
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	if !reflect.DeepEqual(achieved, []string{"ops", "readers"}) {
+		t.Errorf("expected the achieved roles [ops readers], got %v", achieved)
+	}
+
+	if !reflect.DeepEqual(added, []uuid.UUID{roles[2].Id}) || !reflect.DeepEqual(removed, []uuid.UUID{roles[0].Id}) {
+		t.Errorf("expected readers to be added and developers removed, got %v and %v", added, removed)
+	}
+
+	achieved, diags = reconciler.ReconcileNames(context.Background(), roles, []string{"ops"}, []string{"ops", "missing"})
+
+	if len(diags.Errors()) != 1 {
+		t.Errorf("expected an error for the missing role, got %v", diags)
+	}
+
+	if !reflect.DeepEqual(achieved, []string{"ops"}) || len(added) != 1 {
+		t.Errorf("expected no changes when a role is missing, got %v", achieved)
+	}
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+)
+
+// closeSessionsOnChangeAttribute is the close_sessions_on_change attribute of
+// the users and of the targets, change describes when the sessions are
+// closed.
+func closeSessionsOnChangeAttribute(change string) schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Optional: true,
+		MarkdownDescription: fmt.Sprintf("Close the active sessions %s. ", change) +
+			"The number of closed sessions is reported as a warning, also when no session was open.",
+	}
+}
+
+// userSessions matches the sessions of the user.
+func userSessions(username string) func(warpgate.SessionSnapshot) bool {
+	return func(session warpgate.SessionSnapshot) bool {
+		return session.Username != nil && *session.Username == username
+	}
+}
+
+// targetSessions matches the sessions on the target.
+func targetSessions(targetId uuid.UUID) func(warpgate.SessionSnapshot) bool {
+	return func(session warpgate.SessionSnapshot) bool {
+		return session.Target != nil && session.Target.Id == targetId
+	}
+}
+
+// closeSessionsOnChange closes the active sessions matching the user or
+// target named object. It runs after the change has been applied, so the
+// failures are only reported as warnings, like the number of closed sessions,
+// which is reported even when it is zero.
+func closeSessionsOnChange(ctx context.Context, client *warpgate.WarpgateClient, object string, match func(warpgate.SessionSnapshot) bool) diag.Diagnostics {
+	closed, err := closeSessions(ctx, client, match)
+
+	var diags diag.Diagnostics
+
+	if err != nil {
+		diags.AddWarning(
+			"Failed to close sessions",
+			fmt.Sprintf("Failed to close the sessions of %s. (Error: %s)", object, err),
+		)
+	}
+
+	diags.AddWarning(
+		"Sessions closed",
+		fmt.Sprintf("Closed %d active sessions of %s.", closed, object),
+	)
+
+	return diags
+}
+
+// closeSessions closes the active sessions matching match, returning how many
+// were closed. All the sessions are tried, the failures are joined in the
+// error.
+func closeSessions(ctx context.Context, client *warpgate.WarpgateClient, match func(warpgate.SessionSnapshot) bool) (closed int, err error) {
+	sessions, err := client.Sessions().List(ctx, true)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the active sessions: %w", err)
+	}
+
+	failures := []error{}
+
+	for _, session := range sessions {
+		if !match(session) {
+			continue
+		}
+
+		err := client.Sessions().Close(ctx, session.Id)
+
+		switch {
+		case err == nil:
+			closed++
+		case errors.Is(err, warpgate.ErrNotFound):
+			// ended in the meantime
+		default:
+			failures = append(failures, fmt.Errorf("session %s: %w", session.Id, err))
+		}
+	}
+
+	return closed, errors.Join(failures...)
+}
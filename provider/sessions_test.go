@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+	"terraform-provider-warpgate/warpgate"
+	"terraform-provider-warpgate/warpgate/warpgatetest"
+	"testing"
+)
+
+func TestCloseSessions(t *testing.T) {
+	server := warpgatetest.NewServer()
+	t.Cleanup(server.Close)
+
+	client := warpgate.NewWarpgateClient(server.Host(), server.Port(), true, warpgate.RequestLimits{})
+
+	if err := client.Login(warpgatetest.AdminUsername, warpgatetest.AdminPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	// more than a page of sessions of other users
+	for i := 0; i < 150; i++ {
+		server.AddSession("bob", warpgatetest.AdminTargetName)
+	}
+
+	first := server.AddSession("alice", warpgatetest.AdminTargetName)
+	second := server.AddSession("alice", "missing")
+	other := server.AddSession("bob", warpgatetest.AdminTargetName)
+
+	diags := closeSessionsOnChange(context.Background(), client, "user 'alice'", userSessions("alice"))
+
+	if len(diags.Warnings()) != 1 || diags.Warnings()[0].Detail() != "Closed 2 active sessions of user 'alice'." {
+		t.Errorf("expected a warning with the closed sessions, got %v", diags)
+	}
+
+	if server.SessionActive(first) || server.SessionActive(second) || !server.SessionActive(other) {
+		t.Errorf("expected only the sessions of alice to be closed")
+	}
+
+	diags = closeSessionsOnChange(context.Background(), client, "user 'alice'", userSessions("alice"))
+
+	if len(diags.Warnings()) != 1 || diags.Warnings()[0].Detail() != "Closed 0 active sessions of user 'alice'." {
+		t.Errorf("expected a warning with no closed sessions, got %v", diags)
+	}
+
+	targets, err := client.Targets().List(context.Background(), warpgate.TargetKindWebAdmin)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags = closeSessionsOnChange(context.Background(), client, "target 'admin'", targetSessions(targets[0].Id))
+
+	if len(diags.Warnings()) != 1 || diags.Warnings()[0].Detail() != "Closed 151 active sessions of target 'admin'." {
+		t.Errorf("expected a warning with the closed sessions, got %v", diags)
+	}
+}
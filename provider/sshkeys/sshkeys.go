@@ -0,0 +1,99 @@
+// Package sshkeys parses and compares ssh public keys, and defines the
+// string type of the public key attributes.
+package sshkeys
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Parse parses a public key in the authorized_keys format
+// (`<type> <base64> [comment]`).
+func Parse(key string) (ssh.PublicKey, string, error) {
+	publicKey, comment, _, rest, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(key)))
+
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid ssh public key (Error: %s)", err)
+	}
+
+	if len(strings.TrimSpace(string(rest))) > 0 {
+		return nil, "", fmt.Errorf("invalid ssh public key, only a single key is supported")
+	}
+
+	return publicKey, comment, nil
+}
+
+// Normalize returns the key as `<type> <base64>`, without
+// comment and surrounding whitespace.
+func Normalize(key string) (string, error) {
+	publicKey, _, err := Parse(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))), nil
+}
+
+// Fingerprint returns the SHA256 fingerprint (as printed by
+// `ssh-keygen -l`) and the type of the key.
+func Fingerprint(key string) (fingerprint string, keyType string, err error) {
+	publicKey, _, err := Parse(key)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return ssh.FingerprintSHA256(publicKey), publicKey.Type(), nil
+}
+
+// Equal reports if two keys are the same key, ignoring the
+// comment and the whitespace.
+func Equal(a string, b string) bool {
+	normalizedA, err := Normalize(a)
+
+	if err != nil {
+		return false
+	}
+
+	normalizedB, err := Normalize(b)
+
+	if err != nil {
+		return false
+	}
+
+	return normalizedA == normalizedB
+}
+
+// ParseBase64 parses a public key in the wire format encoded
+// in base64, as returned by warpgate.
+func ParseBase64(publicKeyBase64 string) (ssh.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(publicKeyBase64)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh public key, not valid base64 (Error: %s)", err)
+	}
+
+	publicKey, err := ssh.ParsePublicKey(data)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh public key (Error: %s)", err)
+	}
+
+	return publicKey, nil
+}
+
+// AuthorizedKey formats the key as an authorized_keys line, the comment
+// is appended only if not empty.
+func AuthorizedKey(publicKey ssh.PublicKey, comment string) string {
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+
+	if comment != "" {
+		line += " " + comment
+	}
+
+	return line
+}
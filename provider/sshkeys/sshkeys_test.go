@@ -0,0 +1,104 @@
+package sshkeys
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const (
+	testPublicKeyA = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAUHVLLcZmjfhjMTZk8J5q/RZn8+htJakhvTikkAt+5f user-a@example.com"
+	testPublicKeyB = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIN1Y1xyNh61UymYLaCp3Q/oq3WdwuQhWYmwztxBdH+Jx user-b@example.com"
+)
+
+func TestFingerprint(t *testing.T) {
+	fingerprint, keyType, err := Fingerprint(testPublicKeyA + "\n")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fingerprint != "SHA256:ug9edX55FAfpkL8yPrXtiYNjuwRUJ4XfNG2wfCA53SY" {
+		t.Errorf("unexpected fingerprint %s", fingerprint)
+	}
+
+	if keyType != "ssh-ed25519" {
+		t.Errorf("unexpected key type %s", keyType)
+	}
+
+	if _, _, err := Fingerprint("AAAAAAAAAAA"); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	normalized := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAUHVLLcZmjfhjMTZk8J5q/RZn8+htJakhvTikkAt+5f"
+
+	cases := []struct {
+		a        string
+		b        string
+		expected bool
+	}{
+		{testPublicKeyA, testPublicKeyA, true},
+		{testPublicKeyA, normalized, true},
+		{testPublicKeyA, "  " + normalized + " laptop\n", true},
+		{testPublicKeyA, testPublicKeyB, false},
+		{"invalid", "invalid", false},
+	}
+
+	for _, c := range cases {
+		if got := Equal(c.a, c.b); got != c.expected {
+			t.Errorf("Equal(%q, %q) = %t, expected %t", c.a, c.b, got, c.expected)
+		}
+	}
+
+	if got, _ := Normalize(testPublicKeyA); got != normalized {
+		t.Errorf("unexpected normalized key %q", got)
+	}
+}
+
+func TestAuthorizedKey(t *testing.T) {
+	publicKey, err := ParseBase64("AAAAC3NzaC1lZDI1NTE5AAAAIAUHVLLcZmjfhjMTZk8J5q/RZn8+htJakhvTikkAt+5f")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := AuthorizedKey(publicKey, "warpgate"); got != testPublicKeyA[:strings.LastIndex(testPublicKeyA, " ")]+" warpgate" {
+		t.Errorf("unexpected authorized key %q", got)
+	}
+
+	if got := AuthorizedKey(publicKey, ""); strings.Count(got, " ") != 1 {
+		t.Errorf("unexpected authorized key without comment %q", got)
+	}
+
+	if _, err := ParseBase64("not base64!"); err == nil {
+		t.Error("expected an error for invalid base64")
+	}
+}
+
+func TestPublicKeySemanticEquals(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		prior    string
+		new      string
+		expected bool
+	}{
+		{testPublicKeyA, testPublicKeyA, true},
+		{testPublicKeyA, strings.Replace(testPublicKeyA, "user-a@example.com", "laptop", 1) + "\n", true},
+		{testPublicKeyA, testPublicKeyB, false},
+	}
+
+	for _, c := range cases {
+		equal, diags := NewPublicKeyValue(c.prior).StringSemanticEquals(ctx, NewPublicKeyValue(c.new))
+
+		if diags.HasError() {
+			t.Fatal(diags)
+		}
+
+		if equal != c.expected {
+			t.Errorf("StringSemanticEquals(%q, %q) = %t, expected %t", c.prior, c.new, equal, c.expected)
+		}
+	}
+}
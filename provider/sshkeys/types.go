@@ -0,0 +1,115 @@
+package sshkeys
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var _ basetypes.StringTypable = PublicKeyType{}
+var _ basetypes.StringValuableWithSemanticEquals = PublicKey{}
+
+// PublicKeyType is the type of the string attributes holding a public key in
+// the authorized_keys format. Its values that differ only in comment or
+// whitespace are semantically equal, so the key read back from warpgate does
+// not replace the configured one in the state.
+type PublicKeyType struct {
+	basetypes.StringType
+}
+
+func (t PublicKeyType) String() string {
+	return "sshkeys.PublicKeyType"
+}
+
+func (t PublicKeyType) ValueType(ctx context.Context) attr.Value {
+	return PublicKey{}
+}
+
+func (t PublicKeyType) Equal(o attr.Type) bool {
+	other, ok := o.(PublicKeyType)
+
+	if !ok {
+		return false
+	}
+
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t PublicKeyType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return PublicKey{StringValue: in}, nil
+}
+
+func (t PublicKeyType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	stringValuable, diags := t.ValueFromString(ctx, stringValue)
+
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to StringValuable: %v", diags)
+	}
+
+	return stringValuable, nil
+}
+
+// PublicKey is a value of PublicKeyType.
+type PublicKey struct {
+	basetypes.StringValue
+}
+
+func (v PublicKey) Type(ctx context.Context) attr.Type {
+	return PublicKeyType{}
+}
+
+func (v PublicKey) Equal(o attr.Value) bool {
+	other, ok := o.(PublicKey)
+
+	if !ok {
+		return false
+	}
+
+	return v.StringValue.Equal(other.StringValue)
+}
+
+// StringSemanticEquals reports if both values are the same key, see Equal.
+func (v PublicKey) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(PublicKey)
+
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got value type %T. Please report this to the provider developers.", v, newValuable),
+		)
+
+		return false, diags
+	}
+
+	return Equal(v.ValueString(), newValue.ValueString()), diags
+}
+
+func NewPublicKeyNull() PublicKey {
+	return PublicKey{StringValue: basetypes.NewStringNull()}
+}
+
+func NewPublicKeyUnknown() PublicKey {
+	return PublicKey{StringValue: basetypes.NewStringUnknown()}
+}
+
+func NewPublicKeyValue(value string) PublicKey {
+	return PublicKey{StringValue: basetypes.NewStringValue(value)}
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// ssoProviderCheck checks the provider of the Sso credentials of a plan
+// against the sso providers configured on the server, which are listed once
+// for all the credentials.
+type ssoProviderCheck struct {
+	client *warpgate.WarpgateClient
+	listed bool
+	// providers is nil when the server does not list them or the listing
+	// failed.
+	providers []warpgate.SsoProvider
+}
+
+func newSsoProviderCheck(client *warpgate.WarpgateClient) *ssoProviderCheck {
+	return &ssoProviderCheck{client: client}
+}
+
+// check returns an error on the attribute when the provider of an Sso
+// credential is not one of the sso providers configured on the server, which
+// would only be noticed when the user fails to login. Null (any provider) and
+// unknown providers are not checked. The servers that do not list their
+// providers are not checked either, and a failure to list them is only a
+// warning, reported once, since the credential may still be valid.
+func (c *ssoProviderCheck) check(ctx context.Context, attribute path.Path, email types.String, provider types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if provider.IsNull() || provider.IsUnknown() {
+		return diags
+	}
+
+	if !c.listed {
+		c.listed = true
+		providers, err := c.client.Sso().Providers(ctx)
+
+		if errors.Is(err, warpgate.ErrNotFound) {
+			tflog.Debug(ctx, "The server does not list its sso providers, the provider of the Sso credentials is not checked.")
+			return diags
+		}
+
+		if err != nil {
+			diags.AddAttributeWarning(
+				attribute,
+				"Failed to check the sso provider",
+				fmt.Sprintf("Failed to list the sso providers to check '%s'. (Error: %s)", provider.ValueString(), err),
+			)
+			return diags
+		}
+
+		c.providers = providers
+	}
+
+	if c.providers == nil {
+		return diags
+	}
+
+	names := []string{}
+
+	for _, configured := range c.providers {
+		if configured.Name == provider.ValueString() {
+			return diags
+		}
+
+		names = append(names, fmt.Sprintf("'%s'", configured.Name))
+	}
+
+	sort.Strings(names)
+
+	configured := "no sso provider is configured"
+	if len(names) > 0 {
+		configured = "the configured providers are " + strings.Join(names, ", ")
+	}
+
+	diags.AddAttributeError(
+		attribute,
+		"Unknown sso provider",
+		fmt.Sprintf("The sso provider '%s' of the Sso credential of '%s' is not in the warpgate config file, %s.",
+			provider.ValueString(), email.ValueString(), configured),
+	)
+
+	return diags
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"terraform-provider-warpgate/warpgate"
+	"terraform-provider-warpgate/warpgate/warpgatetest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCheckSsoProvider(t *testing.T) {
+	cases := []struct {
+		name      string
+		providers []warpgate.SsoProvider
+		provider  types.String
+		expected  string
+	}{
+		{"configured", nil, types.StringValue(warpgatetest.SsoProviderName), ""},
+		{"any provider", nil, types.StringNull(), ""},
+		{"unknown", nil, types.StringUnknown(), ""},
+		{"typo", nil, types.StringValue("gogle"), "the configured providers are 'google'"},
+		{"none configured", []warpgate.SsoProvider{}, types.StringValue("google"), "no sso provider is configured"},
+		{"several configured", []warpgate.SsoProvider{{Name: "corp"}, {Name: "azure"}}, types.StringValue("google"), "the configured providers are 'azure', 'corp'"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, p := testActionServer(t)
+
+			if c.providers != nil {
+				server.SetSsoProviders(c.providers...)
+			}
+
+			diags := newSsoProviderCheck(p.client).check(context.Background(), path.Root("sso"), types.StringValue("alice@example.com"), c.provider)
+
+			if c.expected == "" {
+				if diags.HasError() {
+					t.Errorf("expected no error, got %v", diags)
+				}
+				return
+			}
+
+			if !diags.HasError() || !strings.Contains(diags.Errors()[0].Detail(), c.expected) {
+				t.Errorf("expected an error containing %q, got %v", c.expected, diags)
+			}
+		})
+	}
+}
+
+// The providers are listed once for all the credentials of a plan.
+func TestSsoProviderCheckListsOnce(t *testing.T) {
+	server, p := testActionServer(t)
+	ctx := context.Background()
+	check := newSsoProviderCheck(p.client)
+
+	if diags := check.check(ctx, path.Root("sso"), types.StringValue("alice@example.com"), types.StringValue(warpgatetest.SsoProviderName)); diags.HasError() {
+		t.Fatalf("expected no error, got %v", diags)
+	}
+
+	// not read again by the same check
+	server.SetSsoProviders(warpgate.SsoProvider{Name: "corp"})
+
+	if diags := check.check(ctx, path.Root("sso"), types.StringValue("bob@example.com"), types.StringValue(warpgatetest.SsoProviderName)); diags.HasError() {
+		t.Errorf("expected the providers listed by the first check, got %v", diags)
+	}
+
+	if diags := newSsoProviderCheck(p.client).check(ctx, path.Root("sso"), types.StringValue("bob@example.com"), types.StringValue(warpgatetest.SsoProviderName)); !diags.HasError() {
+		t.Errorf("expected a new check to list the providers again")
+	}
+}
@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// StateMigration upgrades the raw (json decoded) state of a resource from a
+// schema version to the next one.
+type StateMigration func(state map[string]interface{}) error
+
+// Every resource keeps the list of its migrations next to its schema:
+// migrations[i] upgrades the state from version i to version i+1, so the
+// current schema version is always len(migrations).
+// Adding an attribute does not need a new version: the framework sets the
+// attributes missing from the state to null.
+func SchemaVersion(migrations []StateMigration) int64 {
+	return int64(len(migrations))
+}
+
+// NewStateUpgraders returns an upgrader for every prior schema version, each
+// one applying all the remaining migrations in order, since terraform expects
+// a single step from any prior version to the current one.
+func NewStateUpgraders(migrations []StateMigration) map[int64]resource.StateUpgrader {
+	upgraders := map[int64]resource.StateUpgrader{}
+
+	for version := range migrations {
+		fromVersion := int64(version)
+
+		upgraders[fromVersion] = resource.StateUpgrader{
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				if req.RawState == nil || req.RawState.JSON == nil {
+					resp.Diagnostics.AddError(
+						"Failed to upgrade state",
+						fmt.Sprintf("Failed to upgrade state from version %d. Only json states are supported.", fromVersion),
+					)
+					return
+				}
+
+				upgraded, err := UpgradeRawState(req.RawState.JSON, fromVersion, migrations)
+
+				if err != nil {
+					resp.Diagnostics.AddError(
+						"Failed to upgrade state",
+						fmt.Sprintf("Failed to upgrade state from version %d to %d. (Error: %s)", fromVersion, SchemaVersion(migrations), err),
+					)
+					return
+				}
+
+				resp.DynamicValue = &tfprotov6.DynamicValue{JSON: upgraded}
+			},
+		}
+	}
+
+	return upgraders
+}
+
+// UpgradeRawState applies the migrations from the given version up to the
+// current one to a json state.
+func UpgradeRawState(rawState []byte, fromVersion int64, migrations []StateMigration) ([]byte, error) {
+	if fromVersion < 0 || fromVersion > SchemaVersion(migrations) {
+		return nil, fmt.Errorf("unknown schema version %d", fromVersion)
+	}
+
+	var state map[string]interface{}
+
+	if err := json.Unmarshal(rawState, &state); err != nil {
+		return nil, err
+	}
+
+	for version := fromVersion; version < SchemaVersion(migrations); version++ {
+		if err := migrations[version](state); err != nil {
+			return nil, fmt.Errorf("migration from version %d to %d failed: %s", version, version+1, err)
+		}
+	}
+
+	return json.Marshal(state)
+}
+
+// noStateChanges is used when the schema version is bumped without changes
+// in the state representation.
+func noStateChanges(state map[string]interface{}) error {
+	return nil
+}
+
+// renameStateAttribute moves an attribute, nested objects are addressed by the
+// names of the parents, e.g. renameStateAttribute([]string{"options"}, "host", "address").
+func renameStateAttribute(parents []string, from string, to string) StateMigration {
+	return func(state map[string]interface{}) error {
+		object, err := stateObjectAt(state, parents)
+
+		if err != nil || object == nil {
+			return err
+		}
+
+		if value, ok := object[from]; ok {
+			object[to] = value
+			delete(object, from)
+		}
+
+		return nil
+	}
+}
+
+// removeStateAttribute drops an attribute that is no longer part of the schema.
+func removeStateAttribute(parents []string, name string) StateMigration {
+	return func(state map[string]interface{}) error {
+		object, err := stateObjectAt(state, parents)
+
+		if err != nil || object == nil {
+			return err
+		}
+
+		delete(object, name)
+
+		return nil
+	}
+}
+
+// addStateAttribute sets a new attribute to its initial value, only needed
+// when it is not null.
+func addStateAttribute(parents []string, name string, value interface{}) StateMigration {
+	return func(state map[string]interface{}) error {
+		object, err := stateObjectAt(state, parents)
+
+		if err != nil || object == nil {
+			return err
+		}
+
+		if _, ok := object[name]; !ok {
+			object[name] = value
+		}
+
+		return nil
+	}
+}
+
+// allStateMigrations applies several migrations in a single schema version.
+func allStateMigrations(migrations ...StateMigration) StateMigration {
+	return func(state map[string]interface{}) error {
+		for _, migration := range migrations {
+			if err := migration(state); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// forEachStateElement applies a migration to every object of a nested list or
+// set attribute, e.g. forEachStateElement(nil, "credentials", addStateAttribute(nil, "key_type", nil)).
+func forEachStateElement(parents []string, name string, migration StateMigration) StateMigration {
+	return func(state map[string]interface{}) error {
+		object, err := stateObjectAt(state, parents)
+
+		if err != nil || object == nil || object[name] == nil {
+			return err
+		}
+
+		elements, ok := object[name].([]interface{})
+
+		if !ok {
+			return fmt.Errorf("attribute '%s' is not a list", name)
+		}
+
+		for _, element := range elements {
+			elementObject, ok := element.(map[string]interface{})
+
+			if !ok {
+				return fmt.Errorf("an element of '%s' is not an object", name)
+			}
+
+			if err := migration(elementObject); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// stateObjectAt returns the nested object at the given path, or nil if one of
+// the parents is null.
+func stateObjectAt(state map[string]interface{}, parents []string) (map[string]interface{}, error) {
+	object := state
+
+	for _, parent := range parents {
+		value, ok := object[parent]
+
+		if !ok || value == nil {
+			return nil, nil
+		}
+
+		object, ok = value.(map[string]interface{})
+
+		if !ok {
+			return nil, fmt.Errorf("attribute '%s' is not an object", parent)
+		}
+	}
+
+	return object, nil
+}
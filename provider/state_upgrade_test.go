@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+func TestUpgradeRawState(t *testing.T) {
+	migrations := []StateMigration{
+		noStateChanges,
+		renameStateAttribute([]string{"options"}, "host", "address"),
+		addStateAttribute(nil, "description", "none"),
+		removeStateAttribute(nil, "legacy"),
+		forEachStateElement(nil, "items", addStateAttribute(nil, "kind", "a")),
+	}
+
+	cases := []struct {
+		name        string
+		fromVersion int64
+		state       string
+		expected    string
+	}{
+		{
+			name:        "from version 0",
+			fromVersion: 0,
+			state:       `{"id":"1","legacy":true,"options":{"host":"a","port":22}}`,
+			expected:    `{"id":"1","description":"none","options":{"address":"a","port":22}}`,
+		},
+		{
+			name:        "list elements",
+			fromVersion: 4,
+			state:       `{"id":"1","items":[{"kind":"b"},{}]}`,
+			expected:    `{"id":"1","items":[{"kind":"b"},{"kind":"a"}]}`,
+		},
+		{
+			name:        "from version 2 skips the rename",
+			fromVersion: 2,
+			state:       `{"id":"1","legacy":true,"options":{"host":"a","port":22}}`,
+			expected:    `{"id":"1","description":"none","options":{"host":"a","port":22}}`,
+		},
+		{
+			name:        "null parent",
+			fromVersion: 0,
+			state:       `{"id":"1","options":null}`,
+			expected:    `{"id":"1","description":"none","options":null}`,
+		},
+		{
+			name:        "current version",
+			fromVersion: 5,
+			state:       `{"id":"1","legacy":true}`,
+			expected:    `{"id":"1","legacy":true}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			upgraded, err := UpgradeRawState([]byte(c.state), c.fromVersion, migrations)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertJsonEqual(t, c.expected, string(upgraded))
+		})
+	}
+
+	if _, err := UpgradeRawState([]byte(`{}`), 6, migrations); err == nil {
+		t.Error("expected an error for an unknown schema version")
+	}
+
+	if _, err := UpgradeRawState([]byte(`{"options":"a"}`), 1, migrations); err == nil {
+		t.Error("expected an error when a parent is not an object")
+	}
+}
+
+// The state of every schema version of every resource must upgrade to a
+// state that is valid for the current schema.
+func TestResourceStateUpgraders(t *testing.T) {
+	// every resource is still at version 0, a state of each prior version must
+	// be added here when a schema version is bumped
+	cases := []struct {
+		resource resource.Resource
+		states   map[int64]string
+	}{
+		{resource: NewRoleResource()},
+		{resource: NewSshTargetResource()},
+		{resource: NewHttpTargetResource()},
+		{resource: NewPostgresTargetResource()},
+		{resource: NewTargetResource()},
+		{resource: NewUserResource()},
+		{resource: NewUserRolesResource()},
+		{resource: NewTargetRolesResource()},
+		{resource: NewUserPasswordResource()},
+		{resource: NewUserTotpResource()},
+		{resource: NewUserSsoCredentialResource()},
+		{resource: NewUserPublicKeyResource()},
+	}
+
+	ctx := context.Background()
+
+	for _, c := range cases {
+		metadata := resource.MetadataResponse{}
+		c.resource.Metadata(ctx, resource.MetadataRequest{ProviderTypeName: "warpgate"}, &metadata)
+
+		t.Run(metadata.TypeName, func(t *testing.T) {
+			schemaResponse := resource.SchemaResponse{}
+			c.resource.Schema(ctx, resource.SchemaRequest{}, &schemaResponse)
+
+			upgraders := c.resource.(resource.ResourceWithUpgradeState).UpgradeState(ctx)
+
+			if int64(len(upgraders)) != schemaResponse.Schema.Version {
+				t.Fatalf("expected an upgrader for each of the %d prior versions, got %d", schemaResponse.Schema.Version, len(upgraders))
+			}
+
+			for version := int64(0); version < schemaResponse.Schema.Version; version++ {
+				state, ok := c.states[version]
+
+				if !ok {
+					t.Fatalf("missing test state for version %d", version)
+				}
+
+				upgrader, ok := upgraders[version]
+
+				if !ok {
+					t.Fatalf("missing upgrader for version %d", version)
+				}
+
+				resp := resource.UpgradeStateResponse{}
+				upgrader.StateUpgrader(ctx, resource.UpgradeStateRequest{
+					RawState: &tfprotov6.RawState{JSON: []byte(state)},
+				}, &resp)
+
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("version %d: %v", version, resp.Diagnostics)
+				}
+
+				if _, err := resp.DynamicValue.Unmarshal(schemaResponse.Schema.Type().TerraformType(ctx)); err != nil {
+					t.Fatalf("version %d: upgraded state does not match the current schema: %s", version, err)
+				}
+			}
+		})
+	}
+}
+
+func assertJsonEqual(t *testing.T, expected string, actual string) {
+	t.Helper()
+
+	var expectedValue, actualValue interface{}
+
+	if err := json.Unmarshal([]byte(expected), &expectedValue); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := json.Unmarshal([]byte(actual), &actualValue); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expectedValue, actualValue) {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"terraform-provider-warpgate/provider/validators"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// userCredential is the credential managed by one of the per-credential
+// resources (warpgate_user_password, warpgate_user_totp,
+// warpgate_user_sso_credential and warpgate_user_public_key).
+type userCredential struct {
+	kind warpgate.CredentialKind
+	// new is sent to the per-credential endpoints.
+	new warpgate.NewUserCredential
+	// union is added to the credentials of the user on the servers without
+	// the per-credential endpoints.
+	union warpgate.UserAuthCredential
+	// matches reports if a credential of the user is this one, on the servers
+	// without the per-credential endpoints.
+	matches func(credential warpgate.UserAuthCredential) bool
+}
+
+// addUserCredential adds the credential to the user, with the per-credential
+// endpoints if supported by the server. Returns the id of the credential.
+//
+// The servers without the endpoints have no credential ids: a random one is
+// generated, which is only known to the state and is not stable across
+// recreations. The credential is then found with matches, which is also why
+// the per-credential resources do not implement ImportState.
+func addUserCredential(ctx context.Context, client *warpgate.WarpgateClient, userId uuid.UUID, credential userCredential) (id uuid.UUID, supported bool, err error) {
+	credentials := client.Users().Credentials(userId)
+
+	supported, err = credentials.Supported(ctx)
+
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+
+	if supported {
+		created, err := credentials.Add(ctx, credential.kind, credential.new)
+
+		if err != nil {
+			return uuid.Nil, true, err
+		}
+
+		return created.Id, true, nil
+	}
+
+	_, err = credentials.Update(ctx, func(user *warpgate.UserDataRequest) error {
+		user.Credentials = append(user.Credentials, credential.union)
+		return nil
+	})
+
+	return uuid.New(), false, err
+}
+
+// findUserCredential returns the credential of the user with the id, or nil
+// if it has been removed. On the servers without the per-credential endpoints
+// the credential is found with matches, and only its id is returned.
+func findUserCredential(ctx context.Context, client *warpgate.WarpgateClient, userId uuid.UUID, id uuid.UUID, credential userCredential) (*warpgate.UserCredential, error) {
+	credentials := client.Users().Credentials(userId)
+
+	supported, err := credentials.Supported(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if supported {
+		existing, err := credentials.List(ctx, credential.kind)
+
+		if err != nil {
+			return nil, err
+		}
+
+		index := slices.IndexFunc(existing, func(c warpgate.UserCredential) bool { return c.Id == id })
+
+		if index < 0 {
+			return nil, nil
+		}
+
+		return &existing[index], nil
+	}
+
+	user, err := client.Users().Get(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(user.Credentials, credential.matches) {
+		return nil, nil
+	}
+
+	return &warpgate.UserCredential{Id: id}, nil
+}
+
+// removeUserCredential removes the credential from the user, a credential or
+// a user already removed is not an error.
+func removeUserCredential(ctx context.Context, client *warpgate.WarpgateClient, userId uuid.UUID, id uuid.UUID, credential userCredential) error {
+	credentials := client.Users().Credentials(userId)
+
+	supported, err := credentials.Supported(ctx)
+
+	if err == nil && supported {
+		err = credentials.Delete(ctx, credential.kind, id)
+	} else if err == nil {
+		_, err = credentials.Update(ctx, func(user *warpgate.UserDataRequest) error {
+			if index := slices.IndexFunc(user.Credentials, credential.matches); index >= 0 {
+				user.Credentials = slices.Delete(slices.Clone(user.Credentials), index, index+1)
+			}
+
+			return nil
+		})
+	}
+
+	if errors.Is(err, warpgate.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// credentialOfKind reports if the credential is of the kind, to build the
+// matches of userCredential.
+func credentialOfKind(credential warpgate.UserAuthCredential, kind warpgate.CredentialKind) bool {
+	discriminator, err := credential.Discriminator()
+	return err == nil && discriminator == string(kind)
+}
+
+// userCredentialUserIdAttribute is the user of the per-credential resources,
+// moving a credential to another user replaces it.
+func userCredentialUserIdAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Required: true,
+		MarkdownDescription: "Id of the user in warpgate. Set neither `credentials` nor the typed credentials on the [user](user.md), " +
+			"otherwise it removes the credentials added by this resource.",
+		Validators: []validator.String{
+			validators.IsUUID(),
+		},
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+}
+
+// parseUserCredentialIds parses the user id and the id of a per-credential
+// resource.
+func parseUserCredentialIds(userId types.String, id types.String, diags *diag.Diagnostics) (uuid.UUID, uuid.UUID, bool) {
+	userUUID, err := uuid.Parse(userId.ValueString())
+
+	if err != nil {
+		diags.AddError(
+			"Failed to parse user id.",
+			fmt.Sprintf("Invalid user id %s (Err: %s)", userId, err),
+		)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	idUUID, err := uuid.Parse(id.ValueString())
+
+	if err != nil {
+		diags.AddError(
+			"Failed to parse the id as uuid",
+			fmt.Sprintf("Failed to parse the id '%s' as uuid", id),
+		)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userUUID, idUUID, true
+}
+
+// readUserCredential handles the result of findUserCredential in the read of
+// a per-credential resource, removing it from the state if the credential or
+// the user have been removed. Returns true if the credential was found.
+func readUserCredential(ctx context.Context, name string, id types.String, credential *warpgate.UserCredential, err error, resp *resource.ReadResponse) bool {
+	if errors.Is(err, warpgate.ErrNotFound) || (err == nil && credential == nil) {
+		resp.Diagnostics.AddWarning(
+			fmt.Sprintf("Failed to read %s, resource not found. Removing from the state.", name),
+			fmt.Sprintf("Failed to read %s with id '%s', the credential or the user have been removed.", name, id),
+		)
+		resp.State.RemoveResource(ctx)
+		return false
+	}
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			fmt.Sprintf("Failed to read %s", name),
+			fmt.Sprintf("Failed to read %s with id '%s'. (Error: %s)", name, id, err),
+		)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	provider_models "terraform-provider-warpgate/provider/models"
+	"terraform-provider-warpgate/provider/sshkeys"
+	"terraform-provider-warpgate/warpgate"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestUserCredentialSchemas(t *testing.T) {
+	ctx := context.Background()
+
+	for _, r := range []resource.Resource{NewUserPasswordResource(), NewUserTotpResource(), NewUserSsoCredentialResource(), NewUserPublicKeyResource()} {
+		schemaResp := resource.SchemaResponse{}
+		r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+		if diags := schemaResp.Schema.ValidateImplementation(ctx); diags.HasError() {
+			t.Errorf("%T: %v", r, diags)
+		}
+	}
+}
+
+func TestUserCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	for _, endpoints := range []bool{true, false} {
+		server, p := testActionServer(t)
+
+		if !endpoints {
+			server.DisableCredentialEndpoints()
+		}
+
+		user, err := p.client.Users().Create(ctx, warpgate.UserDataRequest{Username: "alice"})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		hash, err := newUserPasswordHash("secret")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		credentials := []userCredential{
+			userPasswordCredential(provider_models.UserPasswordResource{
+				Password: types.StringValue("secret"),
+				Hash:     types.StringValue(hash),
+			}),
+			userTotpCredential(provider_models.UserTotpResource{
+				Key: ArrayOfUint16ToTerraformList([]uint16{1, 2, 3}),
+			}),
+			userSsoCredential(provider_models.UserSsoCredentialResource{
+				Email:    types.StringValue("alice@example.com"),
+				Provider: types.StringNull(),
+			}),
+			userPublicKeyCredential(provider_models.UserPublicKeyResource{
+				PublicKey: sshkeys.NewPublicKeyValue(testUserPublicKeyA),
+			}),
+		}
+
+		ids := []uuid.UUID{}
+
+		for _, credential := range credentials {
+			id, supported, err := addUserCredential(ctx, p.client, user.Id, credential)
+
+			if err != nil {
+				t.Fatalf("endpoints %t, add %s: %s", endpoints, credential.kind, err)
+			}
+
+			if supported != endpoints {
+				t.Errorf("expected the endpoints to be supported: %t, got %t", endpoints, supported)
+			}
+
+			ids = append(ids, id)
+		}
+
+		current, err := p.client.Users().Get(ctx, user.Id)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(current.Credentials) != len(credentials) {
+			t.Fatalf("endpoints %t: expected %d credentials, got %d", endpoints, len(credentials), len(current.Credentials))
+		}
+
+		for i, credential := range credentials {
+			found, err := findUserCredential(ctx, p.client, user.Id, ids[i], credential)
+
+			if err != nil || found == nil {
+				t.Errorf("endpoints %t: expected the %s credential to be found, got %v, %v", endpoints, credential.kind, found, err)
+			}
+		}
+
+		// the public key is removed, the other credentials are kept
+		publicKey := credentials[3]
+		userId, id := user.Id, ids[3]
+
+		if err := removeUserCredential(ctx, p.client, userId, id, publicKey); err != nil {
+			t.Fatal(err)
+		}
+
+		if found, err := findUserCredential(ctx, p.client, userId, id, publicKey); err != nil || found != nil {
+			t.Errorf("endpoints %t: expected the public key to be removed, got %v, %v", endpoints, found, err)
+		}
+
+		if err := removeUserCredential(ctx, p.client, userId, id, publicKey); err != nil {
+			t.Errorf("endpoints %t: expected a removed credential not to be an error, got %s", endpoints, err)
+		}
+
+		current, err = p.client.Users().Get(ctx, user.Id)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(current.Credentials) != len(credentials)-1 {
+			t.Errorf("endpoints %t: expected %d credentials, got %d", endpoints, len(credentials)-1, len(current.Credentials))
+		}
+	}
+}
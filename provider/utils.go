@@ -154,6 +154,14 @@ func TerraformStringToNullableString(str types.String) *string {
 	}
 }
 
+func NullableStringToTerraformString(str *string) types.String {
+	if str == nil {
+		return types.StringNull()
+	}
+
+	return types.StringValue(*str)
+}
+
 // func GetArraySortedToString(list types.List) (result []string) {
 
 // 	array_string := []string{}
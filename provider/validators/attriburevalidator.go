@@ -14,13 +14,6 @@ func IsDomain() validator.String {
 	)
 }
 
-func IsIpv4() validator.String {
-	return stringvalidator.RegexMatches(
-		regexp.MustCompile(`(\b25[0-5]|\b2[0-4][0-9]|\b[01]?[0-9][0-9]?)(\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)){3}`),
-		"Invalid ipv4",
-	)
-}
-
 func IsMacAddress() validator.String {
 	return stringvalidator.RegexMatches(
 		regexp.MustCompile(`^[a-fA-F0-9]{2}(:[a-fA-F0-9]{2}){5}$`),
@@ -0,0 +1,31 @@
+package validators
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// IsPositiveDuration accepts go durations greater than zero, e.g. "30s" or "2m".
+func IsPositiveDuration() validator.String {
+	return parsingValidator{
+		summary:     "Invalid duration",
+		description: "must be a positive duration such as \"30s\" or \"2m\"",
+		check:       checkPositiveDuration,
+	}
+}
+
+func checkPositiveDuration(value string) error {
+	duration, err := time.ParseDuration(value)
+
+	if err != nil {
+		return errors.New("it cannot be parsed")
+	}
+
+	if duration <= 0 {
+		return errors.New("it is not greater than zero")
+	}
+
+	return nil
+}
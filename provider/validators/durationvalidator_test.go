@@ -0,0 +1,15 @@
+package validators
+
+import "testing"
+
+func TestIsPositiveDuration(t *testing.T) {
+	runValidatorTestCases(t, IsPositiveDuration(), []validatorTestCase{
+		{"30s", ""},
+		{"1m30s", ""},
+		{"500ms", ""},
+		{"0s", "it is not greater than zero"},
+		{"-5s", "it is not greater than zero"},
+		{"30", "it cannot be parsed"},
+		{"ten seconds", "it cannot be parsed"},
+	})
+}
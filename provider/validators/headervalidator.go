@@ -0,0 +1,87 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ validator.Map = headerNamesConflictWithValidator{}
+
+type headerNamesConflictWithValidator struct {
+	expressions path.Expressions
+}
+
+// HeaderNamesConflictWith checks that none of the header names (the keys of
+// the map) is also set in the maps at the given paths. Header names are case
+// insensitive, so `Authorization` and `authorization` are the same header.
+func HeaderNamesConflictWith(expressions ...path.Expression) validator.Map {
+	return headerNamesConflictWithValidator{expressions: expressions}
+}
+
+func (v headerNamesConflictWithValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("header names must not be set in %s", v.expressions)
+}
+
+func (v headerNamesConflictWithValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v headerNamesConflictWithValidator) ValidateMap(ctx context.Context, req validator.MapRequest, resp *validator.MapResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for _, expression := range req.PathExpression.MergeExpressions(v.expressions...) {
+		matchedPaths, diags := req.Config.PathMatches(ctx, expression)
+		resp.Diagnostics.Append(diags...)
+
+		if diags.HasError() {
+			continue
+		}
+
+		for _, matchedPath := range matchedPaths {
+			if matchedPath.Equal(req.Path) {
+				continue
+			}
+
+			var other types.Map
+
+			diags := req.Config.GetAttribute(ctx, matchedPath, &other)
+			resp.Diagnostics.Append(diags...)
+
+			if diags.HasError() || other.IsNull() || other.IsUnknown() {
+				continue
+			}
+
+			otherNames := map[string]bool{}
+			for name := range other.Elements() {
+				otherNames[http.CanonicalHeaderKey(name)] = true
+			}
+
+			conflicts := []string{}
+			for name := range req.ConfigValue.Elements() {
+				if otherNames[http.CanonicalHeaderKey(name)] {
+					conflicts = append(conflicts, name)
+				}
+			}
+
+			if len(conflicts) == 0 {
+				continue
+			}
+
+			sort.Strings(conflicts)
+
+			resp.Diagnostics.AddAttributeError(
+				req.Path,
+				"Conflicting headers",
+				fmt.Sprintf("Attribute %s and attribute %s cannot both set the headers %q.", req.Path, matchedPath, conflicts),
+			)
+		}
+	}
+}
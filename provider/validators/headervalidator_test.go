@@ -0,0 +1,88 @@
+package validators
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestHeaderNamesConflictWith(t *testing.T) {
+	mapType := tftypes.Map{ElementType: tftypes.String}
+	objectType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"headers":           mapType,
+		"sensitive_headers": mapType,
+	}}
+
+	headersValue := func(headers map[string]string) tftypes.Value {
+		if headers == nil {
+			return tftypes.NewValue(mapType, nil)
+		}
+
+		values := map[string]tftypes.Value{}
+		for name, value := range headers {
+			values[name] = tftypes.NewValue(tftypes.String, value)
+		}
+
+		return tftypes.NewValue(mapType, values)
+	}
+
+	cases := []struct {
+		headers          map[string]string
+		sensitiveHeaders map[string]string
+		expectedError    string
+	}{
+		{map[string]string{"Host": "a"}, map[string]string{"Authorization": "b"}, ""},
+		{nil, map[string]string{"Authorization": "b"}, ""},
+		{map[string]string{"Authorization": "a"}, map[string]string{"Authorization": "b"}, `cannot both set the headers ["Authorization"]`},
+		{map[string]string{"authorization": "a"}, map[string]string{"Authorization": "b"}, `cannot both set the headers ["Authorization"]`},
+	}
+
+	for _, c := range cases {
+		config := tfsdk.Config{
+			Schema: schema.Schema{
+				Attributes: map[string]schema.Attribute{
+					"headers":           schema.MapAttribute{Optional: true, ElementType: types.StringType},
+					"sensitive_headers": schema.MapAttribute{Optional: true, ElementType: types.StringType},
+				},
+			},
+			Raw: tftypes.NewValue(objectType, map[string]tftypes.Value{
+				"headers":           headersValue(c.headers),
+				"sensitive_headers": headersValue(c.sensitiveHeaders),
+			}),
+		}
+
+		var configValue = types.MapNull(types.StringType)
+		config.GetAttribute(context.Background(), path.Root("sensitive_headers"), &configValue)
+
+		resp := validator.MapResponse{}
+		HeaderNamesConflictWith(path.MatchRoot("headers")).ValidateMap(context.Background(), validator.MapRequest{
+			Path:           path.Root("sensitive_headers"),
+			PathExpression: path.MatchRoot("sensitive_headers"),
+			ConfigValue:    configValue,
+			Config:         config,
+		}, &resp)
+
+		if c.expectedError == "" {
+			if resp.Diagnostics.HasError() {
+				t.Errorf("%v, %v: unexpected error %v", c.headers, c.sensitiveHeaders, resp.Diagnostics)
+			}
+			continue
+		}
+
+		if !resp.Diagnostics.HasError() {
+			t.Errorf("%v, %v: expected an error containing %q", c.headers, c.sensitiveHeaders, c.expectedError)
+			continue
+		}
+
+		if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, c.expectedError) {
+			t.Errorf("%v, %v: expected an error containing %q, got %q", c.headers, c.sensitiveHeaders, c.expectedError, detail)
+		}
+	}
+}
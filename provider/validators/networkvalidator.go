@@ -0,0 +1,255 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+var _ validator.String = parsingValidator{}
+
+// parsingValidator validates a string attribute by parsing it, the error
+// returned by check explains what exactly is wrong with the value.
+type parsingValidator struct {
+	summary     string
+	description string
+	check       func(value string) error
+}
+
+func (v parsingValidator) Description(_ context.Context) string {
+	return v.description
+}
+
+func (v parsingValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v parsingValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+
+	if err := v.check(value); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			v.summary,
+			fmt.Sprintf("Attribute %s value %q %s: %s.", req.Path, value, v.description, err),
+		)
+	}
+}
+
+// IsUrl accepts absolute urls with one of the given schemes and a valid host.
+func IsUrl(schemes ...string) validator.String {
+	return parsingValidator{
+		summary:     "Invalid URL",
+		description: fmt.Sprintf("must be a valid URL with scheme %s", strings.Join(schemes, " or ")),
+		check: func(value string) error {
+			return checkUrl(value, schemes)
+		},
+	}
+}
+
+// IsHostOrIp accepts hostnames (underscores are allowed), IPv4 and IPv6 addresses.
+func IsHostOrIp() validator.String {
+	return parsingValidator{
+		summary:     "Invalid host",
+		description: "must be a valid hostname, IPv4 or IPv6 address",
+		check:       checkHostOrIp,
+	}
+}
+
+// IsIpv4 accepts IPv4 addresses in dotted decimal notation.
+func IsIpv4() validator.String {
+	return parsingValidator{
+		summary:     "Invalid ipv4",
+		description: "must be a valid IPv4 address",
+		check: func(value string) error {
+			if ip := net.ParseIP(value); ip == nil || ip.To4() == nil || strings.Contains(value, ":") {
+				return fmt.Errorf("cannot be parsed as IPv4 address")
+			}
+
+			return nil
+		},
+	}
+}
+
+// IsHostPort accepts `host:port` where host is a hostname or an IP address
+// (IPv6 addresses must be enclosed in square brackets).
+func IsHostPort() validator.String {
+	return parsingValidator{
+		summary:     "Invalid host:port",
+		description: "must be in the form host:port",
+		check:       checkHostPort,
+	}
+}
+
+// IsHostOrHostPort accepts what IsHostOrIp accepts, optionally followed by a
+// port as accepted by IsHostPort, e.g. the Host header matched by the
+// external_host of http targets.
+func IsHostOrHostPort() validator.String {
+	return parsingValidator{
+		summary:     "Invalid host",
+		description: "must be a valid hostname, IPv4 or IPv6 address, optionally with a port",
+		check: func(value string) error {
+			// a url also splits into host and port, report it as a url
+			if _, _, err := net.SplitHostPort(value); err == nil && !strings.Contains(value, "/") {
+				return checkHostPort(value)
+			}
+
+			return checkHostOrIp(value)
+		},
+	}
+}
+
+// IsCidr accepts IPv4 and IPv6 networks in CIDR notation.
+func IsCidr() validator.String {
+	return parsingValidator{
+		summary:     "Invalid CIDR",
+		description: "must be a network in CIDR notation (e.g. 10.0.0.0/8)",
+		check: func(value string) error {
+			_, network, err := net.ParseCIDR(value)
+
+			if err != nil {
+				return fmt.Errorf("cannot be parsed as CIDR")
+			}
+
+			if network.String() != value {
+				return fmt.Errorf("host bits are set, the network is %s", network)
+			}
+
+			return nil
+		},
+	}
+}
+
+func checkUrl(value string, schemes []string) error {
+	parsed, err := url.Parse(value)
+
+	if err != nil {
+		return fmt.Errorf("cannot be parsed (%s)", err)
+	}
+
+	if parsed.Scheme == "" {
+		return fmt.Errorf("the scheme is missing")
+	}
+
+	allowed := false
+	for _, scheme := range schemes {
+		if strings.EqualFold(parsed.Scheme, scheme) {
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		return fmt.Errorf("scheme %q is not allowed", parsed.Scheme)
+	}
+
+	if parsed.Host == "" {
+		return fmt.Errorf("the host is missing")
+	}
+
+	if parsed.User != nil {
+		return fmt.Errorf("credentials in the URL are not supported")
+	}
+
+	if err := checkHostOrIp(parsed.Hostname()); err != nil {
+		return err
+	}
+
+	if port := parsed.Port(); port != "" {
+		return checkPort(port)
+	}
+
+	return nil
+}
+
+func checkHostPort(value string) error {
+	host, port, err := net.SplitHostPort(value)
+
+	if err != nil {
+		return fmt.Errorf("cannot be split into host and port (%s)", err)
+	}
+
+	if err := checkHostOrIp(host); err != nil {
+		return err
+	}
+
+	return checkPort(port)
+}
+
+func checkPort(port string) error {
+	number, err := strconv.Atoi(port)
+
+	if err != nil || number < 1 || number > 65535 {
+		return fmt.Errorf("port %q is not a number between 1 and 65535", port)
+	}
+
+	return nil
+}
+
+func checkHostOrIp(value string) error {
+	if value == "" {
+		return fmt.Errorf("the host is empty")
+	}
+
+	if net.ParseIP(value) != nil {
+		return nil
+	}
+
+	if strings.Contains(value, "/") {
+		return fmt.Errorf("a URL is not allowed here, only the hostname or IP address")
+	}
+
+	if strings.Contains(value, ":") {
+		if _, _, err := net.SplitHostPort(value); err == nil {
+			return fmt.Errorf("a port is not allowed here, only the hostname or IP address")
+		}
+
+		return fmt.Errorf("%q is not a valid IPv6 address", value)
+	}
+
+	return checkHostname(value)
+}
+
+func checkHostname(value string) error {
+	hostname := strings.TrimSuffix(value, ".")
+
+	if len(hostname) > 253 {
+		return fmt.Errorf("the hostname is longer than 253 characters")
+	}
+
+	labels := strings.Split(hostname, ".")
+
+	if _, err := strconv.Atoi(labels[len(labels)-1]); err == nil {
+		return fmt.Errorf("%q is neither a valid IPv4 address nor a hostname", value)
+	}
+
+	for _, label := range labels {
+		if label == "" {
+			return fmt.Errorf("the hostname contains an empty label")
+		}
+
+		if len(label) > 63 {
+			return fmt.Errorf("label %q is longer than 63 characters", label)
+		}
+
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("label %q starts or ends with a hyphen", label)
+		}
+
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return fmt.Errorf("label %q contains the invalid character %q", label, c)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package validators
+
+import (
+	"terraform-provider-warpgate/provider/sshkeys"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// IsSshPublicKey accepts a single public key in the authorized_keys format
+// (`<type> <base64> [comment]`).
+func IsSshPublicKey() validator.String {
+	return parsingValidator{
+		summary:     "Invalid ssh public key",
+		description: "must be a ssh public key in the authorized_keys format",
+		check: func(value string) error {
+			_, _, err := sshkeys.Parse(value)
+			return err
+		},
+	}
+}
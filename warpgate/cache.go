@@ -0,0 +1,356 @@
+package warpgate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// bulkReadThreshold is the number of single object reads of the same
+// collection after which they are served from the list of the collection.
+const bulkReadThreshold = 3
+
+// bulkCollections maps the path of a single object to the list of the
+// collection it belongs to, both return the same object schema.
+var bulkCollections = map[string]string{
+	"roles":   "/roles",
+	"targets": "/targets",
+	"users":   "/users",
+}
+
+// dependentCollections are the collections whose responses embed the objects
+// of another collection, they are invalidated with it: the users and targets
+// embed the names and ids of their roles, and the role assignment lists
+// (`/users/{id}/roles`, `/targets/{id}/roles`) change when a user, a target or
+// a role is written.
+var dependentCollections = map[string][]string{
+	"roles":   {"users", "targets"},
+	"users":   {"roles"},
+	"targets": {"roles"},
+}
+
+// uncachedCollections change without requests of the provider, e.g. the
+// sessions open and close when the users connect, so they are always read
+// from the server.
+var uncachedCollections = []string{"sessions"}
+
+type cachedResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+func (r *cachedResponse) toResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.statusCode, http.StatusText(r.statusCode)),
+		StatusCode:    r.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}
+}
+
+type cacheEntry struct {
+	response    *cachedResponse
+	collections []string
+}
+
+type inflightRequest struct {
+	done     chan struct{}
+	response *cachedResponse
+	err      error
+}
+
+// cachingTransport caches the successful GET responses of the admin api for
+// the lifetime of the client (a single terraform run).
+//
+// Identical requests in flight at the same time are sent only once, the
+// requests with `Cache-Control: no-cache` are always sent, and any other
+// request invalidates the cached responses of the collections in its
+// path and of their dependentCollections (e.g. `PUT /role/{id}` invalidates
+// `roles`, `users` and `targets`).
+type cachingTransport struct {
+	base http.RoundTripper
+
+	mutex    sync.Mutex
+	entries  map[string]cacheEntry
+	inflight map[string]*inflightRequest
+	// generations are bumped on every write, responses of requests started
+	// before the write are not cached.
+	generations map[string]uint64
+	// singleReads counts the reads of single objects since the last write.
+	singleReads map[string]int
+}
+
+func newCachingTransport(base http.RoundTripper) *cachingTransport {
+	return &cachingTransport{
+		base:        base,
+		entries:     map[string]cacheEntry{},
+		inflight:    map[string]*inflightRequest{},
+		generations: map[string]uint64{},
+		singleReads: map[string]int{},
+	}
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path, ok := strings.CutPrefix(req.URL.Path, WARPGATE_ENDPOINT_ADMIN_API)
+
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	collections := pathCollections(path)
+
+	if req.Method != http.MethodGet {
+		t.invalidate(collections)
+		return t.base.RoundTrip(req)
+	}
+
+	if len(collections) > 0 && slices.Contains(uncachedCollections, collections[0]) {
+		return t.base.RoundTrip(req)
+	}
+
+	// the caller needs the current state of the server, e.g. to detect the
+	// concurrent changes of a read-modify-write
+	if req.Header.Get("Cache-Control") == "no-cache" {
+		return t.base.RoundTrip(req)
+	}
+
+	if response := t.fromBulkRead(req, path); response != nil {
+		return response, nil
+	}
+
+	response, err := t.get(req, req.URL.String(), collections)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return response.toResponse(req), nil
+}
+
+// get sends the request unless the response is already cached or the same
+// request is in flight.
+func (t *cachingTransport) get(req *http.Request, key string, collections []string) (*cachedResponse, error) {
+	t.mutex.Lock()
+
+	if entry, ok := t.entries[key]; ok {
+		t.mutex.Unlock()
+		return entry.response, nil
+	}
+
+	if call, ok := t.inflight[key]; ok {
+		t.mutex.Unlock()
+		return call.wait(req.Context())
+	}
+
+	call := &inflightRequest{done: make(chan struct{})}
+	t.inflight[key] = call
+	generation := t.generation(collections)
+
+	t.mutex.Unlock()
+
+	// the request is shared with the waiters, so it is not cancelled with the
+	// context of the caller that started it
+	go t.fetch(req.WithContext(context.WithoutCancel(req.Context())), key, collections, generation, call)
+
+	return call.wait(req.Context())
+}
+
+// fetch sends the shared request of call and caches its response, unless the
+// collections have been written since generation.
+func (t *cachingTransport) fetch(req *http.Request, key string, collections []string, generation uint64, call *inflightRequest) {
+	call.response, call.err = t.send(req)
+
+	t.mutex.Lock()
+	delete(t.inflight, key)
+
+	if call.err == nil && call.response.statusCode == http.StatusOK && t.generation(collections) == generation {
+		t.entries[key] = cacheEntry{response: call.response, collections: collections}
+	}
+
+	t.mutex.Unlock()
+	close(call.done)
+}
+
+// wait returns the response of the request, or the error of ctx if it is
+// done first.
+func (call *inflightRequest) wait(ctx context.Context) (*cachedResponse, error) {
+	select {
+	case <-call.done:
+		return call.response, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (t *cachingTransport) send(req *http.Request) (*cachedResponse, error) {
+	response, err := t.base.RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	header := response.Header.Clone()
+	// the body is already decompressed by the transport
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+
+	return &cachedResponse{
+		statusCode: response.StatusCode,
+		header:     header,
+		body:       body,
+	}, nil
+}
+
+// fromBulkRead serves `GET /{collection}/{id}` from the list of the
+// collection once enough objects of the same collection have been read.
+// Returns nil when the object has to be requested on its own.
+func (t *cachingTransport) fromBulkRead(req *http.Request, path string) *http.Response {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(segments) != 2 || req.URL.RawQuery != "" {
+		return nil
+	}
+
+	collection := collectionName(segments[0])
+	listPath, ok := bulkCollections[collection]
+
+	if !ok {
+		return nil
+	}
+
+	id, err := uuid.Parse(segments[1])
+
+	if err != nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	t.singleReads[collection]++
+	reads := t.singleReads[collection]
+	t.mutex.Unlock()
+
+	if reads < bulkReadThreshold {
+		return nil
+	}
+
+	listURL := *req.URL
+	listURL.Path = WARPGATE_ENDPOINT_ADMIN_API + listPath
+
+	listRequest := req.Clone(req.Context())
+	listRequest.URL = &listURL
+
+	list, err := t.get(listRequest, listURL.String(), []string{collection})
+
+	if err != nil || list.statusCode != http.StatusOK {
+		return nil
+	}
+
+	var objects []json.RawMessage
+
+	if err := json.Unmarshal(list.body, &objects); err != nil {
+		return nil
+	}
+
+	for _, object := range objects {
+		var identified struct {
+			Id uuid.UUID `json:"id"`
+		}
+
+		if err := json.Unmarshal(object, &identified); err != nil || identified.Id != id {
+			continue
+		}
+
+		return (&cachedResponse{
+			statusCode: http.StatusOK,
+			header:     list.header,
+			body:       object,
+		}).toResponse(req)
+	}
+
+	// the object may have been created by someone else after the list
+	return nil
+}
+
+func (t *cachingTransport) invalidate(collections []string) {
+	written := collections
+	collections = slices.Clone(written)
+
+	for _, collection := range written {
+		for _, dependent := range dependentCollections[collection] {
+			if !slices.Contains(collections, dependent) {
+				collections = append(collections, dependent)
+			}
+		}
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, collection := range collections {
+		t.generations[collection]++
+		delete(t.singleReads, collection)
+	}
+
+	for key, entry := range t.entries {
+		if slices.ContainsFunc(entry.collections, func(collection string) bool {
+			return slices.Contains(collections, collection)
+		}) {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// generation must be called with the mutex held.
+func (t *cachingTransport) generation(collections []string) (generation uint64) {
+	for _, collection := range collections {
+		generation += t.generations[collection]
+	}
+
+	return generation
+}
+
+// pathCollections returns the collections named in the path, skipping the
+// ids, e.g. `/users/{id}/roles` is in `users` and `roles`.
+func pathCollections(path string) (collections []string) {
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if _, err := uuid.Parse(segment); err == nil || segment == "" {
+			continue
+		}
+
+		collections = append(collections, collectionName(segment))
+	}
+
+	return collections
+}
+
+// collectionName handles the single role endpoint, which is `/role/{id}`
+// instead of `/roles/{id}`.
+func collectionName(segment string) string {
+	if segment == "role" {
+		return "roles"
+	}
+
+	return segment
+}
@@ -0,0 +1,308 @@
+package warpgate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var testTargetIds = []uuid.UUID{
+	uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+	uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+	uuid.MustParse("33333333-3333-3333-3333-333333333333"),
+	uuid.MustParse("44444444-4444-4444-4444-444444444444"),
+}
+
+// testCacheServer returns a logged in client and the number of requests
+// received for each path.
+func testCacheServer(t *testing.T, delay time.Duration) (*WarpgateClient, func(path string) int) {
+	var mutex sync.Mutex
+	requests := map[string]int{}
+
+	target := func(id uuid.UUID) string {
+		return fmt.Sprintf(`{"id":"%s","name":"%s","allow_roles":[],"options":{"kind":"WebAdmin"}}`, id, id)
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, WARPGATE_ENDPOINT_ADMIN_API)
+
+		mutex.Lock()
+		requests[r.Method+" "+path]++
+		mutex.Unlock()
+
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == WARPGATE_ENDPOINT_LOGIN:
+			w.WriteHeader(201)
+		case path == "/targets" && r.Method == http.MethodGet:
+			targets := []string{}
+			for _, id := range testTargetIds {
+				targets = append(targets, target(id))
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(targets, ","))
+		case strings.HasPrefix(path, "/targets/") && r.Method == http.MethodGet:
+			id, err := uuid.Parse(strings.TrimPrefix(path, "/targets/"))
+			if err != nil {
+				w.WriteHeader(404)
+				return
+			}
+			fmt.Fprint(w, target(id))
+		case path == "/sessions" && r.Method == http.MethodGet:
+			fmt.Fprint(w, `{"items":[],"offset":0,"total":0}`)
+		case r.Method == http.MethodPut:
+			fmt.Fprint(w, target(testTargetIds[0]))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	serverUrl, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverUrl.Port())
+
+	client := NewWarpgateClient(serverUrl.Hostname(), port, true, RequestLimits{})
+
+	if err := client.Login("admin", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	return client, func(path string) int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return requests[path]
+	}
+}
+
+func TestCachingTransportInvalidation(t *testing.T) {
+	client, requests := testCacheServer(t, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		response, err := client.GetTargetsWithResponse(ctx)
+
+		if err != nil || response.JSON200 == nil || len(*response.JSON200) != len(testTargetIds) {
+			t.Fatalf("unexpected response %v, %v", response, err)
+		}
+	}
+
+	if requests("GET /targets") != 1 {
+		t.Errorf("expected the list to be requested once, got %d", requests("GET /targets"))
+	}
+
+	if _, err := client.UpdateTargetWithResponse(ctx, testTargetIds[0], UpdateTargetJSONRequestBody{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetTargetsWithResponse(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests("GET /targets") != 2 {
+		t.Errorf("expected the list to be requested again after a write, got %d", requests("GET /targets"))
+	}
+
+	// other collections are not invalidated
+	if _, err := client.GetRolesWithResponse(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetTargetsWithResponse(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests("GET /targets") != 2 {
+		t.Errorf("expected the list to stay cached, got %d", requests("GET /targets"))
+	}
+}
+
+// The users and targets embed their roles, a write of a role invalidates them.
+func TestCachingTransportDependentInvalidation(t *testing.T) {
+	client, requests := testCacheServer(t, 0)
+	ctx := context.Background()
+
+	if _, err := client.GetTargetsWithResponse(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.UpdateRoleWithResponse(ctx, uuid.New(), UpdateRoleJSONRequestBody{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetTargetsWithResponse(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests("GET /targets") != 2 {
+		t.Errorf("expected the targets to be requested again after a role write, got %d", requests("GET /targets"))
+	}
+}
+
+func TestCachingTransportUncachedCollections(t *testing.T) {
+	client, requests := testCacheServer(t, 0)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Sessions().List(context.Background(), true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if requests("GET /sessions") != 2 {
+		t.Errorf("expected the sessions to be requested every time, got %d", requests("GET /sessions"))
+	}
+}
+
+func TestCachingTransportNoCache(t *testing.T) {
+	client, requests := testCacheServer(t, 0)
+
+	noCache := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Cache-Control", "no-cache")
+		return nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetTargetsWithResponse(context.Background(), noCache); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if requests("GET /targets") != 2 {
+		t.Errorf("expected the targets to be requested every time, got %d", requests("GET /targets"))
+	}
+}
+
+func TestCachingTransportSingleFlight(t *testing.T) {
+	client, requests := testCacheServer(t, 50*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			response, err := client.GetTargetsWithResponse(context.Background())
+
+			if err != nil || response.JSON200 == nil {
+				t.Errorf("unexpected response %v, %v", response, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if requests("GET /targets") != 1 {
+		t.Errorf("expected a single request, got %d", requests("GET /targets"))
+	}
+}
+
+// A caller giving up does not fail the other callers waiting for the same
+// request.
+func TestCachingTransportSingleFlightCancel(t *testing.T) {
+	client, requests := testCacheServer(t, 100*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+
+	go func() {
+		_, err := client.GetTargetsWithResponse(ctx)
+		first <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+
+	second := make(chan error, 1)
+
+	go func() {
+		response, err := client.GetTargetsWithResponse(context.Background())
+
+		if err == nil && response.JSON200 == nil {
+			err = fmt.Errorf("unexpected response %v", response)
+		}
+
+		second <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancelled caller to fail, got %v", err)
+	}
+
+	if err := <-second; err != nil {
+		t.Errorf("expected the waiting caller to succeed, got %v", err)
+	}
+
+	if requests("GET /targets") != 1 {
+		t.Errorf("expected a single request, got %d", requests("GET /targets"))
+	}
+}
+
+func TestCachingTransportBulkRead(t *testing.T) {
+	client, requests := testCacheServer(t, 0)
+	ctx := context.Background()
+
+	for _, id := range testTargetIds {
+		response, err := client.GetTargetWithResponse(ctx, id)
+
+		if err != nil || response.JSON200 == nil {
+			t.Fatalf("unexpected response %v, %v", response, err)
+		}
+
+		if response.JSON200.Id != id {
+			t.Errorf("expected target %s, got %s", id, response.JSON200.Id)
+		}
+	}
+
+	for _, id := range testTargetIds[:bulkReadThreshold-1] {
+		if requests("GET /targets/"+id.String()) != 1 {
+			t.Errorf("expected target %s to be requested on its own", id)
+		}
+	}
+
+	for _, id := range testTargetIds[bulkReadThreshold-1:] {
+		if requests("GET /targets/"+id.String()) != 0 {
+			t.Errorf("expected target %s to be served from the list", id)
+		}
+	}
+
+	if requests("GET /targets") != 1 {
+		t.Errorf("expected the list to be requested once, got %d", requests("GET /targets"))
+	}
+
+	// unknown objects are requested on their own
+	unknown := uuid.New()
+
+	if _, err := client.GetTargetWithResponse(ctx, unknown); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests("GET /targets/"+unknown.String()) != 1 {
+		t.Errorf("expected the unknown target to be requested")
+	}
+}
+
+func TestPathCollections(t *testing.T) {
+	cases := map[string]string{
+		"/targets": "targets",
+		"/users/11111111-1111-1111-1111-111111111111/roles/22222222-2222-2222-2222-222222222222": "users,roles",
+		"/role/11111111-1111-1111-1111-111111111111":                                             "roles",
+		"/ssh/own-keys": "ssh,own-keys",
+	}
+
+	for path, expected := range cases {
+		if actual := strings.Join(pathCollections(path), ","); actual != expected {
+			t.Errorf("%s: expected %s, got %s", path, expected, actual)
+		}
+	}
+}
@@ -206,6 +206,16 @@ type TargetOptionsTargetMySqlOptions struct {
 	Username string  `json:"username"`
 }
 
+// TargetOptionsTargetPostgresOptions defines model for TargetOptions_TargetPostgresOptions.
+type TargetOptionsTargetPostgresOptions struct {
+	Host     string  `json:"host"`
+	Kind     string  `json:"kind"`
+	Password *string `json:"password,omitempty"`
+	Port     uint16  `json:"port"`
+	Tls      Tls     `json:"tls"`
+	Username string  `json:"username"`
+}
+
 // TargetOptionsTargetSSHOptions defines model for TargetOptions_TargetSSHOptions.
 type TargetOptionsTargetSSHOptions struct {
 	Auth     SSHTargetAuth `json:"auth"`
@@ -220,6 +230,15 @@ type TargetOptionsTargetWebAdminOptions struct {
 	Kind string `json:"kind"`
 }
 
+// TargetPostgresOptions defines model for TargetPostgresOptions.
+type TargetPostgresOptions struct {
+	Host     string  `json:"host"`
+	Password *string `json:"password,omitempty"`
+	Port     uint16  `json:"port"`
+	Tls      Tls     `json:"tls"`
+	Username string  `json:"username"`
+}
+
 // TargetSSHOptions defines model for TargetSSHOptions.
 type TargetSSHOptions struct {
 	Auth     SSHTargetAuth `json:"auth"`
@@ -314,9 +333,10 @@ type UserPublicKeyCredential struct {
 
 // UserRequireCredentialsPolicy defines model for UserRequireCredentialsPolicy.
 type UserRequireCredentialsPolicy struct {
-	Http  *[]CredentialKind `json:"http,omitempty"`
-	Mysql *[]CredentialKind `json:"mysql,omitempty"`
-	Ssh   *[]CredentialKind `json:"ssh,omitempty"`
+	Http     *[]CredentialKind `json:"http,omitempty"`
+	Mysql    *[]CredentialKind `json:"mysql,omitempty"`
+	Postgres *[]CredentialKind `json:"postgres,omitempty"`
+	Ssh      *[]CredentialKind `json:"ssh,omitempty"`
 }
 
 // UserSsoCredential defines model for UserSsoCredential.
@@ -535,6 +555,34 @@ func (t *TargetOptions) MergeTargetOptionsTargetMySqlOptions(v TargetOptionsTarg
 	return err
 }
 
+// AsTargetOptionsTargetPostgresOptions returns the union data inside the TargetOptions as a TargetOptionsTargetPostgresOptions
+func (t TargetOptions) AsTargetOptionsTargetPostgresOptions() (TargetOptionsTargetPostgresOptions, error) {
+	var body TargetOptionsTargetPostgresOptions
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromTargetOptionsTargetPostgresOptions overwrites any union data inside the TargetOptions as the provided TargetOptionsTargetPostgresOptions
+func (t *TargetOptions) FromTargetOptionsTargetPostgresOptions(v TargetOptionsTargetPostgresOptions) error {
+	v.Kind = "Postgres"
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeTargetOptionsTargetPostgresOptions performs a merge with any union data inside the TargetOptions, using the provided TargetOptionsTargetPostgresOptions
+func (t *TargetOptions) MergeTargetOptionsTargetPostgresOptions(v TargetOptionsTargetPostgresOptions) error {
+	v.Kind = "Postgres"
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JsonMerge(b, t.union)
+	t.union = merged
+	return err
+}
+
 // AsTargetOptionsTargetWebAdminOptions returns the union data inside the TargetOptions as a TargetOptionsTargetWebAdminOptions
 func (t TargetOptions) AsTargetOptionsTargetWebAdminOptions() (TargetOptionsTargetWebAdminOptions, error) {
 	var body TargetOptionsTargetWebAdminOptions
@@ -581,6 +629,8 @@ func (t TargetOptions) ValueByDiscriminator() (interface{}, error) {
 		return t.AsTargetOptionsTargetHTTPOptions()
 	case "MySql":
 		return t.AsTargetOptionsTargetMySqlOptions()
+	case "Postgres":
+		return t.AsTargetOptionsTargetPostgresOptions()
 	case "Ssh":
 		return t.AsTargetOptionsTargetSSHOptions()
 	case "WebAdmin":
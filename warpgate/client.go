@@ -1,13 +1,17 @@
 package warpgate
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/cookiejar"
 	"strings"
+	"sync"
 	models "terraform-provider-warpgate/warpgate/models"
 )
 
@@ -17,9 +21,17 @@ type WarpgateClient struct {
 	Port       int
 	url        string
 	httpClient *http.Client
+
+	// credentialEndpoints caches if the server has the per-credential
+	// endpoints, see UserCredentialsService.Supported.
+	credentialEndpointsMutex sync.Mutex
+	credentialEndpoints      *bool
+	// userLocks serializes the read-modify-write of the credentials of each
+	// user, see UserCredentialsService.Update.
+	userLocks sync.Map
 }
 
-func NewWarpgateClient(address string, port int, insecureSkipVerify bool) *WarpgateClient {
+func NewWarpgateClient(address string, port int, insecureSkipVerify bool, limits RequestLimits) *WarpgateClient {
 	jar, _ := cookiejar.New(nil)
 
 	return &WarpgateClient{
@@ -27,9 +39,12 @@ func NewWarpgateClient(address string, port int, insecureSkipVerify bool) *Warpg
 		Port:    port,
 		url:     fmt.Sprintf("https://%s:%d", address, port),
 		httpClient: &http.Client{
-			Transport: &http.Transport{
+			// GET responses are cached for the lifetime of the client, see
+			// cachingTransport, only the requests actually sent are limited
+			// and the timeout starts once the limits allow the request
+			Transport: newCachingTransport(newLimitingTransport(newTimeoutTransport(&http.Transport{
 				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify},
-			},
+			}, limits.Timeout), limits)),
 			Jar: jar,
 		},
 	}
@@ -74,3 +89,61 @@ func (c *WarpgateClient) Login(username string, password string) (err error) {
 
 	return
 }
+
+// rawResponse is the apiResponse of the requests sent by doJSON.
+type rawResponse int
+
+func (r rawResponse) StatusCode() int {
+	return int(r)
+}
+
+// doJSON sends a request to the endpoint (the path from the root of the
+// server), for the endpoints missing from the generated client. The body and
+// the result are encoded as json.
+func (c *WarpgateClient) doJSON(ctx context.Context, operation string, method string, endpoint string, body interface{}, result interface{}, expected int) error {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url+endpoint, reader)
+
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		return err
+	}
+
+	if err := checkStatus(operation, rawResponse(response.StatusCode), data, expected); err != nil {
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, result)
+}
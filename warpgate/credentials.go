@@ -0,0 +1,188 @@
+package warpgate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// credentialPaths are the paths of the per-credential endpoints of each kind
+// of credential, under `/users/{id}/credentials`.
+var credentialPaths = map[CredentialKind]string{
+	Password:  "passwords",
+	PublicKey: "public-keys",
+	Sso:       "sso",
+	Totp:      "otp",
+}
+
+// UserCredential is a credential returned by the per-credential endpoints,
+// which never return the secrets (password and totp key).
+type UserCredential struct {
+	Id               uuid.UUID `json:"id"`
+	Email            string    `json:"email,omitempty"`
+	Provider         *string   `json:"provider,omitempty"`
+	OpensshPublicKey string    `json:"openssh_public_key,omitempty"`
+}
+
+// NewUserCredential is the credential to add with the per-credential
+// endpoints, only the fields of its kind are set. The password is sent in
+// clear text and hashed by warpgate.
+type NewUserCredential struct {
+	Password         string   `json:"password,omitempty"`
+	SecretKey        []uint16 `json:"secret_key,omitempty"`
+	Email            string   `json:"email,omitempty"`
+	Provider         *string  `json:"provider,omitempty"`
+	OpensshPublicKey string   `json:"openssh_public_key,omitempty"`
+}
+
+// UserCredentialsService is the typed api of the credentials of a user.
+//
+// Recent warpgate servers manage every credential with its own endpoints,
+// the older ones only with the update of the whole user (see Update).
+type UserCredentialsService struct {
+	client *WarpgateClient
+	userId uuid.UUID
+}
+
+// Credentials returns the api of the credentials of the user.
+func (s *UsersService) Credentials(id uuid.UUID) *UserCredentialsService {
+	return &UserCredentialsService{client: s.client, userId: id}
+}
+
+// Supported reports if the server has the per-credential endpoints, the
+// result is cached for the lifetime of the client. ErrNotFound is returned if
+// the user does not exist.
+func (s *UserCredentialsService) Supported(ctx context.Context) (bool, error) {
+	s.client.credentialEndpointsMutex.Lock()
+	defer s.client.credentialEndpointsMutex.Unlock()
+
+	if s.client.credentialEndpoints != nil {
+		return *s.client.credentialEndpoints, nil
+	}
+
+	_, err := s.List(ctx, Password)
+
+	if errors.Is(err, ErrNotFound) {
+		// either the endpoint or the user is missing
+		if _, err := s.client.Users().Get(ctx, s.userId); err != nil {
+			return false, err
+		}
+	} else if err != nil {
+		return false, err
+	}
+
+	supported := err == nil
+	s.client.credentialEndpoints = &supported
+
+	return supported, nil
+}
+
+func (s *UserCredentialsService) List(ctx context.Context, kind CredentialKind) ([]UserCredential, error) {
+	var credentials []UserCredential
+
+	err := s.client.doJSON(ctx, fmt.Sprintf("list %s credentials", kind), http.MethodGet, s.path(kind), nil, &credentials, 200)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+func (s *UserCredentialsService) Add(ctx context.Context, kind CredentialKind, credential NewUserCredential) (*UserCredential, error) {
+	var created UserCredential
+
+	err := s.client.doJSON(ctx, fmt.Sprintf("add %s credential", kind), http.MethodPost, s.path(kind), credential, &created, 201)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (s *UserCredentialsService) Delete(ctx context.Context, kind CredentialKind, id uuid.UUID) error {
+	return s.client.doJSON(ctx, fmt.Sprintf("delete %s credential", kind), http.MethodDelete, fmt.Sprintf("%s/%s", s.path(kind), id), nil, nil, 204)
+}
+
+// Update modifies the user, usually its credentials, with a read-modify-write
+// of the whole user, for the servers without the per-credential endpoints.
+// modify receives the current username, credentials and credential policy.
+//
+// The updates of the same user by this client are serialized. Before the
+// update the user is read again bypassing the cache, and ErrConflict is
+// returned if its credentials or credential policy changed since they were
+// passed to modify, e.g. by another terraform run. Warpgate has no
+// precondition on the update of a user, so a change made between this last
+// read and the update is still lost.
+func (s *UserCredentialsService) Update(ctx context.Context, modify func(user *UserDataRequest) error) (*User, error) {
+	lock, _ := s.client.userLocks.LoadOrStore(s.userId, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	read, err := s.currentUser(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user := UserDataRequest{
+		Username:         read.Username,
+		Credentials:      slices.Clone(read.Credentials),
+		CredentialPolicy: read.CredentialPolicy,
+	}
+
+	if err := modify(&user); err != nil {
+		return nil, err
+	}
+
+	current, err := s.currentUser(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !sameCredentials(read, current) {
+		return nil, &StatusError{
+			Operation:  "update credentials of user",
+			StatusCode: http.StatusConflict,
+			Message:    "the credentials of the user changed since they were read",
+		}
+	}
+
+	return s.client.Users().Update(ctx, s.userId, user)
+}
+
+// currentUser reads the user bypassing the cache.
+func (s *UserCredentialsService) currentUser(ctx context.Context) (*User, error) {
+	response, err := s.client.GetUserWithResponse(ctx, s.userId, func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Cache-Control", "no-cache")
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checkJSON("read user", response, response.Body, 200, response.JSON200)
+}
+
+func (s *UserCredentialsService) path(kind CredentialKind) string {
+	return fmt.Sprintf("%s/users/%s/credentials/%s", WARPGATE_ENDPOINT_ADMIN_API, s.userId, credentialPaths[kind])
+}
+
+// sameCredentials reports if both users have the same credentials and
+// credential policy.
+func sameCredentials(a *User, b *User) bool {
+	aJson, aErr := json.Marshal([]any{a.Credentials, a.CredentialPolicy})
+	bJson, bErr := json.Marshal([]any{b.Credentials, b.CredentialPolicy})
+
+	return aErr == nil && bErr == nil && bytes.Equal(aJson, bJson)
+}
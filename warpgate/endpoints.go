@@ -2,4 +2,8 @@ package warpgate
 
 const WARPGATE_ENDPOINT_LOGIN = "/@warpgate/api/auth/login"
 
+// WARPGATE_ENDPOINT_SSO_PROVIDERS lists the sso providers of the config
+// file, it is public as it is used by the login page.
+const WARPGATE_ENDPOINT_SSO_PROVIDERS = "/@warpgate/api/sso/providers"
+
 const WARPGATE_ENDPOINT_ADMIN_API = "/@warpgate/admin/api"
@@ -0,0 +1,100 @@
+package warpgate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when the object does not exist (404).
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when the request conflicts with the current
+	// state of the server, e.g. a role already assigned (409).
+	ErrConflict = errors.New("conflict")
+	// ErrBadRequest is returned when warpgate rejects the request (400).
+	ErrBadRequest = errors.New("bad request")
+)
+
+// StatusError is returned when warpgate answers with an unexpected status
+// code, use errors.Is with ErrNotFound, ErrConflict and ErrBadRequest to
+// check for the common cases.
+type StatusError struct {
+	// Operation is what was requested, e.g. "read user".
+	Operation  string
+	StatusCode int
+	// Message is the body of the response, if any.
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("failed to %s: %s (Error code: %d)", e.Operation, e.Message, e.StatusCode)
+	}
+
+	return fmt.Sprintf("failed to %s (Error code: %d)", e.Operation, e.StatusCode)
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == 404
+	case ErrConflict:
+		return e.StatusCode == 409
+	case ErrBadRequest:
+		return e.StatusCode == 400
+	}
+
+	return false
+}
+
+// apiResponse is implemented by all the responses of ClientWithResponses.
+type apiResponse interface {
+	StatusCode() int
+}
+
+// checkStatus returns a StatusError unless the response has the expected
+// status code.
+func checkStatus(operation string, response apiResponse, body []byte, expected int) error {
+	if response.StatusCode() == expected {
+		return nil
+	}
+
+	return &StatusError{
+		Operation:  operation,
+		StatusCode: response.StatusCode(),
+		Message:    responseMessage(body),
+	}
+}
+
+// checkJSON is checkStatus for the responses with a json body: a StatusError
+// is also returned when the body could not be decoded, e.g. if it is not json
+// or it was sent without the json content type.
+func checkJSON[T any](operation string, response apiResponse, body []byte, expected int, value *T) (*T, error) {
+	if err := checkStatus(operation, response, body, expected); err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return nil, &StatusError{
+			Operation:  operation,
+			StatusCode: response.StatusCode(),
+			Message:    "the response has no json body",
+		}
+	}
+
+	return value, nil
+}
+
+// responseMessage returns the error message of the body, warpgate sends
+// them as json strings.
+func responseMessage(body []byte) string {
+	var message string
+
+	if err := json.Unmarshal(body, &message); err == nil {
+		return message
+	}
+
+	return strings.TrimSpace(string(body))
+}
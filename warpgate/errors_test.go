@@ -0,0 +1,77 @@
+package warpgate
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// A 200 response that is not json, e.g. the page of a proxy, is an error
+// instead of a nil dereference.
+func TestResponseWithoutJsonBody(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == WARPGATE_ENDPOINT_LOGIN {
+			w.WriteHeader(201)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>maintenance</html>"))
+	}))
+	t.Cleanup(server.Close)
+
+	serverUrl, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverUrl.Port())
+
+	client := NewWarpgateClient(serverUrl.Hostname(), port, true, RequestLimits{})
+
+	if err := client.Login("admin", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	calls := map[string]func() error{
+		"list users": func() error {
+			_, err := client.Users().List(ctx)
+			return err
+		},
+		"read user": func() error {
+			_, err := client.Users().Get(ctx, uuid.New())
+			return err
+		},
+		"list roles": func() error {
+			_, err := client.Roles().List(ctx)
+			return err
+		},
+		"list targets": func() error {
+			_, err := client.Targets().List(ctx, TargetKindSsh)
+			return err
+		},
+		"list sessions": func() error {
+			_, err := client.Sessions().List(ctx, true)
+			return err
+		},
+	}
+
+	for operation, call := range calls {
+		err := call()
+
+		var statusError *StatusError
+
+		if !errors.As(err, &statusError) {
+			t.Errorf("%s: expected a StatusError, got %v", operation, err)
+			continue
+		}
+
+		if statusError.Operation != operation || statusError.StatusCode != 200 {
+			t.Errorf("%s: unexpected error %v", operation, statusError)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package warpgate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RequestLimits bounds the requests sent to the warpgate server by a client,
+// zero values mean no limit.
+type RequestLimits struct {
+	// MaxConcurrentRequests is the number of requests in flight at the same
+	// time.
+	MaxConcurrentRequests int
+	// RequestsPerSecond is the rate at which the requests are started.
+	RequestsPerSecond float64
+	// Timeout is the maximum duration of a single request, the time spent
+	// waiting for the other limits is not counted.
+	Timeout time.Duration
+}
+
+// limitingTransport enforces the RequestLimits on all the requests of a
+// client, whatever the number of resources refreshed in parallel.
+type limitingTransport struct {
+	base http.RoundTripper
+
+	// slots is nil without a concurrency limit
+	slots chan struct{}
+	// interval is zero without a rate limit
+	interval time.Duration
+
+	mutex sync.Mutex
+	// next is the earliest time the next request can be started
+	next time.Time
+}
+
+func newLimitingTransport(base http.RoundTripper, limits RequestLimits) http.RoundTripper {
+	if limits.MaxConcurrentRequests <= 0 && limits.RequestsPerSecond <= 0 {
+		return base
+	}
+
+	t := &limitingTransport{base: base}
+
+	if limits.MaxConcurrentRequests > 0 {
+		t.slots = make(chan struct{}, limits.MaxConcurrentRequests)
+	}
+
+	if limits.RequestsPerSecond > 0 {
+		t.interval = time.Duration(float64(time.Second) / limits.RequestsPerSecond)
+	}
+
+	return t
+}
+
+func (t *limitingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if t.slots != nil {
+		select {
+		case t.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		defer func() { <-t.slots }()
+	}
+
+	if delay := t.reserve(); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// reserve takes the next free start time and returns how long to wait for it.
+func (t *limitingTransport) reserve() time.Duration {
+	if t.interval == 0 {
+		return 0
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+
+	if t.next.Before(now) {
+		t.next = now
+	}
+
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+
+	return delay
+}
+
+// timeoutTransport cancels the requests taking longer than timeout, e.g.
+// when the warpgate server hangs.
+type timeoutTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+func newTimeoutTransport(base http.RoundTripper, timeout time.Duration) http.RoundTripper {
+	if timeout <= 0 {
+		return base
+	}
+
+	return &timeoutTransport{base: base, timeout: timeout}
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+
+	response, err := t.base.RoundTrip(req.WithContext(ctx))
+
+	if err != nil {
+		cancel()
+
+		if req.Context().Err() == nil && ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("request timed out after %s: %w", t.timeout, err)
+		}
+
+		return nil, err
+	}
+
+	// the timeout applies to the body too, it is released once read
+	response.Body = &cancelingBody{ReadCloser: response.Body, cancel: cancel}
+
+	return response, nil
+}
+
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelingBody) Close() error {
+	defer b.cancel()
+
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,160 @@
+package warpgate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingTransport struct {
+	running    int32
+	maxRunning int32
+	started    []time.Time
+	mutex      sync.Mutex
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	running := atomic.AddInt32(&t.running, 1)
+	defer atomic.AddInt32(&t.running, -1)
+
+	t.mutex.Lock()
+	t.started = append(t.started, time.Now())
+	if running > t.maxRunning {
+		t.maxRunning = running
+	}
+	t.mutex.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	return httptest.NewRecorder().Result(), nil
+}
+
+func runLimitedRequests(t *testing.T, transport http.RoundTripper, count int) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req := httptest.NewRequest(http.MethodGet, "https://warpgate/", nil)
+
+			if _, err := transport.RoundTrip(req); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestLimitingTransportConcurrency(t *testing.T) {
+	base := &countingTransport{}
+
+	runLimitedRequests(t, newLimitingTransport(base, RequestLimits{MaxConcurrentRequests: 2}), 10)
+
+	if base.maxRunning != 2 {
+		t.Errorf("expected 2 concurrent requests, got %d", base.maxRunning)
+	}
+}
+
+func TestLimitingTransportRate(t *testing.T) {
+	base := &countingTransport{}
+
+	runLimitedRequests(t, newLimitingTransport(base, RequestLimits{RequestsPerSecond: 50}), 6)
+
+	if len(base.started) != 6 {
+		t.Fatalf("expected 6 requests, got %d", len(base.started))
+	}
+
+	// 6 requests at 50 per second span at least 100ms
+	first, last := base.started[0], base.started[0]
+	for _, started := range base.started {
+		if started.Before(first) {
+			first = started
+		}
+		if started.After(last) {
+			last = started
+		}
+	}
+
+	if span := last.Sub(first); span < 90*time.Millisecond {
+		t.Errorf("expected the requests to span at least 100ms, got %s", span)
+	}
+}
+
+func TestLimitingTransportUnlimited(t *testing.T) {
+	base := &countingTransport{}
+
+	if newLimitingTransport(base, RequestLimits{}) != http.RoundTripper(base) {
+		t.Error("expected the base transport without limits")
+	}
+}
+
+// sleepingTransport answers after delay, unless the request is canceled.
+type sleepingTransport struct {
+	delay time.Duration
+}
+
+func (t *sleepingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case <-time.After(t.delay):
+		return httptest.NewRecorder().Result(), nil
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+}
+
+func TestTimeoutTransport(t *testing.T) {
+	transport := newTimeoutTransport(&sleepingTransport{delay: time.Second}, 20*time.Millisecond)
+
+	_, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://warpgate/", nil))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the request to time out, got %v", err)
+	}
+}
+
+func TestTimeoutTransportExcludesLimits(t *testing.T) {
+	// every request takes 20ms, the last one waits 80ms for its slot, more
+	// than the timeout, but is answered in time once sent
+	transport := newLimitingTransport(
+		newTimeoutTransport(&sleepingTransport{delay: 20 * time.Millisecond}, 60*time.Millisecond),
+		RequestLimits{MaxConcurrentRequests: 1},
+	)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			response, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://warpgate/", nil))
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestTimeoutTransportUnlimited(t *testing.T) {
+	base := &countingTransport{}
+
+	if newTimeoutTransport(base, 0) != http.RoundTripper(base) {
+		t.Error("expected the base transport without timeout")
+	}
+}
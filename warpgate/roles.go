@@ -0,0 +1,72 @@
+package warpgate
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RolesService is the typed api of the roles.
+type RolesService struct {
+	client *WarpgateClient
+}
+
+func (c *WarpgateClient) Roles() *RolesService {
+	return &RolesService{client: c}
+}
+
+func (s *RolesService) List(ctx context.Context) ([]Role, error) {
+	response, err := s.client.GetRolesWithResponse(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := checkJSON("list roles", response, response.Body, 200, response.JSON200)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return *roles, nil
+}
+
+func (s *RolesService) Get(ctx context.Context, id uuid.UUID) (*Role, error) {
+	response, err := s.client.GetRoleWithResponse(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checkJSON("read role", response, response.Body, 200, response.JSON200)
+}
+
+func (s *RolesService) Create(ctx context.Context, data RoleDataRequest) (*Role, error) {
+	response, err := s.client.CreateRoleWithResponse(ctx, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checkJSON("create role", response, response.Body, 201, response.JSON201)
+}
+
+func (s *RolesService) Update(ctx context.Context, id uuid.UUID, data RoleDataRequest) (*Role, error) {
+	response, err := s.client.UpdateRoleWithResponse(ctx, id, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checkJSON("update role", response, response.Body, 200, response.JSON200)
+}
+
+func (s *RolesService) Delete(ctx context.Context, id uuid.UUID) error {
+	response, err := s.client.DeleteRoleWithResponse(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return checkStatus("delete role", response, response.Body, 204)
+}
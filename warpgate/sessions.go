@@ -0,0 +1,74 @@
+package warpgate
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// sessionsPageSize is the number of sessions requested at a time by List.
+const sessionsPageSize = 100
+
+// SessionsService is the typed api of the sessions of the users on the
+// targets.
+type SessionsService struct {
+	client *WarpgateClient
+}
+
+func (c *WarpgateClient) Sessions() *SessionsService {
+	return &SessionsService{client: c}
+}
+
+// List returns all the sessions, requesting every page, or only the ones not
+// ended yet with activeOnly.
+func (s *SessionsService) List(ctx context.Context, activeOnly bool) ([]SessionSnapshot, error) {
+	sessions := []SessionSnapshot{}
+	offset := uint64(0)
+	limit := uint64(sessionsPageSize)
+
+	for {
+		response, err := s.client.GetSessionsWithResponse(ctx, &GetSessionsParams{
+			Offset:     &offset,
+			Limit:      &limit,
+			ActiveOnly: &activeOnly,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		page, err := checkJSON("list sessions", response, response.Body, 200, response.JSON200)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, page.Items...)
+		offset += uint64(len(page.Items))
+
+		if len(page.Items) == 0 || offset >= page.Total {
+			return sessions, nil
+		}
+	}
+}
+
+func (s *SessionsService) Close(ctx context.Context, id uuid.UUID) error {
+	response, err := s.client.CloseSessionWithResponse(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return checkStatus("close session", response, response.Body, 201)
+}
+
+// CloseAll closes the sessions of all the users on all the targets.
+func (s *SessionsService) CloseAll(ctx context.Context) error {
+	response, err := s.client.CloseAllSessionsWithResponse(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return checkStatus("close all sessions", response, response.Body, 201)
+}
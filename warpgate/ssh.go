@@ -0,0 +1,31 @@
+package warpgate
+
+import (
+	"context"
+)
+
+// SshService is the typed api of the ssh keys of warpgate.
+type SshService struct {
+	client *WarpgateClient
+}
+
+func (c *WarpgateClient) Ssh() *SshService {
+	return &SshService{client: c}
+}
+
+// OwnKeys returns the keys used by warpgate to connect to the ssh targets.
+func (s *SshService) OwnKeys(ctx context.Context) ([]SSHKey, error) {
+	response, err := s.client.GetSshOwnKeysWithResponse(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := checkJSON("list own ssh keys", response, response.Body, 200, response.JSON200)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return *keys, nil
+}
@@ -0,0 +1,37 @@
+package warpgate
+
+import (
+	"context"
+	"net/http"
+)
+
+// SsoProvider is an sso provider of the warpgate config file, Name is the
+// provider of the Sso credentials.
+type SsoProvider struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Kind  string `json:"kind"`
+}
+
+// SsoService is the typed api of the sso providers. They are not part of the
+// admin api, so the generated client does not have them.
+type SsoService struct {
+	client *WarpgateClient
+}
+
+func (c *WarpgateClient) Sso() *SsoService {
+	return &SsoService{client: c}
+}
+
+// Providers returns the sso providers configured on the server.
+func (s *SsoService) Providers(ctx context.Context) ([]SsoProvider, error) {
+	providers := []SsoProvider{}
+
+	err := s.client.doJSON(ctx, "list sso providers", http.MethodGet, WARPGATE_ENDPOINT_SSO_PROVIDERS, nil, &providers, 200)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return providers, nil
+}
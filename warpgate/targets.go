@@ -0,0 +1,134 @@
+package warpgate
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of target, as returned by TargetOptions.Discriminator.
+const (
+	TargetKindSsh      = "Ssh"
+	TargetKindHttp     = "Http"
+	TargetKindMySql    = "MySql"
+	TargetKindPostgres = "Postgres"
+	TargetKindWebAdmin = "WebAdmin"
+)
+
+// TargetsService is the typed api of the targets and of their roles.
+type TargetsService struct {
+	client *WarpgateClient
+}
+
+func (c *WarpgateClient) Targets() *TargetsService {
+	return &TargetsService{client: c}
+}
+
+// List returns the targets of the given kind, or all of them if kind is
+// empty.
+func (s *TargetsService) List(ctx context.Context, kind string) ([]Target, error) {
+	response, err := s.client.GetTargetsWithResponse(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := checkJSON("list targets", response, response.Body, 200, response.JSON200)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == "" {
+		return *all, nil
+	}
+
+	targets := []Target{}
+
+	for _, target := range *all {
+		if targetKind, err := target.Options.Discriminator(); err == nil && targetKind == kind {
+			targets = append(targets, target)
+		}
+	}
+
+	return targets, nil
+}
+
+func (s *TargetsService) Get(ctx context.Context, id uuid.UUID) (*Target, error) {
+	response, err := s.client.GetTargetWithResponse(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checkJSON("read target", response, response.Body, 200, response.JSON200)
+}
+
+func (s *TargetsService) Create(ctx context.Context, data TargetDataRequest) (*Target, error) {
+	response, err := s.client.CreateTargetWithResponse(ctx, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checkJSON("create target", response, response.Body, 201, response.JSON201)
+}
+
+func (s *TargetsService) Update(ctx context.Context, id uuid.UUID, data TargetDataRequest) (*Target, error) {
+	response, err := s.client.UpdateTargetWithResponse(ctx, id, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checkJSON("update target", response, response.Body, 200, response.JSON200)
+}
+
+func (s *TargetsService) Delete(ctx context.Context, id uuid.UUID) error {
+	response, err := s.client.DeleteTargetWithResponse(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return checkStatus("delete target", response, response.Body, 204)
+}
+
+// Roles returns the roles allowed to access the target.
+func (s *TargetsService) Roles(ctx context.Context, id uuid.UUID) ([]Role, error) {
+	response, err := s.client.GetTargetRolesWithResponse(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := checkJSON("read roles of target", response, response.Body, 200, response.JSON200)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return *roles, nil
+}
+
+// AssignRole allows the role to access the target, ErrConflict is returned
+// if it is already allowed.
+func (s *TargetsService) AssignRole(ctx context.Context, id uuid.UUID, roleId uuid.UUID) error {
+	response, err := s.client.AddTargetRoleWithResponse(ctx, id, roleId)
+
+	if err != nil {
+		return err
+	}
+
+	return checkStatus("add role to target", response, response.Body, 201)
+}
+
+func (s *TargetsService) UnassignRole(ctx context.Context, id uuid.UUID, roleId uuid.UUID) error {
+	response, err := s.client.DeleteTargetRoleWithResponse(ctx, id, roleId)
+
+	if err != nil {
+		return err
+	}
+
+	return checkStatus("remove role from target", response, response.Body, 204)
+}
@@ -0,0 +1,53 @@
+package warpgate
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// TicketsService is the typed api of the tickets.
+type TicketsService struct {
+	client *WarpgateClient
+}
+
+func (c *WarpgateClient) Tickets() *TicketsService {
+	return &TicketsService{client: c}
+}
+
+func (s *TicketsService) List(ctx context.Context) ([]Ticket, error) {
+	response, err := s.client.GetTicketsWithResponse(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tickets, err := checkJSON("list tickets", response, response.Body, 200, response.JSON200)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return *tickets, nil
+}
+
+// Create returns the ticket with its secret, which cannot be read again.
+func (s *TicketsService) Create(ctx context.Context, data CreateTicketRequest) (*TicketAndSecret, error) {
+	response, err := s.client.CreateTicketWithResponse(ctx, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checkJSON("create ticket", response, response.Body, 201, response.JSON201)
+}
+
+func (s *TicketsService) Delete(ctx context.Context, id uuid.UUID) error {
+	response, err := s.client.DeleteTicketWithResponse(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return checkStatus("delete ticket", response, response.Body, 204)
+}
@@ -0,0 +1,111 @@
+package warpgate
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// UsersService is the typed api of the users and of their roles.
+type UsersService struct {
+	client *WarpgateClient
+}
+
+func (c *WarpgateClient) Users() *UsersService {
+	return &UsersService{client: c}
+}
+
+func (s *UsersService) List(ctx context.Context) ([]User, error) {
+	response, err := s.client.GetUsersWithResponse(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := checkJSON("list users", response, response.Body, 200, response.JSON200)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return *users, nil
+}
+
+func (s *UsersService) Get(ctx context.Context, id uuid.UUID) (*User, error) {
+	response, err := s.client.GetUserWithResponse(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checkJSON("read user", response, response.Body, 200, response.JSON200)
+}
+
+func (s *UsersService) Create(ctx context.Context, data UserDataRequest) (*User, error) {
+	response, err := s.client.CreateUserWithResponse(ctx, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checkJSON("create user", response, response.Body, 201, response.JSON201)
+}
+
+func (s *UsersService) Update(ctx context.Context, id uuid.UUID, data UserDataRequest) (*User, error) {
+	response, err := s.client.UpdateUserWithResponse(ctx, id, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return checkJSON("update user", response, response.Body, 200, response.JSON200)
+}
+
+func (s *UsersService) Delete(ctx context.Context, id uuid.UUID) error {
+	response, err := s.client.DeleteUserWithResponse(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return checkStatus("delete user", response, response.Body, 204)
+}
+
+// Roles returns the roles assigned to the user.
+func (s *UsersService) Roles(ctx context.Context, id uuid.UUID) ([]Role, error) {
+	response, err := s.client.GetUserRolesWithResponse(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := checkJSON("read roles of user", response, response.Body, 200, response.JSON200)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return *roles, nil
+}
+
+// AssignRole assigns the role to the user, ErrConflict is returned if it is
+// already assigned.
+func (s *UsersService) AssignRole(ctx context.Context, id uuid.UUID, roleId uuid.UUID) error {
+	response, err := s.client.AddUserRoleWithResponse(ctx, id, roleId)
+
+	if err != nil {
+		return err
+	}
+
+	return checkStatus("add role to user", response, response.Body, 201)
+}
+
+func (s *UsersService) UnassignRole(ctx context.Context, id uuid.UUID, roleId uuid.UUID) error {
+	response, err := s.client.DeleteUserRoleWithResponse(ctx, id, roleId)
+
+	if err != nil {
+		return err
+	}
+
+	return checkStatus("remove role from user", response, response.Body, 204)
+}
@@ -0,0 +1,309 @@
+package warpgatetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"terraform-provider-warpgate/warpgate"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTypedClientErrors(t *testing.T) {
+	_, client := testClient(t)
+	ctx := context.Background()
+
+	role, err := client.Roles().Create(ctx, warpgate.RoleDataRequest{Name: "developers"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Roles().Create(ctx, warpgate.RoleDataRequest{Name: "developers"})
+
+	if !errors.Is(err, warpgate.ErrConflict) {
+		t.Errorf("expected a conflict for a duplicate role, got %v", err)
+	}
+
+	_, err = client.Roles().Create(ctx, warpgate.RoleDataRequest{Name: ""})
+
+	if !errors.Is(err, warpgate.ErrBadRequest) {
+		t.Errorf("expected a bad request for an empty role name, got %v", err)
+	}
+
+	var statusError *warpgate.StatusError
+
+	if !errors.As(err, &statusError) || statusError.StatusCode != 400 || statusError.Operation != "create role" {
+		t.Errorf("expected a StatusError of create role with status 400, got %#v", err)
+	}
+
+	if _, err := client.Users().Get(ctx, uuid.New()); !errors.Is(err, warpgate.ErrNotFound) {
+		t.Errorf("expected a missing user to be not found, got %v", err)
+	}
+
+	targets, err := client.Targets().List(ctx, warpgate.TargetKindWebAdmin)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(targets) != 1 || targets[0].Name != AdminTargetName {
+		t.Errorf("expected only the admin target to be listed, got %v", targets)
+	}
+
+	targetId := targets[0].Id
+
+	if err := client.Targets().AssignRole(ctx, targetId, role.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Targets().AssignRole(ctx, targetId, role.Id); !errors.Is(err, warpgate.ErrConflict) {
+		t.Errorf("expected a conflict for a role assigned twice, got %v", err)
+	}
+
+	if err := client.Targets().UnassignRole(ctx, targetId, role.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Targets().UnassignRole(ctx, targetId, role.Id); !errors.Is(err, warpgate.ErrNotFound) {
+		t.Errorf("expected a role not assigned to be not found, got %v", err)
+	}
+
+	if err := client.Roles().Delete(ctx, role.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Roles().Get(ctx, role.Id); !errors.Is(err, warpgate.ErrNotFound) {
+		t.Errorf("expected a deleted role to be not found, got %v", err)
+	}
+
+	if err := client.Tickets().Delete(ctx, uuid.New()); !errors.Is(err, warpgate.ErrNotFound) {
+		t.Errorf("expected a missing ticket to be not found, got %v", err)
+	}
+}
+
+func TestUserCredentials(t *testing.T) {
+	ctx := context.Background()
+	publicKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAUHVLLcZmjfhjMTZk8J5q/RZn8+htJakhvTikkAt+5f alice@example.com"
+
+	for _, endpoints := range []bool{true, false} {
+		server, client := testClient(t)
+
+		if !endpoints {
+			server.DisableCredentialEndpoints()
+		}
+
+		user, err := client.Users().Create(ctx, warpgate.UserDataRequest{Username: "alice"})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		credentials := client.Users().Credentials(user.Id)
+
+		if _, err := client.Users().Credentials(uuid.New()).Supported(ctx); !errors.Is(err, warpgate.ErrNotFound) {
+			t.Errorf("expected a missing user to be not found, got %v", err)
+		}
+
+		supported, err := credentials.Supported(ctx)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if supported != endpoints {
+			t.Errorf("expected the endpoints to be supported: %t, got %t", endpoints, supported)
+		}
+
+		if !supported {
+			updated, err := credentials.Update(ctx, func(user *warpgate.UserDataRequest) error {
+				credential := warpgate.UserAuthCredential{}
+				err := credential.FromUserAuthCredentialUserPublicKeyCredential(warpgate.UserAuthCredentialUserPublicKeyCredential{Kind: "PublicKey", Key: publicKey})
+				user.Credentials = append(user.Credentials, credential)
+				return err
+			})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(updated.Credentials) != 1 {
+				t.Errorf("expected the public key to be added, got %v", updated.Credentials)
+			}
+
+			continue
+		}
+
+		created, err := credentials.Add(ctx, warpgate.PublicKey, warpgate.NewUserCredential{OpensshPublicKey: publicKey})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		listed, err := credentials.List(ctx, warpgate.PublicKey)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(listed) != 1 || listed[0].Id != created.Id || listed[0].OpensshPublicKey != publicKey {
+			t.Errorf("expected the public key %v to be listed, got %v", created, listed)
+		}
+
+		if _, err := credentials.Add(ctx, warpgate.Password, warpgate.NewUserCredential{}); !errors.Is(err, warpgate.ErrBadRequest) {
+			t.Errorf("expected an empty password to be rejected, got %v", err)
+		}
+
+		if err := credentials.Delete(ctx, warpgate.PublicKey, created.Id); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := credentials.Delete(ctx, warpgate.PublicKey, created.Id); !errors.Is(err, warpgate.ErrNotFound) {
+			t.Errorf("expected a deleted credential to be not found, got %v", err)
+		}
+	}
+}
+
+// The read-modify-write of the credentials of the same user are serialized,
+// so concurrent updates do not overwrite each other.
+func TestUserCredentialsUpdateSerialized(t *testing.T) {
+	ctx := context.Background()
+	server, client := testClient(t)
+	server.DisableCredentialEndpoints()
+
+	user, err := client.Users().Create(ctx, warpgate.UserDataRequest{Username: "alice"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func(email string) {
+			defer wg.Done()
+
+			_, err := client.Users().Credentials(user.Id).Update(ctx, func(user *warpgate.UserDataRequest) error {
+				credential := warpgate.UserAuthCredential{}
+				err := credential.FromUserAuthCredentialUserSsoCredential(warpgate.UserAuthCredentialUserSsoCredential{Kind: "Sso", Email: email})
+				user.Credentials = append(user.Credentials, credential)
+				return err
+			})
+
+			errs <- err
+		}(fmt.Sprintf("user-%d@example.com", i))
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	updated, err := client.Users().Credentials(user.Id).Update(ctx, func(user *warpgate.UserDataRequest) error {
+		user.Username = "bob"
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated.Username != "bob" || len(updated.Credentials) != 5 {
+		t.Errorf("expected the user renamed keeping the 5 credentials, got %s and %v", updated.Username, updated.Credentials)
+	}
+}
+
+// A change of the credentials by another client while they are modified is
+// a conflict instead of being overwritten.
+func TestUserCredentialsUpdateConflict(t *testing.T) {
+	ctx := context.Background()
+	server, client := testClient(t)
+	server.DisableCredentialEndpoints()
+
+	// another terraform run changing the same user
+	other := warpgate.NewWarpgateClient(server.Host(), server.Port(), true, warpgate.RequestLimits{})
+
+	if err := other.Login(AdminUsername, AdminPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := client.Users().Create(ctx, warpgate.UserDataRequest{Username: "alice"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addSso := func(email string) func(user *warpgate.UserDataRequest) error {
+		return func(user *warpgate.UserDataRequest) error {
+			credential := warpgate.UserAuthCredential{}
+			err := credential.FromUserAuthCredentialUserSsoCredential(warpgate.UserAuthCredentialUserSsoCredential{Kind: "Sso", Email: email})
+			user.Credentials = append(user.Credentials, credential)
+			return err
+		}
+	}
+
+	_, err = client.Users().Credentials(user.Id).Update(ctx, func(data *warpgate.UserDataRequest) error {
+		if _, err := other.Users().Credentials(user.Id).Update(ctx, addSso("bob@example.com")); err != nil {
+			return err
+		}
+
+		return addSso("alice@example.com")(data)
+	})
+
+	if !errors.Is(err, warpgate.ErrConflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+
+	current, err := other.Users().Get(ctx, user.Id)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(current.Credentials) != 1 {
+		t.Errorf("expected only the credential of the other client, got %v", current.Credentials)
+	}
+
+	// the credential policy is compared as well
+	_, err = client.Users().Credentials(user.Id).Update(ctx, func(data *warpgate.UserDataRequest) error {
+		_, err := other.Users().Credentials(user.Id).Update(ctx, func(data *warpgate.UserDataRequest) error {
+			data.CredentialPolicy = &warpgate.UserRequireCredentialsPolicy{Ssh: &[]warpgate.CredentialKind{warpgate.PublicKey}}
+			return nil
+		})
+
+		return err
+	})
+
+	if !errors.Is(err, warpgate.ErrConflict) {
+		t.Errorf("expected a conflict for the credential policy, got %v", err)
+	}
+}
+
+func TestSsoProviders(t *testing.T) {
+	server, client := testClient(t)
+	ctx := context.Background()
+
+	server.SetSsoProviders(
+		warpgate.SsoProvider{Name: SsoProviderName, Label: "Google", Kind: "Google"},
+		warpgate.SsoProvider{Name: "corp", Label: "Corporate", Kind: "Custom"},
+	)
+
+	providers, err := client.Sso().Providers(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(providers) != 2 || providers[0].Name != SsoProviderName || providers[1].Kind != "Custom" {
+		t.Errorf("unexpected sso providers %v", providers)
+	}
+}
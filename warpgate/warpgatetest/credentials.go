@@ -0,0 +1,162 @@
+package warpgatetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+)
+
+// credentialKinds maps the paths of the per-credential endpoints to the kind
+// of the credentials.
+var credentialKinds = map[string]string{
+	"passwords":   "Password",
+	"public-keys": "PublicKey",
+	"sso":         "Sso",
+	"otp":         "Totp",
+}
+
+// DisableCredentialEndpoints makes the per-credential endpoints answer 404,
+// as the older warpgate servers, where the credentials are only changed with
+// the update of the user.
+func (s *Server) DisableCredentialEndpoints() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.noCredentialEndpoints = true
+}
+
+// credentialId is derived from the user and the credential, so that it does
+// not change when the credentials are replaced by the update of the user.
+func credentialId(user *storedUser, credential warpgate.UserAuthCredential) uuid.UUID {
+	data, _ := json.Marshal(credential)
+	return uuid.NewSHA1(user.Id, data)
+}
+
+func (s *Server) getUserCredentials(w http.ResponseWriter, r *http.Request) {
+	user, kind, ok := s.pathCredentials(w, r)
+
+	if !ok {
+		return
+	}
+
+	credentials := []warpgate.UserCredential{}
+
+	for _, credential := range user.Credentials {
+		if discriminator, _ := credential.Discriminator(); discriminator == kind {
+			credentials = append(credentials, existingCredential(user, credential))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, credentials)
+}
+
+func (s *Server) addUserCredential(w http.ResponseWriter, r *http.Request) {
+	user, kind, ok := s.pathCredentials(w, r)
+
+	if !ok {
+		return
+	}
+
+	var data warpgate.NewUserCredential
+
+	if !readJSON(w, r, &data) {
+		return
+	}
+
+	if kind == "Password" && data.Password == "" {
+		writeError(w, http.StatusBadRequest, "invalid credentials: the password cannot be empty")
+		return
+	}
+
+	credential := warpgate.UserAuthCredential{}
+
+	switch kind {
+	case "Password":
+		// not a valid hash, the password is never checked
+		_ = credential.FromUserAuthCredentialUserPasswordCredential(warpgate.UserAuthCredentialUserPasswordCredential{
+			Kind: kind,
+			Hash: "$argon2id$v=19$m=4096,t=3,p=1$warpgatetest$" + data.Password,
+		})
+	case "PublicKey":
+		_ = credential.FromUserAuthCredentialUserPublicKeyCredential(warpgate.UserAuthCredentialUserPublicKeyCredential{
+			Kind: kind,
+			Key:  data.OpensshPublicKey,
+		})
+	case "Sso":
+		_ = credential.FromUserAuthCredentialUserSsoCredential(warpgate.UserAuthCredentialUserSsoCredential{
+			Kind:     kind,
+			Email:    data.Email,
+			Provider: data.Provider,
+		})
+	case "Totp":
+		_ = credential.FromUserAuthCredentialUserTotpCredential(warpgate.UserAuthCredentialUserTotpCredential{
+			Kind: kind,
+			Key:  data.SecretKey,
+		})
+	}
+
+	if err := validateCredentials([]warpgate.UserAuthCredential{credential}); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid credentials: "+err.Error())
+		return
+	}
+
+	user.Credentials = append(user.Credentials, credential)
+
+	writeJSON(w, http.StatusCreated, existingCredential(user, credential))
+}
+
+func (s *Server) deleteUserCredential(w http.ResponseWriter, r *http.Request) {
+	user, kind, ok := s.pathCredentials(w, r)
+
+	if !ok {
+		return
+	}
+
+	id, ok := pathId(w, r, "credential_id")
+
+	if !ok {
+		return
+	}
+
+	for i, credential := range user.Credentials {
+		if discriminator, _ := credential.Discriminator(); discriminator == kind && credentialId(user, credential) == id {
+			user.Credentials = append(user.Credentials[:i:i], user.Credentials[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, "credential not found")
+}
+
+func (s *Server) pathCredentials(w http.ResponseWriter, r *http.Request) (*storedUser, string, bool) {
+	kind, ok := credentialKinds[r.PathValue("kind")]
+
+	if !ok || s.noCredentialEndpoints {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, "", false
+	}
+
+	user, ok := s.pathUser(w, r)
+
+	return user, kind, ok
+}
+
+// existingCredential returns the credential without the secrets.
+func existingCredential(user *storedUser, credential warpgate.UserAuthCredential) warpgate.UserCredential {
+	existing := warpgate.UserCredential{Id: credentialId(user, credential)}
+
+	switch kind, _ := credential.Discriminator(); kind {
+	case "PublicKey":
+		publicKey, _ := credential.AsUserAuthCredentialUserPublicKeyCredential()
+		existing.OpensshPublicKey = publicKey.Key
+	case "Sso":
+		sso, _ := credential.AsUserAuthCredentialUserSsoCredential()
+		existing.Email = sso.Email
+		existing.Provider = sso.Provider
+	}
+
+	return existing
+}
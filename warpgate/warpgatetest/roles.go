@@ -0,0 +1,166 @@
+package warpgatetest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+)
+
+func (s *Server) roleNameTaken(name string, except uuid.UUID) bool {
+	for _, role := range s.roles {
+		if role.Name == name && role.Id != except {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) validateRole(w http.ResponseWriter, data warpgate.RoleDataRequest, id uuid.UUID) bool {
+	if strings.TrimSpace(data.Name) == "" {
+		writeError(w, http.StatusBadRequest, "name cannot be empty")
+		return false
+	}
+
+	if s.roleNameTaken(data.Name, id) {
+		writeError(w, http.StatusConflict, "a role with this name already exists")
+		return false
+	}
+
+	return true
+}
+
+func (s *Server) getRoles(w http.ResponseWriter, r *http.Request) {
+	roles := []warpgate.Role{}
+
+	for _, role := range s.roles {
+		roles = append(roles, *role)
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	writeJSON(w, http.StatusOK, roles)
+}
+
+func (s *Server) createRole(w http.ResponseWriter, r *http.Request) {
+	var data warpgate.RoleDataRequest
+
+	if !readJSON(w, r, &data) || !s.validateRole(w, data, uuid.Nil) {
+		return
+	}
+
+	role := &warpgate.Role{Id: uuid.New(), Name: data.Name}
+	s.roles[role.Id] = role
+
+	writeJSON(w, http.StatusCreated, role)
+}
+
+func (s *Server) getRole(w http.ResponseWriter, r *http.Request) {
+	role, ok := s.pathRole(w, r, "id")
+
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, role)
+}
+
+func (s *Server) updateRole(w http.ResponseWriter, r *http.Request) {
+	role, ok := s.pathRole(w, r, "id")
+
+	if !ok {
+		return
+	}
+
+	var data warpgate.RoleDataRequest
+
+	if !readJSON(w, r, &data) || !s.validateRole(w, data, role.Id) {
+		return
+	}
+
+	role.Name = data.Name
+
+	writeJSON(w, http.StatusOK, role)
+}
+
+func (s *Server) deleteRole(w http.ResponseWriter, r *http.Request) {
+	role, ok := s.pathRole(w, r, "id")
+
+	if !ok {
+		return
+	}
+
+	delete(s.roles, role.Id)
+
+	// the assignments are removed by the database cascade
+	for _, assignments := range s.targetRoles {
+		delete(assignments, role.Id)
+	}
+
+	for _, assignments := range s.userRoles {
+		delete(assignments, role.Id)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) pathRole(w http.ResponseWriter, r *http.Request, name string) (*warpgate.Role, bool) {
+	id, ok := pathId(w, r, name)
+
+	if !ok {
+		return nil, false
+	}
+
+	role, ok := s.roles[id]
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "role not found")
+		return nil, false
+	}
+
+	return role, true
+}
+
+// getAssignedRoles, addRole and deleteRole implement the role assignments of
+// both users and targets.
+
+func (s *Server) getAssignedRoles(w http.ResponseWriter, assignments map[uuid.UUID]bool) {
+	writeJSON(w, http.StatusOK, sortedRoles(s.roles, assignments))
+}
+
+func (s *Server) addRole(w http.ResponseWriter, r *http.Request, assignments map[uuid.UUID]bool) {
+	role, ok := s.pathRole(w, r, "role_id")
+
+	if !ok {
+		return
+	}
+
+	if assignments[role.Id] {
+		writeError(w, http.StatusConflict, "the role is already assigned")
+		return
+	}
+
+	assignments[role.Id] = true
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) removeRole(w http.ResponseWriter, r *http.Request, assignments map[uuid.UUID]bool) {
+	role, ok := s.pathRole(w, r, "role_id")
+
+	if !ok {
+		return
+	}
+
+	if !assignments[role.Id] {
+		writeError(w, http.StatusNotFound, "the role is not assigned")
+		return
+	}
+
+	delete(assignments, role.Id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,305 @@
+// Package warpgatetest provides an in-memory implementation of the warpgate
+// admin api, to run the tests of the provider without a warpgate server.
+package warpgatetest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// AdminUsername and AdminPassword are the credentials to login into the
+	// server, as set by `warpgate unattended-setup`.
+	AdminUsername = "admin"
+	AdminPassword = "password"
+
+	// AdminRoleName is the name of the built-in role, assigned to the admin
+	// user and to the web admin target.
+	AdminRoleName = "warpgate:admin"
+	// AdminTargetName is the name of the built-in web admin target.
+	AdminTargetName = "warpgate:admin"
+
+	sessionCookie = "warpgate-http-session"
+)
+
+// Server is a warpgate admin api served by httptest, all the state is kept in
+// memory and lost on Close.
+type Server struct {
+	*httptest.Server
+
+	mutex sync.Mutex
+
+	sessions map[string]bool // http sessions of the logged in clients
+
+	roles       map[uuid.UUID]*warpgate.Role
+	targets     map[uuid.UUID]*storedTarget
+	users       map[uuid.UUID]*storedUser
+	targetRoles map[uuid.UUID]map[uuid.UUID]bool
+	userRoles   map[uuid.UUID]map[uuid.UUID]bool
+
+	tickets        map[uuid.UUID]*warpgate.Ticket
+	ticketSecrets  map[uuid.UUID]string
+	targetSessions map[uuid.UUID]*warpgate.SessionSnapshot
+	knownHosts     map[uuid.UUID]*warpgate.SSHKnownHost
+	ownKeys        []warpgate.SSHKey
+	ssoProviders   []warpgate.SsoProvider
+
+	// noCredentialEndpoints hides the per-credential endpoints, as on the
+	// older warpgate servers.
+	noCredentialEndpoints bool
+}
+
+type storedTarget struct {
+	Id      uuid.UUID
+	Name    string
+	Options warpgate.TargetOptions
+}
+
+type storedUser struct {
+	Id               uuid.UUID
+	Username         string
+	Credentials      []warpgate.UserAuthCredential
+	CredentialPolicy *warpgate.UserRequireCredentialsPolicy
+}
+
+// NewServer starts a tls server with the built-in objects of a new warpgate
+// installation: the admin role, user and web admin target. The config file
+// has the SsoProviderName sso provider.
+func NewServer() *Server {
+	s := &Server{
+		sessions:       map[string]bool{},
+		roles:          map[uuid.UUID]*warpgate.Role{},
+		targets:        map[uuid.UUID]*storedTarget{},
+		users:          map[uuid.UUID]*storedUser{},
+		targetRoles:    map[uuid.UUID]map[uuid.UUID]bool{},
+		userRoles:      map[uuid.UUID]map[uuid.UUID]bool{},
+		tickets:        map[uuid.UUID]*warpgate.Ticket{},
+		ticketSecrets:  map[uuid.UUID]string{},
+		targetSessions: map[uuid.UUID]*warpgate.SessionSnapshot{},
+		knownHosts:     map[uuid.UUID]*warpgate.SSHKnownHost{},
+		ownKeys:        generateOwnKeys(),
+		ssoProviders:   []warpgate.SsoProvider{{Name: SsoProviderName, Label: "Google", Kind: "Google"}},
+	}
+
+	s.seed()
+
+	s.Server = httptest.NewTLSServer(s.routes())
+
+	return s
+}
+
+func (s *Server) seed() {
+	role := &warpgate.Role{Id: uuid.New(), Name: AdminRoleName}
+	s.roles[role.Id] = role
+
+	options := warpgate.TargetOptions{}
+	_ = options.FromTargetOptionsTargetWebAdminOptions(warpgate.TargetOptionsTargetWebAdminOptions{Kind: warpgate.TargetKindWebAdmin})
+
+	target := &storedTarget{Id: uuid.New(), Name: AdminTargetName, Options: options}
+	s.targets[target.Id] = target
+	s.targetRoles[target.Id] = map[uuid.UUID]bool{role.Id: true}
+
+	password := warpgate.UserAuthCredential{}
+	_ = password.FromUserAuthCredentialUserPasswordCredential(warpgate.UserAuthCredentialUserPasswordCredential{
+		Kind: "Password",
+		// not a valid hash, the login only checks AdminPassword
+		Hash: "$argon2id$v=19$m=4096,t=3,p=1$warpgatetest$admin",
+	})
+
+	user := &storedUser{Id: uuid.New(), Username: AdminUsername, Credentials: []warpgate.UserAuthCredential{password}}
+	s.users[user.Id] = user
+	s.userRoles[user.Id] = map[uuid.UUID]bool{role.Id: true}
+}
+
+// Host returns the hostname the server is listening on.
+func (s *Server) Host() string {
+	serverUrl, _ := url.Parse(s.URL)
+	return serverUrl.Hostname()
+}
+
+// Port returns the port the server is listening on.
+func (s *Server) Port() int {
+	serverUrl, _ := url.Parse(s.URL)
+	port, _ := strconv.Atoi(serverUrl.Port())
+	return port
+}
+
+// Env returns the WARPGATE_* environment variables used by the provider to
+// connect to the server.
+func (s *Server) Env() map[string]string {
+	return map[string]string{
+		"WARPGATE_HOST":                 s.Host(),
+		"WARPGATE_PORT":                 strconv.Itoa(s.Port()),
+		"WARPGATE_USERNAME":             AdminUsername,
+		"WARPGATE_PASSWORD":             AdminPassword,
+		"WARPGATE_INSECURE_SKIP_VERIFY": "true",
+	}
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST "+warpgate.WARPGATE_ENDPOINT_LOGIN, s.login)
+	mux.HandleFunc("GET "+warpgate.WARPGATE_ENDPOINT_SSO_PROVIDERS, s.getSsoProviders)
+
+	admin := func(pattern string, handler http.HandlerFunc) {
+		method, path, _ := strings.Cut(pattern, " ")
+		mux.Handle(method+" "+warpgate.WARPGATE_ENDPOINT_ADMIN_API+path, s.authenticated(handler))
+	}
+
+	admin("GET /roles", s.getRoles)
+	admin("POST /roles", s.createRole)
+	admin("GET /role/{id}", s.getRole)
+	admin("PUT /role/{id}", s.updateRole)
+	admin("DELETE /role/{id}", s.deleteRole)
+
+	admin("GET /targets", s.getTargets)
+	admin("POST /targets", s.createTarget)
+	admin("GET /targets/{id}", s.getTarget)
+	admin("PUT /targets/{id}", s.updateTarget)
+	admin("DELETE /targets/{id}", s.deleteTarget)
+	admin("GET /targets/{id}/roles", s.getTargetRoles)
+	admin("POST /targets/{id}/roles/{role_id}", s.addTargetRole)
+	admin("DELETE /targets/{id}/roles/{role_id}", s.deleteTargetRole)
+
+	admin("GET /users", s.getUsers)
+	admin("POST /users", s.createUser)
+	admin("GET /users/{id}", s.getUser)
+	admin("PUT /users/{id}", s.updateUser)
+	admin("DELETE /users/{id}", s.deleteUser)
+	admin("GET /users/{id}/roles", s.getUserRoles)
+	admin("POST /users/{id}/roles/{role_id}", s.addUserRole)
+	admin("DELETE /users/{id}/roles/{role_id}", s.deleteUserRole)
+	admin("GET /users/{id}/credentials/{kind}", s.getUserCredentials)
+	admin("POST /users/{id}/credentials/{kind}", s.addUserCredential)
+	admin("DELETE /users/{id}/credentials/{kind}/{credential_id}", s.deleteUserCredential)
+
+	admin("GET /tickets", s.getTickets)
+	admin("POST /tickets", s.createTicket)
+	admin("DELETE /tickets/{id}", s.deleteTicket)
+
+	admin("GET /sessions", s.getSessions)
+	admin("DELETE /sessions", s.closeAllSessions)
+	admin("GET /sessions/{id}", s.getSession)
+	admin("POST /sessions/{id}/close", s.closeSession)
+	admin("GET /sessions/{id}/recordings", s.getSessionRecordings)
+
+	admin("GET /ssh/known-hosts", s.getKnownHosts)
+	admin("DELETE /ssh/known-hosts/{id}", s.deleteKnownHost)
+	admin("GET /ssh/own-keys", s.getOwnKeys)
+
+	return mux
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if data.Username != AdminUsername || data.Password != AdminPassword {
+		writeError(w, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+
+	secret := make([]byte, 16)
+	_, _ = rand.Read(secret)
+	session := hex.EncodeToString(secret)
+
+	s.mutex.Lock()
+	s.sessions[session] = true
+	s.mutex.Unlock()
+
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: session, Path: "/", HttpOnly: true, Secure: true})
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) authenticated(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if cookie, err := r.Cookie(sessionCookie); err != nil || !s.sessions[cookie.Value] {
+			writeError(w, http.StatusUnauthorized, "not logged in")
+			return
+		}
+
+		// the handlers run with the mutex held
+		handler(w, r)
+	})
+}
+
+// pathId parses the uuid in the path, writing a 404 if it is not valid.
+func pathId(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue(name))
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+// readJSON decodes the request body, writing a 400 if it is not valid.
+func readJSON(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(value); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %s", err))
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes the message as a json string, as warpgate does for the
+// 400 responses.
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, message)
+}
+
+func sortedRoles(roles map[uuid.UUID]*warpgate.Role, ids map[uuid.UUID]bool) []warpgate.Role {
+	result := []warpgate.Role{}
+
+	for id := range ids {
+		if role, ok := roles[id]; ok {
+			result = append(result, *role)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result
+}
+
+func roleNames(roles []warpgate.Role) []string {
+	names := []string{}
+
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+
+	return names
+}
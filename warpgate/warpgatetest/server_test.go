@@ -0,0 +1,321 @@
+package warpgatetest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"terraform-provider-warpgate/warpgate"
+	"testing"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
+)
+
+func testClient(t *testing.T) (*Server, *warpgate.WarpgateClient) {
+	t.Helper()
+
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	client := warpgate.NewWarpgateClient(server.Host(), server.Port(), true, warpgate.RequestLimits{})
+
+	if err := client.Login(AdminUsername, AdminPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	return server, client
+}
+
+func expectStatus(t *testing.T, name string, expected int, actual int, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+
+	if actual != expected {
+		t.Errorf("%s: expected status %d, got %d", name, expected, actual)
+	}
+}
+
+func sshTargetOptions(t *testing.T, host string) warpgate.TargetOptions {
+	auth := warpgate.SSHTargetAuth{}
+	options := warpgate.TargetOptions{}
+
+	if err := auth.FromSSHTargetAuthSshTargetPasswordAuth(warpgate.SSHTargetAuthSshTargetPasswordAuth{Kind: "Password", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := options.FromTargetOptionsTargetSSHOptions(warpgate.TargetOptionsTargetSSHOptions{
+		Kind: "Ssh", Host: host, Port: 22, Username: "root", Auth: auth,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return options
+}
+
+func TestLogin(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := warpgate.NewWarpgateClient(server.Host(), server.Port(), true, warpgate.RequestLimits{})
+
+	if err := client.Login(AdminUsername, "wrong"); err == nil {
+		t.Error("expected the login to fail with a wrong password")
+	}
+
+	response, err := http.Get(server.URL + warpgate.WARPGATE_ENDPOINT_ADMIN_API + "/roles")
+
+	if err == nil {
+		response.Body.Close()
+		t.Error("expected the tls verification to fail")
+	}
+
+	response, err = server.Client().Get(server.URL + warpgate.WARPGATE_ENDPOINT_ADMIN_API + "/roles")
+	expectStatus(t, "roles without login", 401, response.StatusCode, err)
+	response.Body.Close()
+}
+
+func TestBuiltInObjects(t *testing.T) {
+	_, client := testClient(t)
+	ctx := context.Background()
+
+	roles, err := client.GetRolesWithResponse(ctx)
+	expectStatus(t, "roles", 200, roles.StatusCode(), err)
+
+	if len(*roles.JSON200) != 1 || (*roles.JSON200)[0].Name != AdminRoleName {
+		t.Errorf("expected only the admin role, got %v", *roles.JSON200)
+	}
+
+	targets, err := client.GetTargetsWithResponse(ctx)
+	expectStatus(t, "targets", 200, targets.StatusCode(), err)
+
+	if len(*targets.JSON200) != 1 || (*targets.JSON200)[0].AllowRoles[0] != AdminRoleName {
+		t.Fatalf("expected only the web admin target, got %v", *targets.JSON200)
+	}
+
+	webAdmin := (*targets.JSON200)[0]
+
+	if kind, _ := webAdmin.Options.Discriminator(); kind != "WebAdmin" {
+		t.Errorf("expected a web admin target, got %s", kind)
+	}
+
+	response, err := client.DeleteTargetWithResponse(ctx, webAdmin.Id)
+	expectStatus(t, "delete web admin", 403, response.StatusCode(), err)
+
+	users, err := client.GetUsersWithResponse(ctx)
+	expectStatus(t, "users", 200, users.StatusCode(), err)
+
+	if len(*users.JSON200) != 1 || (*users.JSON200)[0].Username != AdminUsername {
+		t.Errorf("expected only the admin user, got %v", *users.JSON200)
+	}
+}
+
+func TestRoles(t *testing.T) {
+	_, client := testClient(t)
+	ctx := context.Background()
+
+	created, err := client.CreateRoleWithResponse(ctx, warpgate.RoleDataRequest{Name: "ops"})
+	expectStatus(t, "create", 201, created.StatusCode(), err)
+
+	duplicate, err := client.CreateRoleWithResponse(ctx, warpgate.RoleDataRequest{Name: "ops"})
+	expectStatus(t, "create duplicate", 409, duplicate.StatusCode(), err)
+
+	empty, err := client.CreateRoleWithResponse(ctx, warpgate.RoleDataRequest{Name: ""})
+	expectStatus(t, "create empty", 400, empty.StatusCode(), err)
+
+	if empty.JSON400 == nil {
+		t.Error("expected the error message as a json string")
+	}
+
+	id := created.JSON201.Id
+
+	updated, err := client.UpdateRoleWithResponse(ctx, id, warpgate.RoleDataRequest{Name: "dev"})
+	expectStatus(t, "update", 200, updated.StatusCode(), err)
+
+	read, err := client.GetRoleWithResponse(ctx, id)
+	expectStatus(t, "read", 200, read.StatusCode(), err)
+
+	if read.JSON200.Name != "dev" {
+		t.Errorf("expected the updated name, got %s", read.JSON200.Name)
+	}
+
+	deleted, err := client.DeleteRoleWithResponse(ctx, id)
+	expectStatus(t, "delete", 204, deleted.StatusCode(), err)
+
+	missing, err := client.GetRoleWithResponse(ctx, id)
+	expectStatus(t, "read deleted", 404, missing.StatusCode(), err)
+
+	deleted, err = client.DeleteRoleWithResponse(ctx, id)
+	expectStatus(t, "delete deleted", 404, deleted.StatusCode(), err)
+}
+
+func TestTargetsAndRoleAssignments(t *testing.T) {
+	_, client := testClient(t)
+	ctx := context.Background()
+
+	role, err := client.CreateRoleWithResponse(ctx, warpgate.RoleDataRequest{Name: "ops"})
+	expectStatus(t, "create role", 201, role.StatusCode(), err)
+
+	created, err := client.CreateTargetWithResponse(ctx, warpgate.TargetDataRequest{Name: "prod", Options: sshTargetOptions(t, "10.0.0.1")})
+	expectStatus(t, "create", 201, created.StatusCode(), err)
+
+	invalid, err := client.CreateTargetWithResponse(ctx, warpgate.TargetDataRequest{Name: "invalid", Options: sshTargetOptions(t, "")})
+	expectStatus(t, "create invalid", 400, invalid.StatusCode(), err)
+
+	webAdmin := warpgate.TargetOptions{}
+	_ = webAdmin.FromTargetOptionsTargetWebAdminOptions(warpgate.TargetOptionsTargetWebAdminOptions{Kind: "WebAdmin"})
+
+	admin, err := client.CreateTargetWithResponse(ctx, warpgate.TargetDataRequest{Name: "admin", Options: webAdmin})
+	expectStatus(t, "create web admin", 400, admin.StatusCode(), err)
+
+	targetId, roleId := created.JSON201.Id, role.JSON201.Id
+
+	added, err := client.AddTargetRoleWithResponse(ctx, targetId, roleId)
+	expectStatus(t, "add role", 201, added.StatusCode(), err)
+
+	added, err = client.AddTargetRoleWithResponse(ctx, targetId, roleId)
+	expectStatus(t, "add role again", 409, added.StatusCode(), err)
+
+	added, err = client.AddTargetRoleWithResponse(ctx, targetId, uuid.New())
+	expectStatus(t, "add unknown role", 404, added.StatusCode(), err)
+
+	target, err := client.GetTargetWithResponse(ctx, targetId)
+	expectStatus(t, "read", 200, target.StatusCode(), err)
+
+	if len(target.JSON200.AllowRoles) != 1 || target.JSON200.AllowRoles[0] != "ops" {
+		t.Errorf("expected allow_roles [ops], got %v", target.JSON200.AllowRoles)
+	}
+
+	// deleting the role removes the assignment
+	deleted, err := client.DeleteRoleWithResponse(ctx, roleId)
+	expectStatus(t, "delete role", 204, deleted.StatusCode(), err)
+
+	roles, err := client.GetTargetRolesWithResponse(ctx, targetId)
+	expectStatus(t, "roles", 200, roles.StatusCode(), err)
+
+	if len(*roles.JSON200) != 0 {
+		t.Errorf("expected no roles, got %v", *roles.JSON200)
+	}
+
+	removed, err := client.DeleteTargetRoleWithResponse(ctx, targetId, roleId)
+	expectStatus(t, "remove deleted role", 404, removed.StatusCode(), err)
+
+	deletedTarget, err := client.DeleteTargetWithResponse(ctx, targetId)
+	expectStatus(t, "delete", 204, deletedTarget.StatusCode(), err)
+
+	roles, err = client.GetTargetRolesWithResponse(ctx, targetId)
+	expectStatus(t, "roles of deleted target", 404, roles.StatusCode(), err)
+}
+
+func TestUsers(t *testing.T) {
+	_, client := testClient(t)
+	ctx := context.Background()
+
+	password := warpgate.UserAuthCredential{}
+	_ = password.FromUserAuthCredentialUserPasswordCredential(warpgate.UserAuthCredentialUserPasswordCredential{Kind: "Password", Hash: "$argon2id$hash"})
+
+	created, err := client.CreateUserWithResponse(ctx, warpgate.UserDataRequest{Username: "alice", Credentials: []warpgate.UserAuthCredential{password}})
+	expectStatus(t, "create", 201, created.StatusCode(), err)
+
+	invalid := warpgate.UserAuthCredential{}
+	_ = invalid.FromUserAuthCredentialUserPasswordCredential(warpgate.UserAuthCredentialUserPasswordCredential{Kind: "Password"})
+
+	rejected, err := client.CreateUserWithResponse(ctx, warpgate.UserDataRequest{Username: "bob", Credentials: []warpgate.UserAuthCredential{invalid}})
+	expectStatus(t, "create with invalid credential", 400, rejected.StatusCode(), err)
+
+	id := created.JSON201.Id
+
+	updated, err := client.UpdateUserWithResponse(ctx, id, warpgate.UserDataRequest{Username: "alice"})
+	expectStatus(t, "update", 200, updated.StatusCode(), err)
+
+	if updated.JSON200.Credentials == nil || len(updated.JSON200.Credentials) != 0 {
+		t.Errorf("expected an empty list of credentials, got %v", updated.JSON200.Credentials)
+	}
+
+	duplicate, err := client.UpdateUserWithResponse(ctx, id, warpgate.UserDataRequest{Username: AdminUsername})
+	expectStatus(t, "update duplicate", 409, duplicate.StatusCode(), err)
+
+	deleted, err := client.DeleteUserWithResponse(ctx, id)
+	expectStatus(t, "delete", 204, deleted.StatusCode(), err)
+
+	read, err := client.GetUserWithResponse(ctx, id)
+	expectStatus(t, "read deleted", 404, read.StatusCode(), err)
+}
+
+func TestTicketsAndSessions(t *testing.T) {
+	server, client := testClient(t)
+	ctx := context.Background()
+
+	created, err := client.CreateTicketWithResponse(ctx, warpgate.CreateTicketRequest{Username: AdminUsername, TargetName: AdminTargetName})
+	expectStatus(t, "create ticket", 201, created.StatusCode(), err)
+
+	if created.JSON201.Secret == "" {
+		t.Error("expected a secret")
+	}
+
+	deleted, err := client.DeleteTicketWithResponse(ctx, created.JSON201.Ticket.Id)
+	expectStatus(t, "delete ticket", 204, deleted.StatusCode(), err)
+
+	deleted, err = client.DeleteTicketWithResponse(ctx, created.JSON201.Ticket.Id)
+	expectStatus(t, "delete deleted ticket", 404, deleted.StatusCode(), err)
+
+	first := server.AddSession(AdminUsername, AdminTargetName)
+	second := server.AddSession(AdminUsername, AdminTargetName)
+
+	closed, err := client.CloseSessionWithResponse(ctx, first)
+	expectStatus(t, "close", 201, closed.StatusCode(), err)
+
+	if server.SessionActive(first) || !server.SessionActive(second) {
+		t.Error("expected only the first session to be closed")
+	}
+
+	activeOnly := true
+	sessions, err := client.GetSessionsWithResponse(ctx, &warpgate.GetSessionsParams{ActiveOnly: &activeOnly})
+	expectStatus(t, "sessions", 200, sessions.StatusCode(), err)
+
+	if sessions.JSON200.Total != 1 || sessions.JSON200.Items[0].Id != second {
+		t.Errorf("expected only the second session, got %v", sessions.JSON200.Items)
+	}
+
+	closedAll, err := client.CloseAllSessionsWithResponse(ctx)
+	expectStatus(t, "close all", 201, closedAll.StatusCode(), err)
+
+	if server.SessionActive(second) {
+		t.Error("expected all sessions to be closed")
+	}
+}
+
+func TestSsh(t *testing.T) {
+	server, client := testClient(t)
+	ctx := context.Background()
+
+	keys, err := client.GetSshOwnKeysWithResponse(ctx)
+	expectStatus(t, "own keys", 200, keys.StatusCode(), err)
+
+	kinds := []string{}
+	for _, key := range *keys.JSON200 {
+		kinds = append(kinds, key.Kind)
+	}
+
+	if len(kinds) != 3 || kinds[0] != "ssh-ed25519" || kinds[1] != "rsa-sha2-256" {
+		t.Errorf("unexpected key kinds %v", kinds)
+	}
+
+	hostKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	publicKey, _ := ssh.NewPublicKey(hostKey)
+
+	id := server.AddKnownHost("10.0.0.1", 22, publicKey)
+
+	knownHosts, err := client.GetSshKnownHostsWithResponse(ctx)
+	expectStatus(t, "known hosts", 200, knownHosts.StatusCode(), err)
+
+	if len(*knownHosts.JSON200) != 1 || (*knownHosts.JSON200)[0].KeyType != "ssh-ed25519" {
+		t.Errorf("unexpected known hosts %v", *knownHosts.JSON200)
+	}
+
+	deleted, err := client.DeleteSshKnownHostWithResponse(ctx, id)
+	expectStatus(t, "delete known host", 204, deleted.StatusCode(), err)
+}
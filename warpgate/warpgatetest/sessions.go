@@ -0,0 +1,221 @@
+package warpgatetest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"terraform-provider-warpgate/warpgate"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var sessionProtocols = map[string]string{
+	warpgate.TargetKindSsh:      "SSH",
+	warpgate.TargetKindHttp:     "HTTP",
+	warpgate.TargetKindMySql:    "MySQL",
+	warpgate.TargetKindPostgres: "PostgreSQL",
+}
+
+// AddSession opens a session of the user on the target, as if the user had
+// connected through warpgate. Returns the id of the session.
+func (s *Server) AddSession(username string, targetName string) uuid.UUID {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session := &warpgate.SessionSnapshot{
+		Id:       uuid.New(),
+		Protocol: "SSH",
+		Started:  time.Now().UTC(),
+		Username: &username,
+	}
+
+	for _, target := range s.targets {
+		if target.Name == targetName {
+			snapshot := s.target(target)
+			session.Target = &snapshot
+
+			if kind, _ := target.Options.Discriminator(); sessionProtocols[kind] != "" {
+				session.Protocol = sessionProtocols[kind]
+			}
+		}
+	}
+
+	s.targetSessions[session.Id] = session
+
+	return session.Id
+}
+
+// SessionActive reports if the session exists and has not been closed.
+func (s *Server) SessionActive(id uuid.UUID) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, ok := s.targetSessions[id]
+
+	return ok && session.Ended == nil
+}
+
+func (s *Server) getSessions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	offset, _ := strconv.ParseUint(query.Get("offset"), 10, 64)
+	limit, err := strconv.ParseUint(query.Get("limit"), 10, 64)
+
+	if err != nil {
+		limit = 100
+	}
+
+	activeOnly, _ := strconv.ParseBool(query.Get("active_only"))
+	loggedInOnly, _ := strconv.ParseBool(query.Get("logged_in_only"))
+
+	sessions := []warpgate.SessionSnapshot{}
+
+	for _, session := range s.targetSessions {
+		if activeOnly && session.Ended != nil {
+			continue
+		}
+
+		if loggedInOnly && session.Username == nil {
+			continue
+		}
+
+		sessions = append(sessions, *session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Started.After(sessions[j].Started) })
+
+	total := uint64(len(sessions))
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	writeJSON(w, http.StatusOK, warpgate.PaginatedSessionSnapshot{
+		Items:  sessions[start:end],
+		Offset: offset,
+		Total:  total,
+	})
+}
+
+func (s *Server) getSession(w http.ResponseWriter, r *http.Request) {
+	if session, ok := s.pathSession(w, r); ok {
+		writeJSON(w, http.StatusOK, session)
+	}
+}
+
+func (s *Server) closeSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.pathSession(w, r)
+
+	if !ok {
+		return
+	}
+
+	if session.Ended == nil {
+		ended := time.Now().UTC()
+		session.Ended = &ended
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) closeAllSessions(w http.ResponseWriter, r *http.Request) {
+	ended := time.Now().UTC()
+
+	for _, session := range s.targetSessions {
+		if session.Ended == nil {
+			session.Ended = &ended
+		}
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) getSessionRecordings(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.pathSession(w, r); ok {
+		writeJSON(w, http.StatusOK, []warpgate.Recording{})
+	}
+}
+
+func (s *Server) pathSession(w http.ResponseWriter, r *http.Request) (*warpgate.SessionSnapshot, bool) {
+	id, ok := pathId(w, r, "id")
+
+	if !ok {
+		return nil, false
+	}
+
+	session, ok := s.targetSessions[id]
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "session not found")
+		return nil, false
+	}
+
+	return session, true
+}
+
+func (s *Server) getTickets(w http.ResponseWriter, r *http.Request) {
+	tickets := []warpgate.Ticket{}
+
+	for _, ticket := range s.tickets {
+		tickets = append(tickets, *ticket)
+	}
+
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].Created.Before(tickets[j].Created) })
+
+	writeJSON(w, http.StatusOK, tickets)
+}
+
+func (s *Server) createTicket(w http.ResponseWriter, r *http.Request) {
+	var data warpgate.CreateTicketRequest
+
+	if !readJSON(w, r, &data) {
+		return
+	}
+
+	if strings.TrimSpace(data.Username) == "" {
+		writeError(w, http.StatusBadRequest, "username cannot be empty")
+		return
+	}
+
+	if strings.TrimSpace(data.TargetName) == "" {
+		writeError(w, http.StatusBadRequest, "target_name cannot be empty")
+		return
+	}
+
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+
+	ticket := &warpgate.Ticket{
+		Id:       uuid.New(),
+		Created:  time.Now().UTC(),
+		Username: data.Username,
+		Target:   data.TargetName,
+	}
+	s.tickets[ticket.Id] = ticket
+	s.ticketSecrets[ticket.Id] = hex.EncodeToString(secret)
+
+	writeJSON(w, http.StatusCreated, warpgate.TicketAndSecret{
+		Ticket: *ticket,
+		Secret: s.ticketSecrets[ticket.Id],
+	})
+}
+
+func (s *Server) deleteTicket(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathId(w, r, "id")
+
+	if !ok {
+		return
+	}
+
+	if _, ok := s.tickets[id]; !ok {
+		writeError(w, http.StatusNotFound, "ticket not found")
+		return
+	}
+
+	delete(s.tickets, id)
+	delete(s.ticketSecrets, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
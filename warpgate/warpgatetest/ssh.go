@@ -0,0 +1,114 @@
+package warpgatetest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
+)
+
+// generateOwnKeys returns the client keys of warpgate, the rsa key is listed
+// once for each signature algorithm as warpgate does.
+func generateOwnKeys() []warpgate.SSHKey {
+	keys := []warpgate.SSHKey{}
+
+	ed25519Key, _, err := ed25519.GenerateKey(rand.Reader)
+
+	if err != nil {
+		panic(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		panic(err)
+	}
+
+	ed25519PublicKey, err := ssh.NewPublicKey(ed25519Key)
+
+	if err != nil {
+		panic(err)
+	}
+
+	rsaPublicKey, err := ssh.NewPublicKey(&rsaKey.PublicKey)
+
+	if err != nil {
+		panic(err)
+	}
+
+	keys = append(keys, warpgate.SSHKey{
+		Kind:            ssh.KeyAlgoED25519,
+		PublicKeyBase64: base64.StdEncoding.EncodeToString(ed25519PublicKey.Marshal()),
+	})
+
+	for _, kind := range []string{ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSASHA512} {
+		keys = append(keys, warpgate.SSHKey{
+			Kind:            kind,
+			PublicKeyBase64: base64.StdEncoding.EncodeToString(rsaPublicKey.Marshal()),
+		})
+	}
+
+	return keys
+}
+
+// AddKnownHost trusts the key of a ssh host, as if a user had accepted it on
+// the first connection. Returns the id of the known host.
+func (s *Server) AddKnownHost(host string, port int32, publicKey ssh.PublicKey) uuid.UUID {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	knownHost := &warpgate.SSHKnownHost{
+		Id:        uuid.New(),
+		Host:      host,
+		Port:      port,
+		KeyType:   publicKey.Type(),
+		KeyBase64: base64.StdEncoding.EncodeToString(publicKey.Marshal()),
+	}
+	s.knownHosts[knownHost.Id] = knownHost
+
+	return knownHost.Id
+}
+
+func (s *Server) getKnownHosts(w http.ResponseWriter, r *http.Request) {
+	knownHosts := []warpgate.SSHKnownHost{}
+
+	for _, knownHost := range s.knownHosts {
+		knownHosts = append(knownHosts, *knownHost)
+	}
+
+	sort.Slice(knownHosts, func(i, j int) bool {
+		if knownHosts[i].Host != knownHosts[j].Host {
+			return knownHosts[i].Host < knownHosts[j].Host
+		}
+		return knownHosts[i].Port < knownHosts[j].Port
+	})
+
+	writeJSON(w, http.StatusOK, knownHosts)
+}
+
+func (s *Server) deleteKnownHost(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathId(w, r, "id")
+
+	if !ok {
+		return
+	}
+
+	if _, ok := s.knownHosts[id]; !ok {
+		writeError(w, http.StatusNotFound, "known host not found")
+		return
+	}
+
+	delete(s.knownHosts, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getOwnKeys(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.ownKeys)
+}
@@ -0,0 +1,26 @@
+package warpgatetest
+
+import (
+	"net/http"
+	"terraform-provider-warpgate/warpgate"
+)
+
+// SsoProviderName is the name of the sso provider configured on a new
+// server, to be used as the provider of the Sso credentials.
+const SsoProviderName = "google"
+
+// SetSsoProviders replaces the sso providers of the config file of the
+// server.
+func (s *Server) SetSsoProviders(providers ...warpgate.SsoProvider) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.ssoProviders = append([]warpgate.SsoProvider{}, providers...)
+}
+
+func (s *Server) getSsoProviders(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, s.ssoProviders)
+}
@@ -0,0 +1,243 @@
+package warpgatetest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+)
+
+func (s *Server) target(target *storedTarget) warpgate.Target {
+	return warpgate.Target{
+		Id:         target.Id,
+		Name:       target.Name,
+		Options:    target.Options,
+		AllowRoles: roleNames(sortedRoles(s.roles, s.targetRoles[target.Id])),
+	}
+}
+
+// validateTargetOptions checks the fields required by warpgate for each kind
+// of target.
+func validateTargetOptions(options warpgate.TargetOptions) error {
+	kind, err := options.Discriminator()
+
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case warpgate.TargetKindSsh:
+		ssh, err := options.AsTargetOptionsTargetSSHOptions()
+
+		if err != nil {
+			return err
+		}
+
+		if ssh.Host == "" || ssh.Username == "" {
+			return fmt.Errorf("host and username are required")
+		}
+
+		authKind, err := ssh.Auth.Discriminator()
+
+		if err != nil {
+			return err
+		}
+
+		if authKind != "Password" && authKind != "PublicKey" {
+			return fmt.Errorf("unknown auth kind %q", authKind)
+		}
+	case warpgate.TargetKindHttp:
+		http, err := options.AsTargetOptionsTargetHTTPOptions()
+
+		if err != nil {
+			return err
+		}
+
+		if http.Url == "" {
+			return fmt.Errorf("url is required")
+		}
+	case warpgate.TargetKindMySql:
+		mysql, err := options.AsTargetOptionsTargetMySqlOptions()
+
+		if err != nil {
+			return err
+		}
+
+		if mysql.Host == "" || mysql.Username == "" {
+			return fmt.Errorf("host and username are required")
+		}
+	case warpgate.TargetKindPostgres:
+		postgres, err := options.AsTargetOptionsTargetPostgresOptions()
+
+		if err != nil {
+			return err
+		}
+
+		if postgres.Host == "" || postgres.Username == "" {
+			return fmt.Errorf("host and username are required")
+		}
+	case warpgate.TargetKindWebAdmin:
+	default:
+		return fmt.Errorf("unknown target kind %q", kind)
+	}
+
+	return nil
+}
+
+// AddTarget stores a target without the checks of the api, e.g. to have
+// several targets with the same name. Returns the id of the target.
+func (s *Server) AddTarget(name string, options warpgate.TargetOptions) uuid.UUID {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	target := &storedTarget{Id: uuid.New(), Name: name, Options: options}
+	s.targets[target.Id] = target
+	s.targetRoles[target.Id] = map[uuid.UUID]bool{}
+
+	return target.Id
+}
+
+func (s *Server) validateTarget(w http.ResponseWriter, data warpgate.TargetDataRequest, id uuid.UUID) bool {
+	if strings.TrimSpace(data.Name) == "" {
+		writeError(w, http.StatusBadRequest, "name cannot be empty")
+		return false
+	}
+
+	if err := validateTargetOptions(data.Options); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid options: %s", err))
+		return false
+	}
+
+	for _, target := range s.targets {
+		if target.Name == data.Name && target.Id != id {
+			writeError(w, http.StatusConflict, "a target with this name already exists")
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Server) getTargets(w http.ResponseWriter, r *http.Request) {
+	targets := []warpgate.Target{}
+
+	for _, target := range s.targets {
+		targets = append(targets, s.target(target))
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+
+	writeJSON(w, http.StatusOK, targets)
+}
+
+func (s *Server) createTarget(w http.ResponseWriter, r *http.Request) {
+	var data warpgate.TargetDataRequest
+
+	if !readJSON(w, r, &data) || !s.validateTarget(w, data, uuid.Nil) {
+		return
+	}
+
+	if kind, _ := data.Options.Discriminator(); kind == warpgate.TargetKindWebAdmin {
+		writeError(w, http.StatusBadRequest, "cannot create Warpgate web admin targets")
+		return
+	}
+
+	target := &storedTarget{Id: uuid.New(), Name: data.Name, Options: data.Options}
+	s.targets[target.Id] = target
+	s.targetRoles[target.Id] = map[uuid.UUID]bool{}
+
+	writeJSON(w, http.StatusCreated, s.target(target))
+}
+
+func (s *Server) getTarget(w http.ResponseWriter, r *http.Request) {
+	target, ok := s.pathTarget(w, r)
+
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.target(target))
+}
+
+func (s *Server) updateTarget(w http.ResponseWriter, r *http.Request) {
+	target, ok := s.pathTarget(w, r)
+
+	if !ok {
+		return
+	}
+
+	var data warpgate.TargetDataRequest
+
+	if !readJSON(w, r, &data) || !s.validateTarget(w, data, target.Id) {
+		return
+	}
+
+	currentKind, _ := target.Options.Discriminator()
+	kind, _ := data.Options.Discriminator()
+
+	if (currentKind == warpgate.TargetKindWebAdmin) != (kind == warpgate.TargetKindWebAdmin) {
+		writeError(w, http.StatusBadRequest, "cannot change the kind of the Warpgate web admin target")
+		return
+	}
+
+	target.Name = data.Name
+	target.Options = data.Options
+
+	writeJSON(w, http.StatusOK, s.target(target))
+}
+
+func (s *Server) deleteTarget(w http.ResponseWriter, r *http.Request) {
+	target, ok := s.pathTarget(w, r)
+
+	if !ok {
+		return
+	}
+
+	if kind, _ := target.Options.Discriminator(); kind == warpgate.TargetKindWebAdmin {
+		writeError(w, http.StatusForbidden, "cannot delete the Warpgate web admin target")
+		return
+	}
+
+	delete(s.targets, target.Id)
+	delete(s.targetRoles, target.Id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getTargetRoles(w http.ResponseWriter, r *http.Request) {
+	if target, ok := s.pathTarget(w, r); ok {
+		s.getAssignedRoles(w, s.targetRoles[target.Id])
+	}
+}
+
+func (s *Server) addTargetRole(w http.ResponseWriter, r *http.Request) {
+	if target, ok := s.pathTarget(w, r); ok {
+		s.addRole(w, r, s.targetRoles[target.Id])
+	}
+}
+
+func (s *Server) deleteTargetRole(w http.ResponseWriter, r *http.Request) {
+	if target, ok := s.pathTarget(w, r); ok {
+		s.removeRole(w, r, s.targetRoles[target.Id])
+	}
+}
+
+func (s *Server) pathTarget(w http.ResponseWriter, r *http.Request) (*storedTarget, bool) {
+	id, ok := pathId(w, r, "id")
+
+	if !ok {
+		return nil, false
+	}
+
+	target, ok := s.targets[id]
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "target not found")
+		return nil, false
+	}
+
+	return target, true
+}
@@ -0,0 +1,200 @@
+package warpgatetest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"terraform-provider-warpgate/warpgate"
+
+	"github.com/google/uuid"
+)
+
+func (s *Server) user(user *storedUser) warpgate.User {
+	return warpgate.User{
+		Id:               user.Id,
+		Username:         user.Username,
+		Credentials:      user.Credentials,
+		CredentialPolicy: user.CredentialPolicy,
+		Roles:            roleNames(sortedRoles(s.roles, s.userRoles[user.Id])),
+	}
+}
+
+func validateCredentials(credentials []warpgate.UserAuthCredential) error {
+	for _, credential := range credentials {
+		kind, err := credential.Discriminator()
+
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case "Password":
+			password, err := credential.AsUserAuthCredentialUserPasswordCredential()
+
+			if err != nil || password.Hash == "" {
+				return fmt.Errorf("the password credential requires a hash")
+			}
+		case "PublicKey":
+			publicKey, err := credential.AsUserAuthCredentialUserPublicKeyCredential()
+
+			if err != nil || publicKey.Key == "" {
+				return fmt.Errorf("the public key credential requires a key")
+			}
+		case "Totp":
+			totp, err := credential.AsUserAuthCredentialUserTotpCredential()
+
+			if err != nil || len(totp.Key) == 0 {
+				return fmt.Errorf("the totp credential requires a key")
+			}
+		case "Sso":
+			sso, err := credential.AsUserAuthCredentialUserSsoCredential()
+
+			if err != nil || sso.Email == "" {
+				return fmt.Errorf("the sso credential requires an email")
+			}
+		default:
+			return fmt.Errorf("unknown credential kind %q", kind)
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) validateUser(w http.ResponseWriter, data warpgate.UserDataRequest, id uuid.UUID) bool {
+	if strings.TrimSpace(data.Username) == "" {
+		writeError(w, http.StatusBadRequest, "username cannot be empty")
+		return false
+	}
+
+	if err := validateCredentials(data.Credentials); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid credentials: %s", err))
+		return false
+	}
+
+	for _, user := range s.users {
+		if user.Username == data.Username && user.Id != id {
+			writeError(w, http.StatusConflict, "a user with this username already exists")
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Server) getUsers(w http.ResponseWriter, r *http.Request) {
+	users := []warpgate.User{}
+
+	for _, user := range s.users {
+		users = append(users, s.user(user))
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	var data warpgate.UserDataRequest
+
+	if !readJSON(w, r, &data) || !s.validateUser(w, data, uuid.Nil) {
+		return
+	}
+
+	user := &storedUser{
+		Id:               uuid.New(),
+		Username:         data.Username,
+		Credentials:      nonNilCredentials(data.Credentials),
+		CredentialPolicy: data.CredentialPolicy,
+	}
+	s.users[user.Id] = user
+	s.userRoles[user.Id] = map[uuid.UUID]bool{}
+
+	writeJSON(w, http.StatusCreated, s.user(user))
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.pathUser(w, r)
+
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.user(user))
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.pathUser(w, r)
+
+	if !ok {
+		return
+	}
+
+	var data warpgate.UserDataRequest
+
+	if !readJSON(w, r, &data) || !s.validateUser(w, data, user.Id) {
+		return
+	}
+
+	user.Username = data.Username
+	user.Credentials = nonNilCredentials(data.Credentials)
+	user.CredentialPolicy = data.CredentialPolicy
+
+	writeJSON(w, http.StatusOK, s.user(user))
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := s.pathUser(w, r)
+
+	if !ok {
+		return
+	}
+
+	delete(s.users, user.Id)
+	delete(s.userRoles, user.Id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getUserRoles(w http.ResponseWriter, r *http.Request) {
+	if user, ok := s.pathUser(w, r); ok {
+		s.getAssignedRoles(w, s.userRoles[user.Id])
+	}
+}
+
+func (s *Server) addUserRole(w http.ResponseWriter, r *http.Request) {
+	if user, ok := s.pathUser(w, r); ok {
+		s.addRole(w, r, s.userRoles[user.Id])
+	}
+}
+
+func (s *Server) deleteUserRole(w http.ResponseWriter, r *http.Request) {
+	if user, ok := s.pathUser(w, r); ok {
+		s.removeRole(w, r, s.userRoles[user.Id])
+	}
+}
+
+func (s *Server) pathUser(w http.ResponseWriter, r *http.Request) (*storedUser, bool) {
+	id, ok := pathId(w, r, "id")
+
+	if !ok {
+		return nil, false
+	}
+
+	user, ok := s.users[id]
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "user not found")
+		return nil, false
+	}
+
+	return user, true
+}
+
+func nonNilCredentials(credentials []warpgate.UserAuthCredential) []warpgate.UserAuthCredential {
+	if credentials == nil {
+		return []warpgate.UserAuthCredential{}
+	}
+
+	return credentials
+}